@@ -0,0 +1,103 @@
+package srp
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditReason identifies the outcome of a proof verification
+// reported to an [AuditSink].
+type AuditReason int
+
+// Available audit reasons.
+const (
+	// AuditVerified reports a verified proof.
+	AuditVerified AuditReason = iota
+
+	// AuditLegacyProof reports a client proof verified in its
+	// legacy form (see [AcceptLegacyProofsUntil]).
+	AuditLegacyProof
+
+	// AuditBadProof reports a proof that does not match, e.g.
+	// because the password is wrong.
+	AuditBadProof
+
+	// AuditUnknownUser reports a client proof rejected by a
+	// fake server (see [NewFakeServer]).
+	AuditUnknownUser
+)
+
+// String returns a stable identifier of r, suitable for logs
+// (e.g. "bad_proof").
+func (r AuditReason) String() string {
+	switch r {
+	case AuditVerified:
+		return "verified"
+	case AuditLegacyProof:
+		return "legacy_proof"
+	case AuditBadProof:
+		return "bad_proof"
+	case AuditUnknownUser:
+		return "unknown_user"
+	default:
+		return fmt.Sprintf("AuditReason(%d)", int(r))
+	}
+}
+
+// AuditEvent describes the outcome of a proof verification.
+type AuditEvent struct {
+	Time     time.Time     // Time of the verification
+	Role     Role          // Side that verified the proof of its peer
+	Username string        // Username of the handshake
+	Params   string        // Name of the params
+	Group    string        // ID of the group
+	Duration time.Duration // Time elapsed since the handshake started
+	Success  bool          // Whether the peer was authenticated
+	Reason   AuditReason   // Outcome of the verification
+}
+
+// AuditSink receives the outcome of each proof verified by
+// the clients and servers configured with [WithAuditSink], so
+// that login attempts can be fed into a security information
+// and event management (SIEM) pipeline.
+//
+// Audit is called synchronously, while the client or server is
+// locked: it must not block (e.g. by sending events over the
+// network), nor call methods of the client or server.
+type AuditSink interface {
+	Audit(e AuditEvent)
+}
+
+// AuditFunc is an [AuditSink] calling itself.
+type AuditFunc func(e AuditEvent)
+
+// Audit calls f(e).
+func (f AuditFunc) Audit(e AuditEvent) {
+	f(e)
+}
+
+// WithAuditSink reports the outcome of the verification of the
+// peer's proof to sink. It does not need to match on both sides.
+func WithAuditSink(sink AuditSink) Option {
+	return func(o *options) {
+		o.auditSink = sink
+	}
+}
+
+// audit reports the outcome of a proof verification to the
+// audit sink of o, if any.
+func (o *options) audit(e HandshakeEvent, params *Params, reason AuditReason) {
+	if o.auditSink == nil {
+		return
+	}
+	o.auditSink.Audit(AuditEvent{
+		Time:     now(),
+		Role:     e.Role,
+		Username: e.Username,
+		Params:   params.Name,
+		Group:    params.Group.ID,
+		Duration: e.Duration,
+		Success:  reason == AuditVerified || reason == AuditLegacyProof,
+		Reason:   reason,
+	})
+}
@@ -0,0 +1,134 @@
+package srp
+
+import (
+	"testing"
+	"time"
+)
+
+// auditLog records the events it receives.
+type auditLog []AuditEvent
+
+func (l *auditLog) Audit(e AuditEvent) {
+	*l = append(*l, e)
+}
+
+func TestAuditSink(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		password string
+		server   func(opts ...Option) (*Server, error)
+		client   *Params
+		reason   AuditReason
+		success  bool
+	}{
+		{
+			name:     "Verified",
+			password: string(P),
+			server: func(opts ...Option) (*Server, error) {
+				return NewServer(params, string(I), salt.Bytes(), v.Bytes(), opts...)
+			},
+			reason:  AuditVerified,
+			success: true,
+		},
+		{
+			name:     "BadProof",
+			password: "password124",
+			server: func(opts ...Option) (*Server, error) {
+				return NewServer(params, string(I), salt.Bytes(), v.Bytes(), opts...)
+			},
+			reason: AuditBadProof,
+		},
+		{
+			name:     "UnknownUser",
+			password: string(P),
+			server: func(opts ...Option) (*Server, error) {
+				return NewFakeServer(params, string(I), fakeSecret, opts...)
+			},
+			reason: AuditUnknownUser,
+		},
+		{
+			name:     "LegacyProof",
+			password: string(P),
+			client:   legacyParams,
+			server: func(opts ...Option) (*Server, error) {
+				opts = append(opts, AcceptLegacyProofsUntil(start.Add(time.Hour), legacyParams))
+				return NewServer(params, string(I), salt.Bytes(), v.Bytes(), opts...)
+			},
+			reason:  AuditLegacyProof,
+			success: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setNow(t, start)
+
+			clientParams := params
+			if tt.client != nil {
+				clientParams = tt.client
+			}
+
+			var log auditLog
+			server, err := tt.server(WithAuditSink(&log))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, err := NewClient(clientParams, string(I), tt.password, salt.Bytes(), WithAuditSink(&log))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			setNow(t, start.Add(time.Second))
+			err = handshake(client, server)
+			if tt.success && err != nil {
+				t.Fatal(err)
+			}
+
+			wanted := AuditEvent{
+				Time:     start.Add(time.Second),
+				Role:     RoleServer,
+				Username: string(I),
+				Params:   params.Name,
+				Group:    params.Group.ID,
+				Duration: time.Second,
+				Success:  tt.success,
+				Reason:   tt.reason,
+			}
+			if len(log) == 0 || log[0] != wanted {
+				t.Fatalf("wanted %+v, got %+v", wanted, log)
+			}
+
+			if tt.success {
+				if len(log) != 2 || log[1].Role != RoleClient || log[1].Reason != AuditVerified {
+					t.Fatalf("expected the client to report a verified proof, got %+v", log)
+				}
+			} else if len(log) != 1 {
+				t.Fatalf("expected the client not to verify M2, got %+v", log)
+			}
+		})
+	}
+}
+
+func TestAuditReasonString(t *testing.T) {
+	for reason, wanted := range map[AuditReason]string{
+		AuditVerified:    "verified",
+		AuditLegacyProof: "legacy_proof",
+		AuditBadProof:    "bad_proof",
+		AuditUnknownUser: "unknown_user",
+		AuditReason(42):  "AuditReason(42)",
+	} {
+		if s := reason.String(); s != wanted {
+			t.Fatalf("wanted %q, got %q", wanted, s)
+		}
+	}
+}
+
+func TestAuditFunc(t *testing.T) {
+	var called bool
+	var sink AuditSink = AuditFunc(func(AuditEvent) { called = true })
+	sink.Audit(AuditEvent{})
+	if !called {
+		t.Fatal("expected the function to be called")
+	}
+}
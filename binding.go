@@ -0,0 +1,58 @@
+package srp
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrChannelBindingTooLate is returned when a channel binding
+// is set after the proofs have been computed.
+var ErrChannelBindingTooLate = errors.New("channel binding must be set before the peer's public key")
+
+// SetChannelBinding binds the handshake of c to the TLS
+// connection it is performed over.
+//
+// cb must be obtained from the TLS connection, ideally with the
+// keying material exporter defined in RFC 9266
+// ("tls-exporter"):
+//
+//	state := conn.ConnectionState()
+//	cb, err := state.ExportKeyingMaterial("EXPORTER-Channel-Binding", nil, 32)
+//
+// or, for TLS 1.2 and below, from state.TLSUnique.
+//
+// The binding is mixed into the client proof (M1), and therefore
+// into the server proof (M2). A man-in-the-middle terminating
+// TLS separately with the client and the server sees different
+// channel bindings on each side, so the proofs it relays fail
+// to verify.
+//
+// SetChannelBinding must be called before [Client.SetB], and
+// the server must be configured with the same binding.
+func (c *Client) SetChannelBinding(cb []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.xB != nil {
+		return ErrChannelBindingTooLate
+	}
+	c.opts.channelBinding = bytes.Clone(cb)
+	return nil
+}
+
+// SetChannelBinding binds the handshake of s to the TLS
+// connection it is performed over. See
+// [Client.SetChannelBinding].
+//
+// SetChannelBinding must be called before [Server.SetA]. The
+// binding is saved with the state of s.
+func (s *Server) SetChannelBinding(cb []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.xA != nil {
+		return ErrChannelBindingTooLate
+	}
+	s.opts.channelBinding = bytes.Clone(cb)
+	return nil
+}
@@ -0,0 +1,120 @@
+package srp
+
+import (
+	"errors"
+	"testing"
+)
+
+// newBoundPair returns a client and a server configured
+// with the given channel bindings.
+func newBoundPair(t *testing.T, clientCB, serverCB []byte) (*Client, *Server) {
+	t.Helper()
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clientCB != nil {
+		if err := client.SetChannelBinding(clientCB); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if serverCB != nil {
+		if err := server.SetChannelBinding(serverCB); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return client, server
+}
+
+func TestChannelBinding(t *testing.T) {
+	cb := []byte("tls-exporter")
+
+	client, server := newBoundPair(t, cb, cb)
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	// The binding changes the proofs.
+	unbound, _ := newBoundPair(t, nil, nil)
+	if err := unbound.SetB(server.B()); err != nil {
+		t.Fatal(err)
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unboundM1, err := unbound.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkProof(M1, unboundM1) {
+		t.Fatal("expected the channel binding to change M1")
+	}
+}
+
+func TestChannelBindingMismatch(t *testing.T) {
+	tests := []struct {
+		name               string
+		clientCB, serverCB []byte
+	}{
+		{"Different", []byte("client"), []byte("server")},
+		{"ClientOnly", []byte("client"), nil},
+		{"ServerOnly", nil, []byte("server")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newBoundPair(t, tt.clientCB, tt.serverCB)
+			if err := handshake(client, server); err == nil {
+				t.Fatal("expected the handshake to fail")
+			}
+		})
+	}
+}
+
+func TestChannelBindingTooLate(t *testing.T) {
+	client, server := newBoundPair(t, nil, nil)
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.SetChannelBinding([]byte("cb")); !errors.Is(err, ErrChannelBindingTooLate) {
+		t.Fatalf("expected ErrChannelBindingTooLate, got %v", err)
+	}
+	if err := server.SetChannelBinding([]byte("cb")); !errors.Is(err, ErrChannelBindingTooLate) {
+		t.Fatalf("expected ErrChannelBindingTooLate, got %v", err)
+	}
+}
+
+func TestChannelBindingRestore(t *testing.T) {
+	cb := []byte("tls-exporter")
+	client, server := newBoundPair(t, cb, cb)
+
+	if err := server.SetA(client.A()); err != nil {
+		t.Fatal(err)
+	}
+	state, err := server.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err = RestoreServer(params, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.SetB(server.B()); err != nil {
+		t.Fatal(err)
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := server.CheckM1(M1); err != nil || !ok {
+		t.Fatalf("expected M1 to be verified, got %v, %v", ok, err)
+	}
+}
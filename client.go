@@ -1,8 +1,12 @@
 package srp
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"sync"
+	"time"
 )
 
 // ErrClientNotReady is returned when the client
@@ -11,25 +15,50 @@ var ErrClientNotReady = errors.New("server's public ephemeral key (B) must be se
 
 // Client represents the client-side perspective of an SRP
 // session.
+//
+// A Client is safe for concurrent use by multiple goroutines.
 type Client struct {
-	username []byte   // (a.k.a. identity)
-	salt     []byte   // User salt
-	x        *big.Int // User's derived secret
-	a        *big.Int // Client private ephemeral
-	xA       *big.Int // Client public ephemeral
-	xB       *big.Int // Server public ephemeral
-	m1       *big.Int // Client proof
-	m2       *big.Int // Server proof
-	xS       *big.Int // Pre-master key
-	xK       []byte   // Session key
-	params   *Params  // Params combination
+	mu sync.Mutex // Guards all the fields below
+
+	username []byte    // (a.k.a. identity)
+	salt     []byte    // User salt
+	x        *big.Int  // User's derived secret
+	a        *big.Int  // Client private ephemeral
+	xA       *big.Int  // Client public ephemeral
+	xB       *big.Int  // Server public ephemeral
+	m1       *big.Int  // Client proof
+	m2       *big.Int  // Server proof
+	xS       *big.Int  // Pre-master key
+	xK       []byte    // Session key
+	params   *Params   // Params combination
+	opts     options   // Optional features
+	start    time.Time // Time the handshake started
 }
 
 // SetB configures the server's public ephemeral key (B).
-func (c *Client) SetB(public []byte) error {
-	B := new(big.Int).SetBytes(public)
-	if !isValidEphemeralKey(c.params, B) {
-		return errors.New("invalid public exponent")
+func (c *Client) SetB(public PublicKey) error {
+	return c.SetBContext(context.Background(), public)
+}
+
+// SetBContext is like [Client.SetB], but returns early with
+// the error of ctx if ctx is done before the premaster secret
+// is computed. The client is left unchanged in that case.
+//
+// The modular exponentiation cannot be interrupted: it keeps
+// running in the background until it returns, and its result
+// is discarded.
+func (c *Client) SetBContext(ctx context.Context, public PublicKey) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.opts.reportError(c.event(), c.setB(ctx, public))
+}
+
+// setB implements SetBContext. The caller must hold c.mu.
+func (c *Client) setB(ctx context.Context, public PublicKey) error {
+	B, err := c.opts.checkPublicKey(c.params, public)
+	if err != nil {
+		return err
 	}
 
 	k, err := computeLittleK(c.params)
@@ -41,27 +70,49 @@ func (c *Client) SetB(public []byte) error {
 	if err != nil {
 		return err
 	}
-	if u.Cmp(bigZero) == 0 {
-		return errors.New("invalid u value")
+	if err := c.opts.checkU(u); err != nil {
+		return err
 	}
 
-	S, err := computeClientS(c.params, k, c.x, u, B, c.a)
+	var (
+		S        *big.Int
+		params   = c.params
+		x, a     = c.x, c.a
+		hardened = c.opts.hardened
+	)
+	err = runContext(ctx, func() (err error) {
+		S, err = computeClientS(params, k, x, u, B, a, hardened)
+		return
+	})
 	if err != nil {
 		return err
 	}
 
-	K := c.params.hashBytes(S.Bytes())
+	K, err := computeK(c.params, S)
+	if err != nil {
+		return err
+	}
 
-	M1, err := computeM1(c.params, c.username, c.salt, c.xA, B, K)
+	M1, err := computeM1(c.params, c.username, c.salt, c.xA, B, S, K, c.opts.binding(c.params))
 	if err != nil {
 		return err
 	}
 
-	M2, err := computeM2(c.params, c.xA, M1, K)
+	M2, err := computeM2(c.params, c.xA, M1, S, K)
 	if err != nil {
 		return err
 	}
 
+	c.opts.recordTranscript(func(r *TranscriptRecorder) {
+		r.recordInt("B", &r.t.BigB, B)
+		r.recordInt("k", &r.t.LittleK, k)
+		r.recordInt("u", &r.t.U, u)
+		r.recordInt("S", &r.t.S, S)
+		r.recordBytes("K", &r.t.K, K)
+		r.recordInt("M1", &r.t.M1, M1)
+		r.recordInt("M2", &r.t.M2, M2)
+	})
+
 	c.xB = B
 	c.m1 = M1
 	c.m2 = M2
@@ -72,57 +123,182 @@ func (c *Client) SetB(public []byte) error {
 
 // A returns the public ephemeral key
 // (A) of this client.
-func (c *Client) A() []byte {
+func (c *Client) A() PublicKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.xA.Bytes()
 }
 
 // ComputeM1 returns the proof (M1) which should be
 // sent to the server.
-func (c *Client) ComputeM1() ([]byte, error) {
+func (c *Client) ComputeM1() (Proof, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.m1 == nil {
-		return nil, ErrClientNotReady
+		return nil, c.opts.reportError(c.event(), ErrClientNotReady)
 	}
 	return c.m1.Bytes(), nil
 }
 
 // CheckM2 returns true if the server proof M2 is verified.
-func (c *Client) CheckM2(M2 []byte) (bool, error) {
+func (c *Client) CheckM2(M2 Proof) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.m2 == nil {
-		return false, ErrClientNotReady
+		return false, c.opts.reportError(c.event(), ErrClientNotReady)
+	}
+
+	verified := checkProof(c.m2.Bytes(), M2)
+	reason := AuditBadProof
+	if verified {
+		reason = AuditVerified
 	}
 
-	return checkProof(c.m2.Bytes(), M2), nil
+	e := c.event()
+	c.opts.reportProof(e, verified)
+	c.opts.audit(e, c.params, reason)
+	return verified, nil
 }
 
 // SessionKey returns the session key that will be shared with the
 // server.
-func (c *Client) SessionKey() ([]byte, error) {
+func (c *Client) SessionKey() (SessionKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.xK == nil {
-		return nil, ErrClientNotReady
+		return nil, c.opts.reportError(c.event(), ErrClientNotReady)
 	}
 
-	h := c.params.Hash.New()
-	digest := h.Sum(c.xK)[:h.Size()]
-	return digest, nil
+	key, err := formatSessionKey(c.params, c.xK)
+	return key, c.opts.reportError(c.event(), err)
+}
+
+// event returns the event describing the current
+// state of the handshake of c.
+func (c *Client) event() HandshakeEvent {
+	return newHandshakeEvent(RoleClient, c.params, string(c.username), c.start)
 }
 
 // NewClient a new SRP client instance.
-func NewClient(params *Params, username, password string, salt []byte) (*Client, error) {
-	x, err := params.KDF(NFKD(username), NFKD(password), salt)
+//
+// The optional opts must match those used by the server.
+func NewClient(params *Params, username, password string, salt []byte, opts ...Option) (*Client, error) {
+	return NewClientContext(context.Background(), params, username, password, salt, opts...)
+}
+
+// NewClientContext is like [NewClient], but returns early with
+// the error of ctx if ctx is done before the params' key
+// derivation function returns.
+//
+// The key derivation function cannot be interrupted: it keeps
+// running in the background until it returns, and its result
+// is discarded.
+func NewClientContext(ctx context.Context, params *Params, username, password string, salt []byte, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+	if err := o.checkClientParams(params); err != nil {
+		return nil, err
+	}
+	if err := params.checkSalt(salt); err != nil {
+		return nil, err
+	}
+
+	x, err := deriveX(ctx, params, username, password, salt)
 	if err != nil {
 		return nil, err
 	}
+	return newClient(params, username, salt, x, o)
+}
+
+// NewClientFromSecret is like [NewClient], with the secret x
+// exported by [Client.ExportSecret] instead of the password,
+// which skips the params' key derivation function.
+//
+// It lets devices that reconnect frequently cache x (e.g. in
+// the keystore of the platform) instead of the password. x is
+// as sensitive as the password for the server: anyone who
+// knows it can authenticate as the user.
+func NewClientFromSecret(params *Params, username string, secret, salt []byte, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+	if err := o.checkClientParams(params); err != nil {
+		return nil, err
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("secret cannot be empty")
+	}
+	if err := params.checkSalt(salt); err != nil {
+		return nil, err
+	}
+	return newClient(params, username, salt, new(big.Int).SetBytes(secret), o)
+}
+
+// ExportSecret returns the secret x derived from the password
+// of c, to create clients with [NewClientFromSecret].
+//
+// The secret must be stored securely, as it lets anyone
+// authenticate as the user, and be discarded when the password
+// or the salt of the user change.
+func (c *Client) ExportSecret() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.x.Bytes()
+}
+
+// checkClientParams returns an error if params cannot be used
+// by a client configured with o.
+func (o *options) checkClientParams(params *Params) error {
+	if err := o.validateParams(params); err != nil {
+		return err
+	}
+	if o.offer != nil {
+		if err := validateOffer(o.offer); err != nil {
+			return err
+		}
+		if !containsName(o.offer, params.Name) {
+			return fmt.Errorf("params %q are not part of the offer", params.Name)
+		}
+	}
+	return nil
+}
+
+// newClient returns a new client for the user whose secret
+// derived from the password is x.
+func newClient(params *Params, username string, salt []byte, x *big.Int, o options) (*Client, error) {
+	if params.Normalization == NormalizePRECIS {
+		var err error
+		if username, err = params.normalizeUsername(username); err != nil {
+			return nil, err
+		}
+	}
 
-	a, A := newClientKeyPair(params)
+	a, A, err := newClientKeyPair(params, o.entropy)
+	if err != nil {
+		return nil, err
+	}
 
 	c := &Client{
 		username: []byte(username),
 		salt:     salt,
-		x:        new(big.Int).SetBytes(x),
+		x:        x,
 		a:        a,
 		xA:       A,
 		params:   params,
+		opts:     o,
+		start:    now(),
 	}
+	o.recordTranscript(func(r *TranscriptRecorder) {
+		r.recordParams(params)
+		r.record("I", &r.t.Username, username)
+		r.recordBytes("s", &r.t.Salt, salt)
+		r.recordInt("x", &r.t.X, x)
+		r.recordInt("a", &r.t.LittleA, a)
+		r.recordInt("A", &r.t.BigA, A)
+	})
+	o.reportStart(c.event())
 	return c, nil
 }
 
@@ -134,11 +310,66 @@ func NewClient(params *Params, username, password string, salt []byte) (*Client,
 // over a secure connection (TLS), and stored in a secure
 // persistent-storage (e.g. database).
 func ComputeVerifier(params *Params, username, password string, salt []byte) (Triplet, error) {
-	x, err := params.KDF(NFKD(username), NFKD(password), salt)
+	return ComputeVerifierContext(context.Background(), params, username, password, salt)
+}
+
+// ComputeVerifierContext is like [ComputeVerifier], but returns
+// early with the error of ctx if ctx is done before the params'
+// key derivation function returns.
+//
+// As with [NewClientContext], the key derivation function keeps
+// running in the background until it returns.
+func ComputeVerifierContext(ctx context.Context, params *Params, username, password string, salt []byte) (Triplet, error) {
+	v, err := computeVerifierContext(ctx, params, username, password, salt)
 	if err != nil {
 		return nil, err
 	}
 
-	v := new(big.Int).Exp(params.Group.Generator, new(big.Int).SetBytes(x), params.Group.N)
 	return NewTriplet(username, salt, v.Bytes()), nil
 }
+
+// computeVerifier computes the verifier v.
+//
+// Formula:
+//
+//	x = KDF(U, p, s)
+//	v = g^x % N
+func computeVerifier(params *Params, username, password string, salt []byte) (*big.Int, error) {
+	return computeVerifierContext(context.Background(), params, username, password, salt)
+}
+
+// computeVerifierContext is like computeVerifier, but returns
+// early if ctx is done before x is derived.
+func computeVerifierContext(ctx context.Context, params *Params, username, password string, salt []byte) (*big.Int, error) {
+	x, err := deriveX(ctx, params, username, password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(big.Int).Exp(params.Group.Generator, x, params.Group.N)
+	return v, nil
+}
+
+// deriveX runs the key derivation function of params with
+// the normalized credentials, and returns x, or the error of
+// ctx if ctx is done first.
+//
+// Formula:
+//
+//	x = KDF(U, p, s)
+func deriveX(ctx context.Context, params *Params, username, password string, salt []byte) (*big.Int, error) {
+	username, password, err := params.normalizeCredentials(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var x []byte
+	err = runContext(ctx, func() (err error) {
+		x, err = params.KDF(username, password, salt)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(x), nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"code.posterity.life/srp/v2"
+)
+
+// fingerprint returns a short digest of key, which can be
+// compared between a client and a server without revealing
+// the key.
+func fingerprint(key srp.SessionKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// runClient runs a client handshake against a server.
+func runClient(e *env, args []string) error {
+	fs := newFlagSet(e, "client", "")
+	var (
+		addr       = fs.String("addr", "", "address of the server, as host:port (required)")
+		paramsName = fs.String("params", "", "name of the params (required)")
+		username   = fs.String("username", "", "username (required)")
+		password   = fs.String("password", "", "password (default: read from the standard input)")
+		timeout    = fs.Duration("timeout", 10*time.Second, "timeout of the handshake")
+		transcript = fs.Bool("transcript", false, "print the transcript of the handshake")
+	)
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *addr == "" || *paramsName == "" || *username == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	params, err := lookupParams(*paramsName)
+	if err != nil {
+		return err
+	}
+	pw, err := readPassword(e.stdin, *password)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", *addr, *timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(*timeout)); err != nil {
+		return err
+	}
+
+	var r srp.TranscriptRecorder
+	key, err := srp.ClientHandshake(conn, params, *username, pw, srp.WithTranscript(&r))
+	if *transcript {
+		if err := r.WriteJSON(e.stdout); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stdout, "authenticated as %q, session key %s\n", *username, fingerprint(key))
+	return nil
+}
+
+// runServer runs a server accepting handshakes for a user.
+func runServer(e *env, args []string) error {
+	fs := newFlagSet(e, "server", "")
+	var (
+		listen     = fs.String("listen", "localhost:5054", "address to listen on")
+		paramsName = fs.String("params", "", "name of the params (default: that of the triplet)")
+		triplet    = fs.String("triplet", "", "triplet of the user, as printed by the verifier command")
+		username   = fs.String("username", "", "username, if no triplet is given")
+		password   = fs.String("password", "", "password, if no triplet is given (default: read from the standard input)")
+		count      = fs.Int("n", 0, "number of handshakes to run before exiting (0 for no limit)")
+		timeout    = fs.Duration("timeout", 10*time.Second, "timeout of each handshake")
+		transcript = fs.Bool("transcript", false, "print the transcript of each handshake")
+	)
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if (*triplet == "") == (*username == "") {
+		fs.Usage()
+		return errUsage
+	}
+
+	name := *paramsName
+	var t srp.Triplet
+	if *triplet != "" {
+		tagged, parsed, err := parseTriplet(*triplet)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = tagged
+		}
+		t = parsed
+	}
+
+	if name == "" {
+		fs.Usage()
+		return errUsage
+	}
+	params, err := lookupParams(name)
+	if err != nil {
+		return err
+	}
+
+	if t == nil {
+		pw, err := readPassword(e.stdin, *password)
+		if err != nil {
+			return err
+		}
+		if t, err = srp.ComputeVerifier(params, *username, pw, params.NewSalt()); err != nil {
+			return err
+		}
+	}
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	fmt.Fprintf(e.stdout, "listening on %s with params %s\n", ln.Addr(), params.Name)
+	return serve(e, ln, params, t, *count, *timeout, *transcript)
+}
+
+// serve accepts connections on ln, and runs a server handshake
+// for the user of t on each of them, until count handshakes
+// were run, or forever if count is 0.
+//
+// Failed handshakes are reported, but do not stop the server.
+func serve(e *env, ln net.Listener, params *srp.Params, t srp.Triplet, count int, timeout time.Duration, transcript bool) error {
+	for i := 0; count == 0 || i < count; i++ {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		var r srp.TranscriptRecorder
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+		key, err := srp.ServerHandshake(conn, params, t, srp.WithTranscript(&r))
+		conn.Close()
+
+		if transcript {
+			if err := r.WriteJSON(e.stdout); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(e.stdout, "%s: handshake failed: %v\n", conn.RemoteAddr(), err)
+			continue
+		}
+		fmt.Fprintf(e.stdout, "%s: authenticated %q, session key %s\n", conn.RemoteAddr(), t.Username(), fingerprint(key))
+	}
+	return nil
+}
+
+// runVectors runs a handshake between a client and a server
+// in memory, and prints its transcript.
+func runVectors(e *env, args []string) error {
+	fs := newFlagSet(e, "vectors", "")
+	var (
+		paramsName = fs.String("params", "", "name of the params (required)")
+		username   = fs.String("username", "alice", "username")
+		password   = fs.String("password", "", "password (default: read from the standard input)")
+		saltHex    = fs.String("salt", "", "salt, in hexadecimal (default: random)")
+	)
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *paramsName == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	params, err := lookupParams(*paramsName)
+	if err != nil {
+		return err
+	}
+	salt, err := parseSalt(params, *saltHex)
+	if err != nil {
+		return err
+	}
+	pw, err := readPassword(e.stdin, *password)
+	if err != nil {
+		return err
+	}
+
+	var r srp.TranscriptRecorder
+	if err := localHandshake(params, *username, pw, salt, &r); err != nil {
+		return err
+	}
+	return r.WriteJSON(e.stdout)
+}
+
+// localHandshake runs a handshake between a client and a
+// server in memory, recording its values in r.
+func localHandshake(params *srp.Params, username, password string, salt []byte, r *srp.TranscriptRecorder) error {
+	t, err := srp.ComputeVerifier(params, username, password, salt)
+	if err != nil {
+		return err
+	}
+
+	client, err := srp.NewClient(params, username, password, salt, srp.WithTranscript(r))
+	if err != nil {
+		return err
+	}
+	server, err := srp.NewServerFromTriplet(params, t, srp.WithTranscript(r))
+	if err != nil {
+		return err
+	}
+
+	if err := server.SetA(client.A()); err != nil {
+		return err
+	}
+	if err := client.SetB(server.B()); err != nil {
+		return err
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		return err
+	}
+	if ok, err := server.CheckM1(M1); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("server failed to verify M1")
+	}
+	M2, err := server.ComputeM2()
+	if err != nil {
+		return err
+	}
+	if ok, err := client.CheckM2(M2); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("client failed to verify M2")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Command srp provisions and debugs SRP users.
+//
+// Usage:
+//
+//	srp <command> [flags]
+//
+// The commands are:
+//
+//	params    list the params known to the tool
+//	verifier  generate a salt and a verifier for a user
+//	dump      print the contents of a triplet
+//	client    run a client handshake against a server
+//	server    run a server accepting handshakes for a user
+//	vectors   run a handshake locally and print its transcript
+//
+// Passwords are read from the first line of the standard input
+// unless they are given with -password, which leaves them in the
+// history of the shell.
+//
+// Triplets are printed as versioned text, tagged with the name
+// of their params (see [srp.TaggedTriplet.Base64]). The dump,
+// client and server commands also accept untagged triplets
+// (see [srp.Triplet.Base64]).
+//
+// The client and server commands exchange the messages of
+// [srp.ClientHandshake] and [srp.ServerHandshake] over TCP.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	name  string
+	usage string
+	run   func(env *env, args []string) error
+}
+
+var commands = []command{
+	{"params", "list the params known to the tool", runParams},
+	{"verifier", "generate a salt and a verifier for a user", runVerifier},
+	{"dump", "print the contents of a triplet", runDump},
+	{"client", "run a client handshake against a server", runClient},
+	{"server", "run a server accepting handshakes for a user", runServer},
+	{"vectors", "run a handshake locally and print its transcript", runVectors},
+}
+
+// env holds the standard streams of the tool.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// errUsage is returned when the tool is invoked with
+// invalid arguments, after the usage was printed.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	e := &env{os.Stdin, os.Stdout, os.Stderr}
+	if err := run(e, os.Args[1:]); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, "srp:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run runs the command named by args[0].
+func run(e *env, args []string) error {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return errUsage
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(e, args[1:])
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		usage(e.stdout)
+		return nil
+	}
+
+	fmt.Fprintf(e.stderr, "srp: unknown command %q\n", args[0])
+	usage(e.stderr)
+	return errUsage
+}
+
+// usage prints the list of commands to w.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: srp <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "srp <command> -h" for the flags of a command.`)
+}
+
+// newFlagSet returns the flag set of the named command.
+func newFlagSet(e *env, name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: srp %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args with fs, and returns errUsage if
+// they are invalid, or if the number of positional arguments
+// is not between min and max.
+func parseFlags(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < min || fs.NArg() > max {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
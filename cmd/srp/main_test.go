@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"code.posterity.life/srp/v2"
+)
+
+// runCommand runs the tool with args and stdin, and returns
+// its standard output.
+func runCommand(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	e := &env{strings.NewReader(stdin), &stdout, &stderr}
+	err := run(e, args)
+	return stdout.String(), err
+}
+
+func TestLookupParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		bits  int
+		valid bool
+	}{
+		{"apple-hap", 3072, true},
+		{"thinbus", 2048, true},
+		{"tls-srp-16", 4096, true},
+		{"ffdhe3072-sha256", 3072, true},
+		{"14-sha512", 2048, true},
+		{"ffdhe3072-md5", 0, false},
+		{"tls-srp-42", 0, false},
+		{"unknown", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := lookupParams(tt.name)
+			if !tt.valid {
+				if err == nil {
+					t.Fatal("expected params to be unknown")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bits := params.Group.N.BitLen(); bits != tt.bits {
+				t.Fatalf("wanted a %d-bit group, got %d", tt.bits, bits)
+			}
+		})
+	}
+}
+
+func TestVerifierAndDump(t *testing.T) {
+	out, err := runCommand(t, "password123\n", "verifier", "-params", "ffdhe2048-sha256", "-username", "alice", "-salt", "0102")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generated tripletInfo
+	if err := json.Unmarshal([]byte(out), &generated); err != nil {
+		t.Fatal(err)
+	}
+	if generated.Params != "ffdhe2048-sha256" || generated.Username != "alice" || generated.Salt != "0102" {
+		t.Fatalf("unexpected triplet %+v", generated)
+	}
+	tagged, err := srp.ParseTaggedTripletBase64(generated.Triplet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = runCommand(t, generated.Triplet, "dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dumped tripletInfo
+	if err := json.Unmarshal([]byte(out), &dumped); err != nil {
+		t.Fatal(err)
+	}
+	if dumped != generated {
+		t.Fatalf("wanted %+v, got %+v", generated, dumped)
+	}
+
+	// Untagged triplets are dumped without params.
+	untagged := generated
+	untagged.Params = ""
+	untagged.Triplet = tagged.Triplet().Base64()
+	out, err = runCommand(t, "", "dump", untagged.Triplet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dumped = tripletInfo{}
+	if err := json.Unmarshal([]byte(out), &dumped); err != nil {
+		t.Fatal(err)
+	}
+	if dumped != untagged {
+		t.Fatalf("wanted %+v, got %+v", untagged, dumped)
+	}
+
+	if _, err := runCommand(t, "", "dump", "srp-v2:AQ"); err == nil {
+		t.Fatal("expected an unknown version to be rejected")
+	}
+	if _, err := runCommand(t, "", "dump", "srp-v1:AQ"); err == nil {
+		t.Fatal("expected a truncated triplet to be rejected")
+	}
+}
+
+func TestVectors(t *testing.T) {
+	out, err := runCommand(t, "", "vectors", "-params", "thinbus", "-password", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tr srp.Transcript
+	if err := json.Unmarshal([]byte(out), &tr); err != nil {
+		t.Fatal(err)
+	}
+	if tr.Params != "thinbus" || tr.M2 == "" {
+		t.Fatalf("incomplete transcript %+v", tr)
+	}
+	if len(tr.Mismatches) > 0 {
+		t.Fatalf("unexpected mismatches %v", tr.Mismatches)
+	}
+}
+
+func TestClientServer(t *testing.T) {
+	params, err := lookupParams("ffdhe2048-sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	triplet, err := srp.ComputeVerifier(params, "alice", "password123", srp.NewSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var served bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(&env{stdout: &served}, ln, params, triplet, 2, 5*time.Second, false)
+	}()
+
+	args := []string{"client", "-addr", ln.Addr().String(), "-params", params.Name, "-username", "alice"}
+	out, err := runCommand(t, "password123\n", args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCommand(t, "password124\n", args...); err == nil {
+		t.Fatal("expected a wrong password to be rejected")
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(served.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wanted 2 lines of output, got %q", served.String())
+	}
+	fields := strings.Fields(out)
+	if key := fields[len(fields)-1]; !strings.HasSuffix(lines[0], " "+key) {
+		t.Fatalf("session keys differ: %q and %q", out, lines[0])
+	}
+	if !strings.Contains(lines[1], "handshake failed") {
+		t.Fatalf("expected the second handshake to fail, got %q", lines[1])
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if _, err := runCommand(t, ""); err != errUsage {
+		t.Fatalf("wanted errUsage, got %v", err)
+	}
+	if _, err := runCommand(t, "", "unknown"); err != errUsage {
+		t.Fatalf("wanted errUsage, got %v", err)
+	}
+	if _, err := runCommand(t, "", "verifier", "-username", "alice"); err != errUsage {
+		t.Fatalf("wanted errUsage, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.posterity.life/srp/v2"
+
+	_ "crypto/sha1" //#nosec
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// profiles are the predefined params of the srp package.
+var profiles = []*srp.Params{
+	srp.AppleProfile,
+	srp.OnePasswordProfile,
+	srp.ThinbusProfile,
+}
+
+// hashes are the hash functions that can be named in
+// generic params.
+var hashes = map[string]crypto.Hash{
+	"sha1":   crypto.SHA1,
+	"sha256": crypto.SHA256,
+	"sha384": crypto.SHA384,
+	"sha512": crypto.SHA512,
+}
+
+// groupIDs are the IDs of the groups predefined by the
+// srp package.
+var groupIDs = []string{
+	"2", "5", "14", "15", "16", "17", "18",
+	"ffdhe2048", "ffdhe3072", "ffdhe4096", "ffdhe6144", "ffdhe8192",
+}
+
+// lookupParams returns the params identified by name, which
+// is one of:
+//
+//   - the name of a predefined profile (e.g. "apple-hap");
+//   - "tls-srp-<group>", the params of TLS-SRP (e.g. "tls-srp-16");
+//   - "<group>-<hash>", a group with a hash and the KDF of
+//     RFC 5054 (e.g. "ffdhe3072-sha256").
+//
+// The KDF of params cannot be given on the command line, so
+// params using another KDF (e.g. Argon2) are not available.
+func lookupParams(name string) (*srp.Params, error) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	if id, ok := strings.CutPrefix(name, "tls-srp-"); ok {
+		g, err := srp.LookupGroup(id)
+		if err != nil {
+			return nil, err
+		}
+		return srp.TLSParams(g), nil
+	}
+
+	if i := strings.LastIndexByte(name, '-'); i > 0 {
+		if hash, ok := hashes[name[i+1:]]; ok {
+			g, err := srp.LookupGroup(name[:i])
+			if err != nil {
+				return nil, err
+			}
+			return &srp.Params{
+				Name:  name,
+				Group: g,
+				Hash:  hash,
+				KDF:   srp.RFC5054KDF,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown params %q; run \"srp params\" for a list", name)
+}
+
+// runParams lists the params known to the tool.
+func runParams(e *env, args []string) error {
+	fs := newFlagSet(e, "params", "")
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.stdout, "Profiles:")
+	for _, p := range profiles {
+		fmt.Fprintf(e.stdout, "  %-12s %d-bit group %s, %s\n", p.Name, p.Group.N.BitLen(), p.Group.ID, p.Hash)
+	}
+
+	names := make([]string, 0, len(hashes))
+	for name := range hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(e.stdout)
+	fmt.Fprintln(e.stdout, "Generic params, using the KDF of RFC 5054:")
+	fmt.Fprintln(e.stdout, "  tls-srp-<group>   TLS-SRP, with SHA-1")
+	fmt.Fprintf(e.stdout, "  <group>-<hash>    with hash in %s\n", strings.Join(names, ", "))
+	fmt.Fprintln(e.stdout)
+	fmt.Fprintln(e.stdout, "Groups:")
+	for _, id := range groupIDs {
+		g, err := srp.LookupGroup(id)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "  %-12s %d bits\n", id, g.N.BitLen())
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"code.posterity.life/srp/v2"
+)
+
+// tripletInfo is the JSON description of a triplet printed
+// by the verifier and dump commands.
+type tripletInfo struct {
+	Params   string `json:"params,omitempty"`
+	Username string `json:"username"`
+	Salt     string `json:"salt"`
+	Verifier string `json:"verifier"`
+	Triplet  string `json:"triplet"`
+}
+
+// newTripletInfo returns the description of t, tagged with
+// the name of params if not empty.
+func newTripletInfo(params string, t srp.Triplet) (tripletInfo, error) {
+	encoded := t.Base64()
+	if params != "" {
+		tagged, err := srp.NewTaggedTriplet(&srp.Params{Name: params}, t)
+		if err != nil {
+			return tripletInfo{}, err
+		}
+		encoded = tagged.Base64()
+	}
+	return tripletInfo{
+		Params:   params,
+		Username: t.Username(),
+		Salt:     hex.EncodeToString(t.Salt()),
+		Verifier: hex.EncodeToString(t.Verifier()),
+		Triplet:  encoded,
+	}, nil
+}
+
+// writeJSON writes v to w as an indented JSON object.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// readPassword returns password if not empty, or the first
+// line of r otherwise.
+func readPassword(r io.Reader, password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("password is required, with -password or on the standard input")
+	}
+	return line, nil
+}
+
+// parseTriplet decodes s, a triplet encoded with
+// [srp.TaggedTriplet.Base64] or [srp.Triplet.Base64].
+//
+// The name of the params is empty for untagged triplets.
+func parseTriplet(s string) (string, srp.Triplet, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "srp-tagged-") {
+		t, err := srp.ParseTaggedTripletBase64(s)
+		if err != nil {
+			return "", nil, err
+		}
+		return t.ParamsName(), t.Triplet(), nil
+	}
+	t, err := srp.ParseTripletBase64(s)
+	if err != nil {
+		return "", nil, err
+	}
+	return "", t, nil
+}
+
+// runVerifier generates a salt and a verifier for a user.
+func runVerifier(e *env, args []string) error {
+	fs := newFlagSet(e, "verifier", "")
+	var (
+		paramsName = fs.String("params", "", "name of the params (required)")
+		username   = fs.String("username", "", "username (required)")
+		password   = fs.String("password", "", "password (default: read from the standard input)")
+		saltHex    = fs.String("salt", "", "salt, in hexadecimal (default: random)")
+	)
+	if err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *paramsName == "" || *username == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	params, err := lookupParams(*paramsName)
+	if err != nil {
+		return err
+	}
+	salt, err := parseSalt(params, *saltHex)
+	if err != nil {
+		return err
+	}
+	pw, err := readPassword(e.stdin, *password)
+	if err != nil {
+		return err
+	}
+
+	t, err := srp.ComputeVerifier(params, *username, pw, salt)
+	if err != nil {
+		return err
+	}
+	info, err := newTripletInfo(params.Name, t)
+	if err != nil {
+		return err
+	}
+	return writeJSON(e.stdout, info)
+}
+
+// parseSalt decodes s from hexadecimal, or returns a new
+// random salt for params if s is empty.
+func parseSalt(params *srp.Params, s string) ([]byte, error) {
+	if s == "" {
+		return params.NewSalt(), nil
+	}
+	salt, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("salt is not valid hexadecimal: %w", err)
+	}
+	return salt, nil
+}
+
+// runDump prints the contents of a triplet.
+func runDump(e *env, args []string) error {
+	fs := newFlagSet(e, "dump", "[triplet]")
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+
+	s := fs.Arg(0)
+	if s == "" {
+		b, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return err
+		}
+		s = string(b)
+	}
+
+	name, t, err := parseTriplet(s)
+	if err != nil {
+		return err
+	}
+	info, err := newTripletInfo(name, t)
+	if err != nil {
+		return err
+	}
+	return writeJSON(e.stdout, info)
+}
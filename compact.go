@@ -0,0 +1,153 @@
+package srp
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Compression flags of a compact payload.
+const (
+	compactRaw     byte = 0
+	compactDeflate byte = 1
+)
+
+// EncodeCompact returns m as a compact string suitable
+// for air-gapped or camera-based exchanges (e.g. QR codes).
+//
+// The framed message is prefixed with a compression flag,
+// compressed with DEFLATE when it makes it smaller, and
+// encoded in Base45 as defined in [RFC9285], whose alphabet
+// matches the alphanumeric mode of QR codes.
+//
+// The length of the returned string never exceeds
+// ceil(3 * (len(m.Payload) + 4) / 2) characters. Public
+// ephemeral keys are indistinguishable from random and
+// don't compress, so A or B is encoded in at most
+// 1542 characters for an 8192-bit group (774 for a 4096-bit
+// group), which fits in a single QR code.
+//
+// [RFC9285]: https://datatracker.ietf.org/doc/html/rfc9285
+func EncodeCompact(m Message) (string, error) {
+	frame, err := m.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(compactDeflate)
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(frame); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	payload := buf.Bytes()
+	if len(payload) >= 1+len(frame) {
+		payload = append([]byte{compactRaw}, frame...)
+	}
+	return encodeBase45(payload), nil
+}
+
+// DecodeCompact decodes a message encoded
+// with [EncodeCompact].
+func DecodeCompact(s string) (Message, error) {
+	payload, err := decodeBase45(s)
+	if err != nil {
+		return Message{}, err
+	}
+	if len(payload) == 0 {
+		return Message{}, errors.New("compact payload is empty")
+	}
+
+	var frame []byte
+	switch payload[0] {
+	case compactRaw:
+		frame = payload[1:]
+	case compactDeflate:
+		r := flate.NewReader(bytes.NewReader(payload[1:]))
+		defer r.Close()
+
+		// Never inflate more than the largest
+		// possible frame.
+		limit := int64(messageHeaderSize + MaxPayloadSize)
+		frame, err = io.ReadAll(io.LimitReader(r, limit+1))
+		if err != nil {
+			return Message{}, err
+		}
+		if int64(len(frame)) > limit {
+			return Message{}, errors.New("compact payload is too large")
+		}
+	default:
+		return Message{}, fmt.Errorf("unknown compression flag %d", payload[0])
+	}
+
+	var m Message
+	if err := m.UnmarshalBinary(frame); err != nil {
+		return Message{}, err
+	}
+	return m, nil
+}
+
+// base45Alphabet is the alphabet defined in RFC 9285.
+const base45Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// encodeBase45 returns b encoded in Base45.
+func encodeBase45(b []byte) string {
+	var sb strings.Builder
+	sb.Grow((len(b)*3 + 1) / 2)
+	for i := 0; i+1 < len(b); i += 2 {
+		n := int(b[i])<<8 | int(b[i+1])
+		sb.WriteByte(base45Alphabet[n%45])
+		sb.WriteByte(base45Alphabet[(n/45)%45])
+		sb.WriteByte(base45Alphabet[n/(45*45)])
+	}
+	if len(b)%2 == 1 {
+		n := int(b[len(b)-1])
+		sb.WriteByte(base45Alphabet[n%45])
+		sb.WriteByte(base45Alphabet[n/45])
+	}
+	return sb.String()
+}
+
+// decodeBase45 decodes a Base45-encoded string.
+func decodeBase45(s string) ([]byte, error) {
+	if len(s)%3 == 1 {
+		return nil, errors.New("invalid base45 length")
+	}
+
+	values := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		n := strings.IndexByte(base45Alphabet, s[i])
+		if n < 0 {
+			return nil, fmt.Errorf("invalid base45 character %q", s[i])
+		}
+		values[i] = n
+	}
+
+	b := make([]byte, 0, len(s)*2/3)
+	for i := 0; i < len(values); i += 3 {
+		if i+2 < len(values) {
+			n := values[i] + values[i+1]*45 + values[i+2]*45*45
+			if n > 0xFFFF {
+				return nil, errors.New("invalid base45 triplet")
+			}
+			b = append(b, byte(n>>8), byte(n))
+		} else {
+			n := values[i] + values[i+1]*45
+			if n > 0xFF {
+				return nil, errors.New("invalid base45 pair")
+			}
+			b = append(b, byte(n))
+		}
+	}
+	return b, nil
+}
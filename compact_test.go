@@ -0,0 +1,93 @@
+package srp
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Test vectors imported from RFC 9285 – Section 4.3
+// https://datatracker.ietf.org/doc/html/rfc9285#section-4.3
+func TestBase45(t *testing.T) {
+	tests := map[string]string{
+		"AB":      "BB8",
+		"Hello!!": "%69 VD92EX0",
+		"base-45": "UJCLQE7W581",
+		"ietf!":   "QED8WEX0",
+	}
+	for plain, encoded := range tests {
+		if got := encodeBase45([]byte(plain)); got != encoded {
+			t.Fatalf("encode %q: wanted %q, got %q", plain, encoded, got)
+		}
+
+		got, err := decodeBase45(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, plain, []byte(plain), got)
+	}
+}
+
+func TestBase45Invalid(t *testing.T) {
+	for _, s := range []string{"A", "GGW", "ZZZZ", "ab"} {
+		if _, err := decodeBase45(s); err == nil {
+			t.Fatalf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	groups := []*Group{
+		RFC5054Group1024,
+		RFC5054Group4096,
+		RFC5054Group8192,
+	}
+	for _, g := range groups {
+		params := &Params{Group: g, Hash: params.Hash, KDF: params.KDF}
+		_, B, _ := newClientKeyPair(params, nil)
+
+		m := Message{Type: MessageB, Payload: B.Bytes()}
+		s, err := EncodeCompact(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		max := (3*(len(m.Payload)+4) + 1) / 2
+		if len(s) > max {
+			t.Fatalf("%d-bit group: encoded length %d exceeds %d", g.N.BitLen(), len(s), max)
+		}
+
+		got, err := DecodeCompact(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Type != m.Type {
+			t.Fatalf("wanted type %s, got %s", m.Type, got.Type)
+		}
+		assertEqualBytes(t, "payload", m.Payload, got.Payload)
+	}
+}
+
+func TestCompactCompressed(t *testing.T) {
+	m := Message{Type: MessageUsername, Payload: bytes.Repeat([]byte("a"), 512)}
+	s, err := EncodeCompact(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) >= len(m.Payload) {
+		t.Fatalf("expected repetitive payload to be compressed, got %d characters", len(s))
+	}
+
+	got, err := DecodeCompact(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "payload", m.Payload, got.Payload)
+}
+
+func TestDecodeCompactInvalid(t *testing.T) {
+	for _, s := range []string{"", encodeBase45([]byte{0x09, 0x01}), encodeBase45([]byte{compactRaw, 0x01})} {
+		if _, err := DecodeCompact(s); err == nil {
+			t.Fatalf("expected %q to be rejected", s)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package srp
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestServerConcurrentUse calls the methods of a server from
+// concurrent goroutines, as HTTP handlers sharing a restored
+// server would. Run with -race.
+func TestServerConcurrentUse(t *testing.T) {
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetB(server.B()); err != nil {
+		t.Fatal(err)
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := server.SetA(client.A()); err != nil {
+				t.Error(err)
+				return
+			}
+			if ok, err := server.CheckM1(M1); err != nil || !ok {
+				t.Errorf("expected M1 to be verified, got %v, %v", ok, err)
+				return
+			}
+			if _, err := server.ComputeM2(); err != nil {
+				t.Error(err)
+			}
+			if _, err := server.SessionKey(); err != nil {
+				t.Error(err)
+			}
+			if _, err := server.Save(); err != nil {
+				t.Error(err)
+			}
+			_ = server.B()
+			_ = server.LegacyProof()
+		}()
+	}
+	wg.Wait()
+}
+
+// TestClientConcurrentUse calls the methods of a client
+// from concurrent goroutines. Run with -race.
+func TestClientConcurrentUse(t *testing.T) {
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(client.A()); err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := client.SetB(server.B()); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := client.ComputeM1(); err != nil {
+				t.Error(err)
+			}
+			if _, err := client.SessionKey(); err != nil {
+				t.Error(err)
+			}
+			_ = client.A()
+			_ = client.Offer()
+		}()
+	}
+	wg.Wait()
+}
+
+// TestSessionConcurrentUse issues and verifies challenges
+// from concurrent goroutines. Run with -race.
+func TestSessionConcurrentUse(t *testing.T) {
+	client, server := newSessions(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			challenge, err := server.Challenge()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			response, err := client.Respond(challenge, string(P))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// Another goroutine may have issued a new challenge,
+			// or consumed this one: only the absence of races
+			// is checked.
+			server.Verify(response)
+		}()
+	}
+	wg.Wait()
+}
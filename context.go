@@ -0,0 +1,32 @@
+package srp
+
+import "context"
+
+// runContext calls f in a new goroutine, and returns its
+// error, or the error of ctx if ctx is done first.
+//
+// f keeps running in the background after ctx is done, and
+// its result is discarded: it must only read values that are
+// not modified afterwards, and only write to local variables
+// of the caller.
+func runContext(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		// ctx can never be canceled.
+		return f()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,71 @@
+package srp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewClientContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	p := *params
+	p.KDF = func(username, password string, salt []byte) ([]byte, error) {
+		<-release
+		return params.KDF(username, password, salt)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := NewClientContext(ctx, &p, string(I), string(P), salt.Bytes()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if _, err := ComputeVerifierContext(ctx, &p, string(I), string(P), salt.Bytes()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestSetBContext(t *testing.T) {
+	client, err := NewClientContext(context.Background(), params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.SetBContext(ctx, server.B()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := client.ComputeM1(); !errors.Is(err, ErrClientNotReady) {
+		t.Fatalf("expected the client to be unchanged, got %v", err)
+	}
+	if err := server.SetAContext(ctx, client.A()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := server.CheckM1([]byte("M1")); !errors.Is(err, ErrServerNoReady) {
+		t.Fatalf("expected the server to be unchanged, got %v", err)
+	}
+
+	// The handshake succeeds with a live context.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := server.SetAContext(ctx, client.A()); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetBContext(ctx, server.B()); err != nil {
+		t.Fatal(err)
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := server.CheckM1(M1); err != nil || !ok {
+		t.Fatalf("expected M1 to be verified, got %v, %v", ok, err)
+	}
+}
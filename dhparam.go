@@ -0,0 +1,119 @@
+package srp
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// pemDHParameters is the type of the PEM blocks
+// produced by openssl dhparam.
+const pemDHParameters = "DH PARAMETERS"
+
+// dhParameter is the ASN.1 structure of Diffie-Hellman
+// parameters defined in PKCS #3:
+//
+//	DHParameter ::= SEQUENCE {
+//	  prime INTEGER, -- p
+//	  base INTEGER, -- g
+//	  privateValueLength INTEGER OPTIONAL }
+type dhParameter struct {
+	Prime              *big.Int
+	Base               *big.Int
+	PrivateValueLength int `asn1:"optional"`
+}
+
+// ParseGroupFromPEM parses the first "DH PARAMETERS" block
+// of data, such as a file generated with:
+//
+//	openssl dhparam -out dhparam.pem 4096
+//
+// See [ParseGroupFromDER] for how the group is identified
+// and validated.
+func ParseGroupFromPEM(data []byte) (*Group, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no %s block found", pemDHParameters)
+		}
+		if block.Type == pemDHParameters {
+			return ParseGroupFromDER(block.Bytes)
+		}
+	}
+}
+
+// ParseGroupFromDER parses DER-encoded PKCS #3 Diffie-Hellman
+// parameters, and validates the resulting group with
+// [Group.Validate].
+//
+// If the parameters match a group predefined by this package
+// (e.g. ffdhe3072), that group is returned. Otherwise, the
+// group is identified as "dh<bits>-<fingerprint>", and its
+// exponent size is taken from the privateValueLength of the
+// parameters, or chosen from the length of N when absent.
+func ParseGroupFromDER(der []byte) (*Group, error) {
+	var params dhParameter
+	rest, err := asn1.Unmarshal(der, &params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DH parameters: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after DH parameters")
+	}
+	if params.Prime == nil || params.Base == nil {
+		return nil, errors.New("DH parameters are incomplete")
+	}
+
+	g := &Group{
+		Generator: params.Base,
+		N:         params.Prime,
+	}
+	for _, predefined := range groups {
+		if sameGroup(predefined, g) {
+			return predefined, nil
+		}
+	}
+
+	g.ID = groupFingerprint(g)
+	g.ExponentSize = exponentSize(g.N.BitLen())
+	if params.PrivateValueLength > 0 {
+		g.ExponentSize = (params.PrivateValueLength + 7) / 8
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// groupFingerprint returns an identifier derived
+// from the values of g.
+func groupFingerprint(g *Group) string {
+	h := sha256.New()
+	h.Write(g.N.Bytes())
+	h.Write(g.Generator.Bytes())
+	return fmt.Sprintf("dh%d-%s", g.N.BitLen(), hex.EncodeToString(h.Sum(nil)[:4]))
+}
+
+// exponentSize returns the size in bytes of the private
+// exponents of a group whose modulus is bits long, following
+// the recommendations of RFC 7919, section 5.2.
+func exponentSize(bits int) int {
+	switch {
+	case bits >= 8192:
+		return 50
+	case bits >= 6144:
+		return 47
+	case bits >= 4096:
+		return 41
+	case bits >= 3072:
+		return 35
+	default:
+		return 29
+	}
+}
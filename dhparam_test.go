@@ -0,0 +1,124 @@
+package srp
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// Parameters generated with OpenSSL 3.0:
+//
+//	openssl dhparam -out dhparam.pem 1024
+const openSSLDHParam1024 = `-----BEGIN DH PARAMETERS-----
+MIGHAoGBAPW/Rr3tqTFA7MFiWpYdGo4SO8EugWlmgFxP4rKAatWpJRn5txCJwMI5
+IRj0bdBYunrMGZj4B1WQeU9hGOKjx4YFukKn8tJ5A+JByiDSnUhNdMHFDOLNfsl/
+kCOxnS0Lh6H26djYJB+f+tL6suIiQ61vS7Nq96TIhBQBEagRVw7vAgEC
+-----END DH PARAMETERS-----
+`
+
+// Parameters generated with OpenSSL 3.0:
+//
+//	openssl genpkey -genparam -algorithm DH -pkeyopt group:ffdhe2048
+const openSSLFFDHE2048 = `-----BEGIN DH PARAMETERS-----
+MIIBCAKCAQEA//////////+t+FRYortKmq/cViAnPTzx2LnFg84tNpWp4TZBFGQz
++8yTnc4kmz75fS/jY2MMddj2gbICrsRhetPfHtXV/WVhJDP1H18GbtCFY2VVPe0a
+87VXE15/V8k1mE8McODmi3fipona8+/och3xWKE2rec1MKzKT0g6eXq8CrGCsyT7
+YdEIqUuyyOP7uWrat2DX9GgdT0Kj3jlN9K5W7edjcrsZCwenyO4KbXCeAvzhzffi
+7MA0BM0oNC9hkXL+nOmFg/+OTxIy7vKBg8P+OxtMb61zO7X8vC7CIAXFjvGDfRaD
+ssbzSibBsu/6iGtCOGEoXJf//////////wIBAg==
+-----END DH PARAMETERS-----
+`
+
+func TestParseGroupFromPEM(t *testing.T) {
+	g, err := ParseGroupFromPEM([]byte(openSSLDHParam1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.N.BitLen() != 1024 {
+		t.Fatalf("expected a 1024-bit group, got %d bits", g.N.BitLen())
+	}
+	if g.Generator.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("expected generator 2, got %v", g.Generator)
+	}
+	if !strings.HasPrefix(g.ID, "dh1024-") {
+		t.Fatalf("unexpected group ID %q", g.ID)
+	}
+	if g.ExponentSize != 29 {
+		t.Fatalf("expected an exponent size of 29 bytes, got %d", g.ExponentSize)
+	}
+
+	params := &Params{
+		Name:  "dhparam",
+		Group: g,
+		Hash:  crypto.SHA256,
+		KDF:   RFC5054KDF,
+	}
+	triplet, err := ComputeVerifier(params, "alice", "password123", NewSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(params, "alice", "password123", triplet.Salt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, triplet.Username(), triplet.Salt(), triplet.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseGroupFromPEMPredefined(t *testing.T) {
+	g, err := ParseGroupFromPEM([]byte(openSSLFFDHE2048))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != FFDHE2048 {
+		t.Fatalf("expected FFDHE2048, got %s", g.ID)
+	}
+}
+
+func TestParseGroupFromDER(t *testing.T) {
+	block, _ := pem.Decode([]byte(openSSLDHParam1024))
+	der := block.Bytes
+
+	if _, err := ParseGroupFromDER(der); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseGroupFromDER(der[:len(der)-1]); err == nil {
+		t.Fatal("expected truncated parameters to be rejected")
+	}
+	if _, err := ParseGroupFromDER(append(der, 0)); err == nil {
+		t.Fatal("expected trailing data to be rejected")
+	}
+
+	// A valid encoding of a prime that is not safe.
+	notSafe, err := asn1.Marshal(dhParameter{
+		Prime: new(big.Int).Sub(new(big.Int).Lsh(bigOne, 1279), bigOne),
+		Base:  big.NewInt(2),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseGroupFromDER(notSafe); err == nil {
+		t.Fatal("expected a group that is not safe to be rejected")
+	}
+}
+
+func TestParseGroupFromPEMInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"not a PEM file",
+		"-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n",
+	}
+	for _, input := range inputs {
+		if _, err := ParseGroupFromPEM([]byte(input)); err == nil {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+}
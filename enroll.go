@@ -0,0 +1,68 @@
+package srp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Enroll prepares the registration of a new user.
+//
+// It generates a random salt of the length recommended for the
+// params' key derivation function (see [Params.SaltLength]),
+// computes the verifier, and returns the resulting triplet
+// along with the [MessageTriplet] message carrying it, which
+// the client sends to the server over a secure connection
+// (TLS). The server obtains the triplet to store with
+// [ParseEnrollment].
+//
+// Enroll runs the params' key derivation function.
+func Enroll(params *Params, username, password string) (Triplet, Message, error) {
+	return EnrollContext(context.Background(), params, username, password)
+}
+
+// EnrollContext is like [Enroll], but returns early with the
+// error of ctx if ctx is done before the params' key derivation
+// function returns.
+//
+// As with [NewClientContext], the key derivation function keeps
+// running in the background until it returns.
+func EnrollContext(ctx context.Context, params *Params, username, password string) (Triplet, Message, error) {
+	if err := params.Validate(); err != nil {
+		return nil, Message{}, err
+	}
+	if len(username) == 0 || len(username) > math.MaxUint8 {
+		return nil, Message{}, fmt.Errorf("username must be 1 to %d bytes long", math.MaxUint8)
+	}
+
+	triplet, err := ComputeVerifierContext(ctx, params, username, password, params.NewSalt())
+	if err != nil {
+		return nil, Message{}, err
+	}
+
+	m := Message{Type: MessageTriplet, Payload: triplet}
+	if len(m.Payload) > MaxPayloadSize {
+		return nil, Message{}, fmt.Errorf("triplet cannot exceed %d bytes", MaxPayloadSize)
+	}
+	return triplet, m, nil
+}
+
+// ParseEnrollment returns the triplet carried by a message
+// obtained with [Enroll], or an error if m is not a
+// [MessageTriplet] message or if the triplet is
+// mis-formatted.
+func ParseEnrollment(m Message) (Triplet, error) {
+	if m.Type != MessageTriplet {
+		return nil, fmt.Errorf("expected a %s message, got %s", MessageTriplet, m.Type)
+	}
+
+	triplet := Triplet(append([]byte(nil), m.Payload...))
+	if err := triplet.validate(); err != nil {
+		return nil, err
+	}
+	if len(triplet.Username()) == 0 {
+		return nil, errors.New("triplet does not contain a username")
+	}
+	return triplet, nil
+}
@@ -0,0 +1,76 @@
+package srp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEnroll(t *testing.T) {
+	triplet, m, err := Enroll(params, "alice", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(triplet.Salt()); n != params.SaltLength() {
+		t.Fatalf("expected a %d-byte salt, got %d", params.SaltLength(), n)
+	}
+
+	// The message goes through the wire format.
+	var buf bytes.Buffer
+	if err := WriteMessage(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	received, err := ReadMessage(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := ParseEnrollment(received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "triplet", triplet, stored)
+
+	client, err := NewClient(params, "alice", "password123", stored.Salt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServerFromTriplet(params, stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnrollErrors(t *testing.T) {
+	if _, _, err := Enroll(params, "", "password123"); err == nil {
+		t.Fatal("expected an error for an empty username")
+	}
+	if _, _, err := Enroll(params, strings.Repeat("a", 256), "password123"); err == nil {
+		t.Fatal("expected an error for a username longer than 255 bytes")
+	}
+	if _, _, err := Enroll(&Params{Group: RFC5054Group1024}, "alice", "password123"); err == nil {
+		t.Fatal("expected an error for invalid params")
+	}
+}
+
+func TestParseEnrollment(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Message
+	}{
+		{"WrongType", Message{Type: MessageA, Payload: NewTriplet("alice", salt.Bytes(), v.Bytes())}},
+		{"Empty", Message{Type: MessageTriplet}},
+		{"NoVerifier", Message{Type: MessageTriplet, Payload: NewTriplet("alice", salt.Bytes(), nil)}},
+		{"NoUsername", Message{Type: MessageTriplet, Payload: NewTriplet("", salt.Bytes(), v.Bytes())}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseEnrollment(tt.m); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package webapp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.posterity.life/srp/v2"
+)
+
+// ErrServerNotAuthenticated is returned when the server
+// fails to prove it knows the user's verifier.
+var ErrServerNotAuthenticated = errors.New("failed to authenticate the server")
+
+// Client is a client of the todo API.
+type Client struct {
+	BaseURL    string       // URL of the server, without a trailing slash
+	HTTPClient *http.Client // Defaults to http.DefaultClient
+
+	token string      // Session token
+	keys  sessionKeys // Keys derived from the session key
+	seq   uint64      // Last sequence number sent
+}
+
+// NewClient returns a new Client of the server at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Register registers a new user.
+//
+// The password never leaves the client: only the salt and
+// the verifier are sent to the server.
+func (c *Client) Register(username, password string) error {
+	triplet, err := srp.ComputeVerifier(Params, username, password, Params.NewSalt())
+	if err != nil {
+		return err
+	}
+
+	req := &registerRequest{
+		Username: triplet.Username(),
+		Salt:     triplet.Salt(),
+		Verifier: triplet.Verifier(),
+	}
+	return c.postJSON("/register", req, nil)
+}
+
+// Login authenticates the user, and opens a session
+// used by the subsequent requests of c.
+func (c *Client) Login(username, password string) error {
+	var start loginStartResponse
+	if err := c.postJSON("/login/start", &loginStartRequest{Username: username}, &start); err != nil {
+		return err
+	}
+
+	client, err := srp.NewClient(Params, username, password, start.Salt)
+	if err != nil {
+		return err
+	}
+	if err := client.SetB(start.B); err != nil {
+		return err
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		return err
+	}
+
+	var finish loginFinishResponse
+	req := &loginFinishRequest{
+		ID: start.ID,
+		A:  client.A(),
+		M1: M1,
+	}
+	if err := c.postJSON("/login/finish", req, &finish); err != nil {
+		return err
+	}
+
+	if ok, err := client.CheckM2(finish.M2); err != nil {
+		return err
+	} else if !ok {
+		return ErrServerNotAuthenticated
+	}
+
+	K, err := client.SessionKey()
+	if err != nil {
+		return err
+	}
+
+	c.token = finish.Token
+	c.keys = deriveKeys(K)
+	c.seq = 0
+	return nil
+}
+
+// Todos returns the todo list of the user.
+func (c *Client) Todos() ([]Todo, error) {
+	var todos []Todo
+	if err := c.do(http.MethodGet, "/todos", nil, &todos); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// AddTodo adds an item to the todo list of the user.
+func (c *Client) AddTodo(title string) (Todo, error) {
+	var todo Todo
+	if err := c.do(http.MethodPost, "/todos", &Todo{Title: title}, &todo); err != nil {
+		return Todo{}, err
+	}
+	return todo, nil
+}
+
+// do sends an authenticated request, with in encrypted as its
+// body, and decrypts the response into out.
+func (c *Client) do(method, path string, in, out any) error {
+	if c.token == "" {
+		return errors.New("client is not logged in")
+	}
+
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		if body, err = c.keys.seal(b); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	c.seq++
+	tag := srp.Tag(c.keys.auth, requestMessage(method, path, c.seq, body))
+	req.Header.Set("Authorization", authScheme+c.token)
+	req.Header.Set(headerSequence, strconv.FormatUint(c.seq, 10))
+	req.Header.Set(headerTag, base64.StdEncoding.EncodeToString(tag))
+
+	b, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	plaintext, err := c.keys.open(b)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plaintext, out)
+}
+
+// postJSON posts in as JSON, and decodes the
+// response into out unless it is nil.
+func (c *Client) postJSON(path string, in, out any) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if b, err = c.send(req); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
+
+// send sends req and returns the body of a
+// successful response.
+func (c *Client) send(req *http.Request) ([]byte, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, nil
+}
@@ -0,0 +1,81 @@
+package webapp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"strconv"
+
+	"code.posterity.life/srp/v2"
+)
+
+// Labels used to derive independent keys from the
+// session key.
+const (
+	labelAuthentication = "webapp/authentication"
+	labelEncryption     = "webapp/encryption"
+)
+
+// sessionKeys holds the keys derived from an SRP
+// session key.
+type sessionKeys struct {
+	auth []byte // Key of the request tags
+	enc  []byte // AES-256 key of the payloads
+}
+
+// deriveKeys derives the keys of a session from K.
+func deriveKeys(K srp.SessionKey) sessionKeys {
+	return sessionKeys{
+		auth: srp.Tag(K, []byte(labelAuthentication)),
+		enc:  srp.Tag(K, []byte(labelEncryption)),
+	}
+}
+
+// requestMessage returns the message authenticated by
+// the tag of a request.
+//
+// The sequence number prevents a captured request from
+// being replayed.
+func requestMessage(method, path string, seq uint64, body []byte) []byte {
+	message := []byte(method + " " + path + " " + strconv.FormatUint(seq, 10) + "\n")
+	return append(message, body...)
+}
+
+// seal encrypts plaintext, and returns it prefixed
+// with a random nonce.
+func (k sessionKeys) seal(plaintext []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts a ciphertext obtained with seal.
+func (k sessionKeys) open(ciphertext []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// aead returns the AES-GCM cipher of the payloads.
+func (k sessionKeys) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.enc)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,31 @@
+// Package webapp is an example of a small todo API
+// authenticated with SRP.
+//
+// It demonstrates:
+//
+//   - Registration: the client computes a verifier with
+//     [srp.ComputeVerifier] and sends it to the server, which
+//     stores the resulting triplet;
+//   - Login: the client and the server exchange A, B, M1 and
+//     M2 over two HTTP requests. Between them, the server keeps
+//     its state with [srp.Server.Save] rather than in memory.
+//     Unknown users go through a fake handshake created with
+//     [srp.NewFakeServer], so they cannot be told apart from
+//     registered ones;
+//   - Session-bound requests: every request made after login is
+//     authenticated with a tag computed from the session key,
+//     which the server checks before serving it;
+//   - Encrypted payloads: request and response bodies are
+//     encrypted with AES-GCM, using a key derived from the
+//     session key.
+//
+// The server does not use TLS to keep the example short, but a
+// real deployment should: SRP protects the password and the
+// session key, not the registration request, nor the metadata
+// of the requests.
+//
+// The example is a module of its own, which uses the library
+// in the parent directory, so that its imports and its tests
+// stay out of the library's build. Run its tests from its
+// directory.
+package webapp
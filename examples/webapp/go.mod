@@ -0,0 +1,9 @@
+module code.posterity.life/srp/v2/examples/webapp
+
+go 1.20
+
+require code.posterity.life/srp/v2 v2.0.0
+
+require golang.org/x/text v0.5.0 // indirect
+
+replace code.posterity.life/srp/v2 => ../..
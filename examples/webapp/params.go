@@ -0,0 +1,22 @@
+package webapp
+
+import (
+	"crypto"
+
+	"code.posterity.life/srp/v2"
+
+	_ "crypto/sha256"
+)
+
+// Params are the SRP params used by the client and the
+// server of the example.
+//
+// RFC5054KDF keeps the example free of dependencies; a real
+// application should use a KDF designed for password hashing,
+// such as Argon2.
+var Params = &srp.Params{
+	Name:  "webapp-ffdhe3072-sha256",
+	Group: srp.FFDHE3072,
+	Hash:  crypto.SHA256,
+	KDF:   srp.RFC5054KDF,
+}
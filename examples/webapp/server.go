@@ -0,0 +1,375 @@
+package webapp
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.posterity.life/srp/v2"
+)
+
+// Headers of the requests authenticated with
+// a session key.
+const (
+	headerSequence = "X-SRP-Seq"
+	headerTag      = "X-SRP-Tag"
+	authScheme     = "SRP "
+)
+
+// Todo is an item of a user's todo list.
+type Todo struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+// registerRequest is the body of POST /register.
+type registerRequest struct {
+	Username string `json:"username"`
+	Salt     []byte `json:"salt"`
+	Verifier []byte `json:"verifier"`
+}
+
+// loginStartRequest is the body of POST /login/start.
+type loginStartRequest struct {
+	Username string `json:"username"`
+}
+
+// loginStartResponse is the response to POST /login/start.
+type loginStartResponse struct {
+	ID   string        `json:"id"`
+	Salt []byte        `json:"salt"`
+	B    srp.PublicKey `json:"B"`
+}
+
+// loginFinishRequest is the body of POST /login/finish.
+type loginFinishRequest struct {
+	ID string        `json:"id"`
+	A  srp.PublicKey `json:"A"`
+	M1 srp.Proof     `json:"M1"`
+}
+
+// loginFinishResponse is the response to POST /login/finish.
+type loginFinishResponse struct {
+	M2    srp.Proof `json:"M2"`
+	Token string    `json:"token"`
+}
+
+// pendingLogin holds the state of a login
+// between its two requests.
+type pendingLogin struct {
+	username string
+	state    []byte // Saved SRP server state
+}
+
+// session holds the state of a logged-in user.
+type session struct {
+	username string
+	keys     sessionKeys
+	seq      uint64 // Last sequence number received
+}
+
+// Server serves the todo API.
+//
+// Users, pending logins and todo lists are kept in memory.
+type Server struct {
+	mu       sync.Mutex
+	users    map[string]srp.Triplet
+	logins   map[string]pendingLogin
+	sessions map[string]*session
+	todos    map[string][]Todo
+	mux      *http.ServeMux
+
+	fakeSecret []byte // Secret of the fake handshakes of unknown users
+}
+
+// NewServer returns a new Server with no users.
+func NewServer() *Server {
+	s := &Server{
+		users:    make(map[string]srp.Triplet),
+		logins:   make(map[string]pendingLogin),
+		sessions: make(map[string]*session),
+		todos:    make(map[string][]Todo),
+		mux:      http.NewServeMux(),
+
+		fakeSecret: make([]byte, 32),
+	}
+	if _, err := rand.Read(s.fakeSecret); err != nil {
+		panic(err)
+	}
+	s.mux.HandleFunc("/register", s.handleRegister)
+	s.mux.HandleFunc("/login/start", s.handleLoginStart)
+	s.mux.HandleFunc("/login/finish", s.handleLoginFinish)
+	s.mux.HandleFunc("/todos", s.authenticated(s.handleTodos))
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleRegister stores the triplet of a new user.
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Username == "" || len(req.Salt) == 0 || len(req.Verifier) == 0 {
+		http.Error(w, "incomplete registration", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[req.Username]; ok {
+		http.Error(w, "username is taken", http.StatusConflict)
+		return
+	}
+	s.users[req.Username] = srp.NewTriplet(req.Username, req.Salt, req.Verifier)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// handleLoginStart returns the salt of the user and the
+// server's public ephemeral key B.
+func (s *Server) handleLoginStart(w http.ResponseWriter, r *http.Request) {
+	var req loginStartRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	triplet, ok := s.users[req.Username]
+	s.mu.Unlock()
+
+	// Unknown users get a plausible salt and B, so that
+	// attackers cannot tell which usernames are registered.
+	var (
+		server *srp.Server
+		salt   []byte
+		err    error
+	)
+	if ok {
+		salt = triplet.Salt()
+		server, err = srp.NewServerFromTriplet(Params, triplet)
+	} else if salt, err = srp.FakeSalt(Params, req.Username, s.fakeSecret); err == nil {
+		server, err = srp.NewFakeServer(Params, req.Username, s.fakeSecret)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	state, err := server.Save()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id := randomID()
+	s.mu.Lock()
+	s.logins[id] = pendingLogin{
+		username: req.Username,
+		state:    state,
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, &loginStartResponse{
+		ID:   id,
+		Salt: salt,
+		B:    server.B(),
+	})
+}
+
+// handleLoginFinish checks the client proof M1, and opens
+// a session if it is valid.
+func (s *Server) handleLoginFinish(w http.ResponseWriter, r *http.Request) {
+	var req loginFinishRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// A login can only be attempted once.
+	s.mu.Lock()
+	login, ok := s.logins[req.ID]
+	delete(s.logins, req.ID)
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown login", http.StatusNotFound)
+		return
+	}
+
+	server, err := srp.RestoreServer(Params, login.state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := server.SetA(req.A); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if ok, err := server.CheckM1(req.M1); err != nil || !ok {
+		http.Error(w, "authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	M2, err := server.ComputeM2()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	K, err := server.SessionKey()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	token := randomID()
+	s.mu.Lock()
+	s.sessions[token] = &session{
+		username: login.username,
+		keys:     deriveKeys(K),
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, &loginFinishResponse{
+		M2:    M2,
+		Token: token,
+	})
+}
+
+// handleTodos lists the todos of the user on GET, and
+// adds one on POST.
+func (s *Server) handleTodos(w http.ResponseWriter, r *http.Request, sess *session, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		writeEncrypted(w, sess, s.todos[sess.username])
+
+	case http.MethodPost:
+		var todo Todo
+		if err := json.Unmarshal(body, &todo); err != nil || todo.Title == "" {
+			http.Error(w, "invalid todo", http.StatusBadRequest)
+			return
+		}
+		todo.ID = len(s.todos[sess.username]) + 1
+		s.todos[sess.username] = append(s.todos[sess.username], todo)
+		writeEncrypted(w, sess, todo)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authenticated wraps a handler of requests that must be
+// authenticated with the session key.
+//
+// The handler receives the session of the user and the
+// decrypted body of the request.
+func (s *Server) authenticated(h func(http.ResponseWriter, *http.Request, *session, []byte)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess, body, err := s.authenticate(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		h(w, r, sess, body)
+	}
+}
+
+// authenticate checks the tag and the sequence number of r,
+// and returns the session it belongs to with its decrypted body.
+func (s *Server) authenticate(r *http.Request) (*session, []byte, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), authScheme)
+	if !ok {
+		return nil, nil, errors.New("missing session token")
+	}
+	seq, err := strconv.ParseUint(r.Header.Get(headerSequence), 10, 64)
+	if err != nil {
+		return nil, nil, errors.New("invalid sequence number")
+	}
+	tag, err := base64.StdEncoding.DecodeString(r.Header.Get(headerTag))
+	if err != nil {
+		return nil, nil, errors.New("invalid tag")
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[token]
+	if !ok {
+		return nil, nil, errors.New("unknown session")
+	}
+	if !srp.VerifyTag(sess.keys.auth, requestMessage(r.Method, r.URL.Path, seq, body), tag) {
+		return nil, nil, errors.New("invalid tag")
+	}
+	if seq <= sess.seq {
+		return nil, nil, errors.New("request was replayed")
+	}
+	sess.seq = seq
+
+	if len(body) > 0 {
+		if body, err = sess.keys.open(body); err != nil {
+			return nil, nil, err
+		}
+	}
+	return sess, body, nil
+}
+
+// decodeJSON decodes the body of r into v, or writes an
+// error and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeEncrypted writes v as a JSON response encrypted
+// with the keys of sess.
+func writeEncrypted(w http.ResponseWriter, sess *session, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ciphertext, err := sess.keys.seal(b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(ciphertext)
+}
+
+// randomID returns a random identifier.
+func randomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,132 @@
+package webapp
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"code.posterity.life/srp/v2"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(NewServer())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestWebapp(t *testing.T) {
+	ts := newTestServer(t)
+
+	c := NewClient(ts.URL)
+	if err := c.Register("alice", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register("alice", "password456"); err == nil {
+		t.Fatal("expected duplicate registration to fail")
+	}
+	if err := c.Login("alice", "password123"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, title := range []string{"Buy milk", "Walk the dog"} {
+		if _, err := c.AddTodo(title); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	todos, err := c.Todos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := []Todo{
+		{ID: 1, Title: "Buy milk"},
+		{ID: 2, Title: "Walk the dog"},
+	}
+	if len(todos) != len(wanted) {
+		t.Fatalf("wanted %d todos, got %d", len(wanted), len(todos))
+	}
+	for i := range wanted {
+		if todos[i] != wanted[i] {
+			t.Fatalf("wanted %+v, got %+v", wanted[i], todos[i])
+		}
+	}
+
+	// Todo lists are not shared between users.
+	other := NewClient(ts.URL)
+	if err := other.Register("bob", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Login("bob", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if todos, err := other.Todos(); err != nil || len(todos) != 0 {
+		t.Fatalf("expected an empty list, got %v, %v", todos, err)
+	}
+}
+
+func TestWebappWrongPassword(t *testing.T) {
+	ts := newTestServer(t)
+
+	c := NewClient(ts.URL)
+	if err := c.Register("alice", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Login("alice", "password456"); err == nil {
+		t.Fatal("expected login to fail")
+	}
+	if err := c.Login("mallory", "password123"); err == nil {
+		t.Fatal("expected login of an unknown user to fail")
+	}
+	if _, err := c.Todos(); err == nil {
+		t.Fatal("expected request without a session to fail")
+	}
+}
+
+func TestWebappRejectsForgedRequests(t *testing.T) {
+	ts := newTestServer(t)
+
+	c := NewClient(ts.URL)
+	if err := c.Register("alice", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Login("alice", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Todos(); err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(seq uint64, key []byte) int {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/todos", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag := srp.Tag(key, requestMessage(http.MethodGet, "/todos", seq, nil))
+		req.Header.Set("Authorization", authScheme+c.token)
+		req.Header.Set(headerSequence, strconv.FormatUint(seq, 10))
+		req.Header.Set(headerTag, base64.StdEncoding.EncodeToString(tag))
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := send(c.seq, c.keys.auth); code != http.StatusUnauthorized {
+		t.Fatalf("expected replayed request to be rejected, got %d", code)
+	}
+	if code := send(c.seq+1, []byte("not the session key")); code != http.StatusUnauthorized {
+		t.Fatalf("expected forged request to be rejected, got %d", code)
+	}
+	if code := send(c.seq+1, c.keys.auth); code != http.StatusOK {
+		t.Fatalf("expected request to succeed, got %d", code)
+	}
+}
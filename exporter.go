@@ -0,0 +1,99 @@
+package srp
+
+import (
+	"errors"
+	"math/big"
+)
+
+// exporterInfo prefixes the info of the HKDF used
+// to export keying material.
+const exporterInfo = "srp exporter"
+
+// Tags identifying each value of the info of
+// exported keying material.
+const (
+	exporterLabel byte = iota + 1
+	exporterContext
+)
+
+// ExportKeyingMaterial returns length bytes of keying material
+// derived from the handshake of c, in the manner of [RFC5705],
+// once B has been set.
+//
+// The material is bound to the session key K and to the
+// transcript of the handshake (A, B, M1 and M2), and is
+// separated by label and by the optional context: a nil
+// context differs from an empty one. Applications use it to
+// derive their own secrets (e.g. to sign tokens, or to
+// authenticate a secondary channel) without reusing the
+// session key.
+//
+// It should only be used once the server proof has been
+// verified with [Client.CheckM2].
+//
+// [RFC5705]: https://datatracker.ietf.org/doc/html/rfc5705
+func (c *Client) ExportKeyingMaterial(label string, context []byte, length int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.xK == nil {
+		return nil, ErrClientNotReady
+	}
+	return exportKeyingMaterial(c.params, c.xA, c.xB, c.m1, c.m2, c.xK, label, context, length)
+}
+
+// ExportKeyingMaterial returns length bytes of keying material
+// derived from the handshake of s, as [Client.ExportKeyingMaterial]
+// does.
+//
+// An error is returned if the client's proof (M1) has not been
+// verified by calling the s.CheckM1 method first.
+func (s *Server) ExportKeyingMaterial(label string, context []byte, length int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.xK == nil {
+		return nil, ErrServerNoReady
+	}
+	if !s.verifiedM1 {
+		return nil, errors.New("client must show their proof first")
+	}
+	return exportKeyingMaterial(s.params, s.xA, s.xB, s.m1, s.m2, s.xK, label, context, length)
+}
+
+// exportKeyingMaterial derives keying material from the
+// session key K and the transcript of a handshake.
+//
+// A and B are padded to the length of N, and the proofs to
+// the size of the hash, so that the transcript does not
+// depend on their leading zeros.
+//
+// Formula:
+//
+//	T   = H(PAD(A) | PAD(B) | M1 | M2)
+//	EKM = HKDF(K, T, "srp exporter" | label [| context], length)
+func exportKeyingMaterial(params *Params, A, B, M1, M2 *big.Int, K []byte, label string, context []byte, length int) ([]byte, error) {
+	if length <= 0 {
+		return nil, errors.New("length of keying material must be positive")
+	}
+
+	h := newHasher(params)
+	for _, i := range [...]*big.Int{A, B} {
+		if err := h.writeInt(i, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, i := range [...]*big.Int{M1, M2} {
+		h.Write(i.FillBytes(make([]byte, h.Size())))
+	}
+	transcript := h.Sum(nil)
+
+	info := append([]byte(exporterInfo), bindingItem(exporterLabel, []byte(label))...)
+	if context != nil {
+		info = append(info, bindingItem(exporterContext, context)...)
+	}
+	return hkdf(params.Hash, K, transcript, info, length)
+}
@@ -0,0 +1,100 @@
+package srp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportKeyingMaterial(t *testing.T) {
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ExportKeyingMaterial("token", nil, 32); err != ErrClientNotReady {
+		t.Fatalf("expected ErrClientNotReady, got %v", err)
+	}
+	if err := server.SetA(client.A()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.ExportKeyingMaterial("token", nil, 32); err == nil {
+		t.Fatal("expected an error before the client proof is verified")
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	export := func(label string, context []byte, length int) []byte {
+		t.Helper()
+
+		c, err := client.ExportKeyingMaterial(label, context, length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := server.ExportKeyingMaterial(label, context, length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, "keying material", c, s)
+		if len(c) != length {
+			t.Fatalf("expected %d bytes, got %d", length, len(c))
+		}
+		return c
+	}
+
+	var (
+		token    = export("token", nil, 32)
+		channel  = export("channel", nil, 32)
+		empty    = export("token", []byte{}, 32)
+		context  = export("token", []byte("context"), 32)
+		long     = export("token", nil, 100)
+		key, _   = server.SessionKey()
+		distinct = [][]byte{token, channel, empty, context, key}
+	)
+	for i := range distinct {
+		for j := i + 1; j < len(distinct); j++ {
+			if bytes.Equal(distinct[i], distinct[j]) {
+				t.Fatalf("expected values %d and %d to differ", i, j)
+			}
+		}
+	}
+	assertEqualBytes(t, "prefix", token, long[:32])
+
+	if _, err := client.ExportKeyingMaterial("token", nil, 0); err == nil {
+		t.Fatal("expected an error for a zero length")
+	}
+	if _, err := client.ExportKeyingMaterial("token", nil, 256*params.Hash.Size()); err == nil {
+		t.Fatal("expected an error for an excessive length")
+	}
+}
+
+func TestExportKeyingMaterialTranscript(t *testing.T) {
+	// Two handshakes with the same credentials export
+	// different keying material.
+	var exported [][]byte
+	for i := 0; i < 2; i++ {
+		client, err := NewClient(params, string(I), string(P), salt.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := handshake(client, server); err != nil {
+			t.Fatal(err)
+		}
+		ekm, err := client.ExportKeyingMaterial("token", nil, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exported = append(exported, ekm)
+	}
+	if bytes.Equal(exported[0], exported[1]) {
+		t.Fatal("expected the keying material to depend on the handshake")
+	}
+}
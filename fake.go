@@ -0,0 +1,110 @@
+package srp
+
+import (
+	"crypto"
+	"errors"
+	"math/big"
+
+	_ "crypto/sha256"
+)
+
+// Minimum length of the secret of a fake server.
+const minFakeSecretSize = 32
+
+// HKDF info labels of the values simulated by
+// a fake server.
+const (
+	fakeSaltInfo     = "srp fake salt"
+	fakeVerifierInfo = "srp fake verifier"
+)
+
+// NewFakeServer returns a server that simulates a handshake
+// with a user that does not exist, to prevent attackers from
+// enumerating accounts.
+//
+// The salt and the verifier are derived from username with
+// secret, a random value of at least 32 bytes that must be
+// kept private and stable: the same username always gets the
+// same salt, as a real user would, and attackers cannot tell
+// the salt from a real one. Use [FakeSalt] to obtain the salt
+// to send to the client.
+//
+// Fake salts are [Params.SaltLength] bytes long, unless opts
+// contain [WithFakeSaltLength]. Their length must match that
+// of the salts of real users, or attackers can tell unknown
+// users apart: when real salts have another length (e.g. they
+// were created with [NewSalt] before the default length of the
+// params changed), set it with [WithFakeSaltLength].
+//
+// The server computes B and the proofs the same way a real
+// server does, but [Server.CheckM1] always rejects the client
+// proof.
+func NewFakeServer(params *Params, username string, secret []byte, opts ...Option) (*Server, error) {
+	salt, verifier, err := fakeCredentials(params, username, secret, newOptions(opts).fakeSaltLen)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := NewServer(params, username, salt, verifier, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.fake = true
+	return s, nil
+}
+
+// FakeSalt returns the salt a server created with
+// [NewFakeServer] and the same opts uses for username.
+func FakeSalt(params *Params, username string, secret []byte, opts ...Option) ([]byte, error) {
+	salt, _, err := fakeCredentials(params, username, secret, newOptions(opts).fakeSaltLen)
+	return salt, err
+}
+
+// WithFakeSaltLength sets the length of the salts of fake
+// servers to n bytes, instead of [Params.SaltLength] (see
+// [NewFakeServer]).
+//
+// This option only has an effect on fake servers and
+// on [FakeSalt].
+func WithFakeSaltLength(n int) Option {
+	return func(o *options) {
+		o.fakeSaltLen = n
+	}
+}
+
+// fakeCredentials derives the salt and the verifier of a user
+// that does not exist. The salt is saltLen bytes long, or
+// [Params.SaltLength] if saltLen is zero.
+func fakeCredentials(params *Params, username string, secret []byte, saltLen int) (salt, verifier []byte, err error) {
+	if err := params.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if len(secret) < minFakeSecretSize {
+		return nil, nil, errors.New("secret of a fake server must be at least 32 bytes long")
+	}
+	if saltLen < 0 {
+		return nil, nil, errors.New("salt length cannot be negative")
+	}
+	if saltLen == 0 {
+		saltLen = params.SaltLength()
+	}
+
+	if username, err = params.normalizeUsername(username); err != nil {
+		return nil, nil, err
+	}
+	salt, err = hkdf(crypto.SHA256, secret, []byte(username), []byte(fakeSaltInfo), saltLen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reducing a value twice as long as N modulo N
+	// yields a verifier with a negligible bias.
+	size := 2 * ((params.Group.N.BitLen() + 7) / 8)
+	b, err := hkdf(crypto.SHA256, secret, []byte(username), []byte(fakeVerifierInfo), size)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(big.Int).SetBytes(b)
+	v.Mod(v, params.Group.N)
+	return salt, v.Bytes(), nil
+}
@@ -0,0 +1,163 @@
+package srp
+
+import (
+	"bytes"
+	"testing"
+)
+
+var fakeSecret = bytes.Repeat([]byte{0x42}, 32)
+
+func TestFakeSalt(t *testing.T) {
+	s1, err := FakeSalt(params, "mallory", fakeSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := FakeSalt(params, "mallory", fakeSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "salt", s1, s2)
+	if len(s1) != SaltLength {
+		t.Fatalf("expected a %d-byte salt, got %d bytes", SaltLength, len(s1))
+	}
+
+	long, err := FakeSalt(AppleProfile, "mallory", fakeSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(long) != AppleProfile.SaltLength() {
+		t.Fatalf("expected a %d-byte salt, got %d bytes", AppleProfile.SaltLength(), len(long))
+	}
+
+	other, err := FakeSalt(params, "trudy", fakeSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(s1, other) {
+		t.Fatal("expected different usernames to get different salts")
+	}
+
+	other, err = FakeSalt(params, "mallory", bytes.Repeat([]byte{0x43}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(s1, other) {
+		t.Fatal("expected different secrets to produce different salts")
+	}
+
+	if _, err := FakeSalt(params, "mallory", []byte("short")); err == nil {
+		t.Fatal("expected a short secret to be rejected")
+	}
+}
+
+func TestFakeServer(t *testing.T) {
+	salt, err := FakeSalt(params, "mallory", fakeSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewFakeServer(params, "mallory", fakeSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "salt", salt, server.triplet.Salt())
+	if _, err := NewPublicKey(params, server.B()); err != nil {
+		t.Fatalf("expected a valid B: %v", err)
+	}
+
+	client, err := NewClient(params, "mallory", "password123", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(client.A()); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetB(server.B()); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := server.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreServer(params, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []*Server{server, restored} {
+		M1, err := client.ComputeM1()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := s.CheckM1(M1); err != nil || ok {
+			t.Fatalf("expected the client proof to be rejected, got %v, %v", ok, err)
+		}
+		if _, err := s.ComputeM2(); err == nil {
+			t.Fatal("expected M2 to be unavailable")
+		}
+	}
+}
+
+// TestFakeServerRejectsMatchingProof checks that a fake server
+// rejects a client proof even if it is valid for its verifier.
+func TestFakeServerRejectsMatchingProof(t *testing.T) {
+	server, err := NewFakeServer(params, "mallory", fakeSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(params, "mallory", "password123", server.triplet.Salt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(client.A()); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := server.CheckM1(server.m1.Bytes()); err != nil || ok {
+		t.Fatalf("expected the client proof to be rejected, got %v, %v", ok, err)
+	}
+}
+
+// TestFakeSaltLength checks that fake servers can mimic real
+// users whose salts are shorter than the recommended length of
+// their params.
+func TestFakeSaltLength(t *testing.T) {
+	p := AppleProfile
+	if p.SaltLength() != RecommendedSaltLength {
+		t.Fatalf("expected a %d-byte recommended salt, got %d bytes", RecommendedSaltLength, p.SaltLength())
+	}
+
+	// A user enrolled with the legacy default length.
+	enrolled, err := ComputeVerifier(p, "alice", "password123", NewSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake, err := FakeSalt(p, "mallory", fakeSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake) == len(enrolled.Salt()) {
+		t.Fatal("expected the default fake salt to differ in length from the legacy salt")
+	}
+
+	opt := WithFakeSaltLength(len(enrolled.Salt()))
+	fake, err = FakeSalt(p, "mallory", fakeSecret, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake) != len(enrolled.Salt()) {
+		t.Fatalf("expected a %d-byte salt, got %d bytes", len(enrolled.Salt()), len(fake))
+	}
+
+	server, err := NewFakeServer(p, "mallory", fakeSecret, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "salt", fake, server.triplet.Salt())
+
+	if _, err := FakeSalt(p, "mallory", fakeSecret, WithFakeSaltLength(-1)); err == nil {
+		t.Fatal("expected a negative length to be rejected")
+	}
+}
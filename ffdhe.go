@@ -0,0 +1,84 @@
+package srp
+
+import (
+	"math/big"
+
+	_ "embed" // Embedding RFC7919 DH groups
+)
+
+var (
+	//go:embed groups/ffdhe2048.txt
+	hexFFDHE2048 string
+
+	//go:embed groups/ffdhe3072.txt
+	hexFFDHE3072 string
+
+	//go:embed groups/ffdhe4096.txt
+	hexFFDHE4096 string
+
+	//go:embed groups/ffdhe6144.txt
+	hexFFDHE6144 string
+
+	//go:embed groups/ffdhe8192.txt
+	hexFFDHE8192 string
+)
+
+// Finite field Diffie-Hellman groups ffdhe2048, ffdhe3072,
+// ffdhe4096, ffdhe6144 and ffdhe8192 defined in [RFC7919].
+//
+// The exponent sizes follow the recommendations of
+// [RFC7919], section 5.2.
+//
+// [RFC7919]: https://datatracker.ietf.org/doc/html/rfc7919
+var (
+	FFDHE2048 = &Group{
+		ID:           "ffdhe2048",
+		Generator:    big.NewInt(2),
+		N:            mustParseHex(hexFFDHE2048),
+		ExponentSize: 29, // 225 bits
+	}
+
+	FFDHE3072 = &Group{
+		ID:           "ffdhe3072",
+		Generator:    big.NewInt(2),
+		N:            mustParseHex(hexFFDHE3072),
+		ExponentSize: 35, // 275 bits
+	}
+
+	FFDHE4096 = &Group{
+		ID:           "ffdhe4096",
+		Generator:    big.NewInt(2),
+		N:            mustParseHex(hexFFDHE4096),
+		ExponentSize: 41, // 325 bits
+	}
+
+	FFDHE6144 = &Group{
+		ID:           "ffdhe6144",
+		Generator:    big.NewInt(2),
+		N:            mustParseHex(hexFFDHE6144),
+		ExponentSize: 47, // 375 bits
+	}
+
+	FFDHE8192 = &Group{
+		ID:           "ffdhe8192",
+		Generator:    big.NewInt(2),
+		N:            mustParseHex(hexFFDHE8192),
+		ExponentSize: 50, // 400 bits
+	}
+)
+
+// groups lists the groups predefined by this package.
+var groups = []*Group{
+	RFC5054Group1024,
+	RFC5054Group1536,
+	RFC5054Group2048,
+	RFC5054Group3072,
+	RFC5054Group4096,
+	RFC5054Group6144,
+	RFC5054Group8192,
+	FFDHE2048,
+	FFDHE3072,
+	FFDHE4096,
+	FFDHE6144,
+	FFDHE8192,
+}
@@ -0,0 +1,124 @@
+package srp
+
+import (
+	"crypto"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// Leading and trailing words of each prime, imported
+// from RFC 7919 – Appendix A.
+// https://datatracker.ietf.org/doc/html/rfc7919#appendix-A
+var ffdheVectors = []struct {
+	group    *Group
+	bits     int
+	prefix   string
+	suffix   string
+	expBytes int
+}{
+	{FFDHE2048, 2048, "FFFFFFFFFFFFFFFFADF85458A2BB4A9A", "886B423861285C97FFFFFFFFFFFFFFFF", 29},
+	{FFDHE3072, 3072, "FFFFFFFFFFFFFFFFADF85458A2BB4A9A", "25E41D2B66C62E37FFFFFFFFFFFFFFFF", 35},
+	{FFDHE4096, 4096, "FFFFFFFFFFFFFFFFADF85458A2BB4A9A", "C68A007E5E655F6AFFFFFFFFFFFFFFFF", 41},
+	{FFDHE6144, 6144, "FFFFFFFFFFFFFFFFADF85458A2BB4A9A", "A40E329CD0E40E65FFFFFFFFFFFFFFFF", 47},
+	{FFDHE8192, 8192, "FFFFFFFFFFFFFFFFADF85458A2BB4A9A", "D68C8BB7C5C6424CFFFFFFFFFFFFFFFF", 50},
+}
+
+func TestFFDHEGroups(t *testing.T) {
+	for _, tt := range ffdheVectors {
+		t.Run(tt.group.ID, func(t *testing.T) {
+			if got := tt.group.N.BitLen(); got != tt.bits {
+				t.Fatalf("wanted a %d-bit prime, got %d bits", tt.bits, got)
+			}
+
+			h := fmt.Sprintf("%X", tt.group.N)
+			if !strings.HasPrefix(h, tt.prefix) || !strings.HasSuffix(h, tt.suffix) {
+				t.Fatalf("N does not match RFC 7919: %s...%s", h[:32], h[len(h)-32:])
+			}
+
+			// p = 2^b - 2^{b-64} + {[2^{b-130} e] + X} * 2^64 - 1
+			// implies that p ≡ -1 (mod 2^64).
+			low := new(big.Int).And(tt.group.N, new(big.Int).SetUint64(^uint64(0)))
+			if low.Uint64() != ^uint64(0) {
+				t.Fatal("the 64 least significant bits of N must be set")
+			}
+
+			if tt.group.Generator.Cmp(big.NewInt(2)) != 0 {
+				t.Fatalf("wanted generator 2, got %v", tt.group.Generator)
+			}
+			if tt.group.ExponentSize != tt.expBytes {
+				t.Fatalf("wanted an exponent size of %d bytes, got %d", tt.expBytes, tt.group.ExponentSize)
+			}
+		})
+	}
+}
+
+func TestGroupValidate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping primality checks in short mode")
+	}
+
+	for _, g := range groups {
+		g := g
+		t.Run(g.ID, func(t *testing.T) {
+			t.Parallel()
+			if err := g.Validate(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestGroupValidateInvalid(t *testing.T) {
+	composite := new(big.Int).Add(RFC5054Group2048.N, big.NewInt(2))
+	notSafe := new(big.Int).Lsh(bigOne, 1279)
+	notSafe.Sub(notSafe, bigOne) // Mersenne prime; (N-1)/2 is even
+
+	tests := []struct {
+		name  string
+		group *Group
+	}{
+		{"Empty", &Group{}},
+		{"TooShort", &Group{Generator: big.NewInt(2), N: big.NewInt(23), ExponentSize: 1}},
+		{"Composite", &Group{Generator: big.NewInt(2), N: composite, ExponentSize: 32}},
+		{"NotSafe", &Group{Generator: big.NewInt(2), N: notSafe, ExponentSize: 32}},
+		{"GeneratorOne", &Group{Generator: big.NewInt(1), N: FFDHE2048.N, ExponentSize: 32}},
+		{"GeneratorMinusOne", &Group{Generator: new(big.Int).Sub(FFDHE2048.N, bigOne), N: FFDHE2048.N, ExponentSize: 32}},
+		{"NoExponent", &Group{Generator: big.NewInt(2), N: FFDHE2048.N}},
+		{"ExponentTooLong", &Group{Generator: big.NewInt(2), N: FFDHE2048.N, ExponentSize: 256}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.group.Validate(); err == nil {
+				t.Fatal("expected group to be rejected")
+			}
+		})
+	}
+}
+
+func TestFFDHEHandshake(t *testing.T) {
+	params := &Params{
+		Name:  "ffdhe2048-sha256",
+		Group: FFDHE2048,
+		Hash:  crypto.SHA256,
+		KDF:   RFC5054KDF,
+	}
+
+	triplet, err := ComputeVerifier(params, "alice", "password123", NewSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(params, "alice", "password123", triplet.Salt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, triplet.Username(), triplet.Salt(), triplet.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,110 @@
+package srp
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestFixedBaseTable(t *testing.T) {
+	group := RFC5054Group2048
+	table := newFixedBaseTable(group, 256)
+
+	exps := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(16),
+		a,
+		b,
+		new(big.Int).Sub(new(big.Int).Lsh(bigOne, 256), bigOne),
+	}
+	for i := 0; i < 10; i++ {
+		e, err := rand.Int(rand.Reader, new(big.Int).Lsh(bigOne, 256))
+		if err != nil {
+			t.Fatal(err)
+		}
+		exps = append(exps, e)
+	}
+
+	for _, e := range exps {
+		wanted := new(big.Int).Exp(group.Generator, e, group.N)
+		if got := table.exp(e); got == nil || got.Cmp(wanted) != 0 {
+			t.Fatalf("g^%x: wanted %x, got %x", e, wanted, got)
+		}
+	}
+
+	if table.exp(new(big.Int).Lsh(bigOne, 256)) != nil {
+		t.Fatal("expected a long exponent to be rejected")
+	}
+	if table.exp(big.NewInt(-1)) != nil {
+		t.Fatal("expected a negative exponent to be rejected")
+	}
+}
+
+func TestPrecompute(t *testing.T) {
+	p := *params
+	p.Precompute()
+	if p.table == nil {
+		t.Fatal("expected a table to be built")
+	}
+
+	client, err := NewClient(&p, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(&p, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	// A long exponent falls back to big.Int.Exp.
+	e := new(big.Int).Lsh(bigOne, 1000)
+	wanted := new(big.Int).Exp(p.Group.Generator, e, p.Group.N)
+	if got := p.expGenerator(e); got.Cmp(wanted) != 0 {
+		t.Fatalf("wanted %x, got %x", wanted, got)
+	}
+
+	// The table is ignored once the group is replaced.
+	p.Group = RFC5054Group2048
+	wanted = new(big.Int).Exp(p.Group.Generator, a, p.Group.N)
+	if got := p.expGenerator(a); got.Cmp(wanted) != 0 {
+		t.Fatalf("wanted %x, got %x", wanted, got)
+	}
+}
+
+func benchmarkKeyPair(bench *testing.B, group *Group, precompute bool) {
+	p := &Params{Group: group, Hash: params.Hash, KDF: params.KDF}
+	if precompute {
+		p.Precompute()
+	}
+
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		if _, _, err := newClientKeyPair(p, nil); err != nil {
+			bench.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkKeyPair(bench *testing.B) {
+	groups := []struct {
+		name  string
+		group *Group
+	}{
+		{"2048", RFC5054Group2048},
+		{"4096", RFC5054Group4096},
+		{"6144", RFC5054Group6144},
+		{"8192", RFC5054Group8192},
+	}
+	for _, g := range groups {
+		bench.Run(g.name, func(bench *testing.B) {
+			benchmarkKeyPair(bench, g.group, false)
+		})
+		bench.Run(g.name+"/Precomputed", func(bench *testing.B) {
+			benchmarkKeyPair(bench, g.group, true)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package srp
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// GenerateGroup generates a new group whose modulus is a
+// safe prime N of the given length in bits, read from rand.
+// If rand is nil, [crypto/rand.Reader] is used.
+//
+// N is chosen such that N ≡ 7 (mod 8), which makes 2 a
+// generator of the subgroup of prime order (N-1)/2. The
+// exponent size follows the recommendations of RFC 7919,
+// and the group is identified as "dh<bits>-<fingerprint>".
+//
+// Generating a safe prime is slow: expect tens of seconds for 2048
+// bits, and minutes for larger groups. Groups should therefore
+// be generated once and stored, rather than generated on
+// startup.
+func GenerateGroup(bits int, rand io.Reader) (*Group, error) {
+	if bits < minGroupBits {
+		return nil, fmt.Errorf("N must be at least %d bits long", minGroupBits)
+	}
+	if rand == nil {
+		rand = crand.Reader
+	}
+
+	N, err := safePrime(rand, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Group{
+		Generator:    big.NewInt(2),
+		N:            N,
+		ExponentSize: exponentSize(bits),
+	}
+	g.ID = groupFingerprint(g)
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// sievePrimes are the small odd primes used to discard
+// candidates cheaply before testing their primality.
+var sievePrimes = oddPrimesBelow(1 << 14)
+
+// maxSieveDelta bounds the search for a safe prime
+// starting from a random candidate.
+const maxSieveDelta = 1 << 20
+
+// safePrime returns a safe prime N = 2q + 1 of the given
+// length in bits, such that N ≡ 7 (mod 8).
+//
+// Starting from a random q ≡ 3 (mod 4), candidates are
+// searched incrementally, skipping those for which q or N
+// is divisible by one of the sieve primes.
+func safePrime(rand io.Reader, bits int) (*big.Int, error) {
+	var (
+		b    = make([]byte, (bits-1+7)/8)
+		mods = make([]uint64, len(sievePrimes))
+		q    = new(big.Int)
+		N    = new(big.Int)
+	)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+
+		// q has exactly bits-1 bits, the two most significant of
+		// which are set so that N = 2q + 1 keeps bits bits when
+		// q is incremented, and q ≡ 3 (mod 4).
+		q.SetBytes(b)
+		q.SetBit(q, bits-2, 1)
+		q.SetBit(q, bits-3, 1)
+		for i := bits - 1; i < len(b)*8; i++ {
+			q.SetBit(q, i, 0)
+		}
+		q.SetBit(q, 0, 1)
+		q.SetBit(q, 1, 1)
+
+		for i, p := range sievePrimes {
+			mods[i] = new(big.Int).Mod(q, new(big.Int).SetUint64(p)).Uint64()
+		}
+
+	search:
+		for delta := uint64(0); delta < maxSieveDelta; delta += 4 {
+			for i, p := range sievePrimes {
+				// q ≡ 0 (mod p) or N = 2q + 1 ≡ 0 (mod p)
+				if r := (mods[i] + delta) % p; r == 0 || r == (p-1)/2 {
+					continue search
+				}
+			}
+
+			candidate := new(big.Int).Add(q, new(big.Int).SetUint64(delta))
+			if candidate.BitLen() != bits-1 {
+				break
+			}
+			if !candidate.ProbablyPrime(0) {
+				continue
+			}
+			N.Lsh(candidate, 1)
+			N.Add(N, bigOne)
+			if N.ProbablyPrime(primalityRounds) && candidate.ProbablyPrime(primalityRounds) {
+				return N, nil
+			}
+		}
+	}
+}
+
+// oddPrimesBelow returns the odd primes smaller than n.
+func oddPrimesBelow(n int) []uint64 {
+	composite := make([]bool, n)
+	var primes []uint64
+	for i := 3; i < n; i += 2 {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, uint64(i))
+		for j := i * i; j < n; j += 2 * i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
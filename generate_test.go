@@ -0,0 +1,67 @@
+package srp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGroup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping safe prime generation in short mode")
+	}
+
+	g, err := GenerateGroup(1024, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.N.BitLen() != 1024 {
+		t.Fatalf("expected a 1024-bit modulus, got %d bits", g.N.BitLen())
+	}
+	if r := new(big.Int).Mod(g.N, big.NewInt(8)); r.Int64() != 7 {
+		t.Fatalf("expected N ≡ 7 (mod 8), got %v", r)
+	}
+	if !strings.HasPrefix(g.ID, "dh1024-") {
+		t.Fatalf("unexpected group ID %q", g.ID)
+	}
+
+	// 2 generates the subgroup of order q = (N-1)/2.
+	q := new(big.Int).Rsh(g.N, 1)
+	if new(big.Int).Exp(g.Generator, q, g.N).Cmp(bigOne) != 0 {
+		t.Fatal("expected the generator to be of order (N-1)/2")
+	}
+
+	params := &Params{
+		Name:  "generated",
+		Group: g,
+		Hash:  crypto.SHA256,
+		KDF:   RFC5054KDF,
+	}
+	triplet, err := ComputeVerifier(params, "alice", "password123", NewSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(params, "alice", "password123", triplet.Salt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, triplet.Username(), triplet.Salt(), triplet.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateGroupInvalid(t *testing.T) {
+	if _, err := GenerateGroup(512, nil); err == nil {
+		t.Fatal("expected a short modulus to be rejected")
+	}
+	if _, err := GenerateGroup(1024, bytes.NewReader(nil)); err == nil {
+		t.Fatal("expected an exhausted reader to fail")
+	}
+}
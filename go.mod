@@ -2,4 +2,7 @@ module code.posterity.life/srp/v2
 
 go 1.20
 
-require golang.org/x/text v0.5.0
+require (
+	filippo.io/nistec v0.0.3
+	golang.org/x/text v0.5.0
+)
@@ -0,0 +1,187 @@
+package srp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ClientHandshake authenticates the user with a server
+// running [ServerHandshake] on the other end of rw, and
+// returns the session key.
+//
+// The messages are exchanged with [WriteMessage] and
+// [ReadMessage], in the following order:
+//
+//	Client → Server: I
+//	Server → Client: s, [KDF params,] B
+//	Client → Server: A, M1
+//	Server → Client: M2
+//
+// The server sends the [KDFParams] of the user only if it was
+// configured with [WithKDFParams]. The client then binds them
+// to its proof, so that params altered in transit fail the
+// handshake; if opts contain [WithKDFParams] too, the params
+// received must be equal to them.
+//
+// An error is returned if the server fails to prove it knows
+// the verifier of the user. The optional opts must match those
+// used by the server.
+func ClientHandshake(rw io.ReadWriter, params *Params, username, password string, opts ...Option) (SessionKey, error) {
+	if err := WriteMessage(rw, Message{Type: MessageUsername, Payload: []byte(username)}); err != nil {
+		return nil, err
+	}
+
+	salt, err := expectMessage(rw, MessageSalt)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ReadMessage(rw)
+	if err != nil {
+		return nil, err
+	}
+	if m.Type == MessageKDFParams {
+		var p KDFParams
+		if err := p.UnmarshalBinary(m.Payload); err != nil {
+			return nil, err
+		}
+		if expected := newOptions(opts).kdfParams; expected != nil && *expected != p {
+			return nil, errors.New("unexpected KDF params")
+		}
+		opts = append(append([]Option{}, opts...), WithKDFParams(p))
+
+		if m, err = ReadMessage(rw); err != nil {
+			return nil, err
+		}
+	}
+	if m.Type != MessageB {
+		return nil, fmt.Errorf("expected message %s, got %s", MessageB, m.Type)
+	}
+	B := m.Payload
+
+	client, err := NewClient(params, username, password, salt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SetB(B); err != nil {
+		return nil, err
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := WriteMessage(rw, Message{Type: MessageA, Payload: client.A()}); err != nil {
+		return nil, err
+	}
+	if err := WriteMessage(rw, Message{Type: MessageM1, Payload: M1}); err != nil {
+		return nil, err
+	}
+
+	M2, err := expectMessage(rw, MessageM2)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := client.CheckM2(M2); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.New("failed to verify server proof M2")
+	}
+
+	return client.SessionKey()
+}
+
+// ServerHandshake authenticates the user of triplet with a
+// client running [ClientHandshake] on the other end of rw,
+// and returns the session key.
+//
+// If opts contain [WithKDFParams], the KDF params are sent to
+// the client after the salt.
+//
+// An error is returned if the client sends another username
+// than that of triplet, once both are normalized, or fails to
+// prove it knows the password of the user. In that case, M2 is
+// not sent to the client, and rw should be closed. The optional
+// opts must match those used by the client.
+func ServerHandshake(rw io.ReadWriter, params *Params, triplet Triplet, opts ...Option) (SessionKey, error) {
+	if err := triplet.validate(); err != nil {
+		return nil, err
+	}
+
+	username, err := expectMessage(rw, MessageUsername)
+	if err != nil {
+		return nil, err
+	}
+	given, err := params.normalizeUsername(string(username))
+	if err != nil {
+		return nil, err
+	}
+	expected, err := params.normalizeUsername(triplet.Username())
+	if err != nil {
+		return nil, err
+	}
+	if given != expected {
+		return nil, fmt.Errorf("unexpected username %q", username)
+	}
+
+	server, err := NewServerFromTriplet(params, triplet, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := WriteMessage(rw, Message{Type: MessageSalt, Payload: triplet.Salt()}); err != nil {
+		return nil, err
+	}
+	if p := server.opts.kdfParams; p != nil {
+		payload, err := p.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if err := WriteMessage(rw, Message{Type: MessageKDFParams, Payload: payload}); err != nil {
+			return nil, err
+		}
+	}
+	if err := WriteMessage(rw, Message{Type: MessageB, Payload: server.B()}); err != nil {
+		return nil, err
+	}
+
+	A, err := expectMessage(rw, MessageA)
+	if err != nil {
+		return nil, err
+	}
+	if err := server.SetA(A); err != nil {
+		return nil, err
+	}
+	M1, err := expectMessage(rw, MessageM1)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := server.CheckM1(M1); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.New("failed to verify client proof M1")
+	}
+
+	M2, err := server.ComputeM2()
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteMessage(rw, Message{Type: MessageM2, Payload: M2}); err != nil {
+		return nil, err
+	}
+
+	return server.SessionKey()
+}
+
+// expectMessage reads the next message from r, and returns
+// its payload, or an error if it is not of type t.
+func expectMessage(r io.Reader, t MessageType) ([]byte, error) {
+	m, err := ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	if m.Type != t {
+		return nil, fmt.Errorf("expected message %s, got %s", t, m.Type)
+	}
+	return m.Payload, nil
+}
@@ -0,0 +1,142 @@
+package srp
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+// runHandshakes runs ClientHandshake and ServerHandshake
+// over a pipe, and returns their results.
+func runHandshakes(t *testing.T, password string, triplet Triplet) (clientKey, serverKey SessionKey, clientErr, serverErr error) {
+	t.Helper()
+	return runHandshakesWith(t, params, string(I), password, triplet, nil, nil)
+}
+
+// runHandshakesWith runs ClientHandshake and ServerHandshake
+// with their own options over a pipe, and returns their results.
+func runHandshakesWith(t *testing.T, params *Params, username, password string, triplet Triplet, clientOpts, serverOpts []Option) (clientKey, serverKey SessionKey, clientErr, serverErr error) {
+	t.Helper()
+
+	c, s := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer s.Close()
+		serverKey, serverErr = ServerHandshake(s, params, triplet, serverOpts...)
+	}()
+
+	clientKey, clientErr = ClientHandshake(c, params, username, password, clientOpts...)
+	c.Close()
+	<-done
+	return
+}
+
+func TestHandshakeDriver(t *testing.T) {
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	clientKey, serverKey, clientErr, serverErr := runHandshakes(t, string(P), tp)
+	if clientErr != nil {
+		t.Fatal(clientErr)
+	}
+	if serverErr != nil {
+		t.Fatal(serverErr)
+	}
+	assertEqualBytes(t, "session key", clientKey, serverKey)
+}
+
+func TestHandshakeDriverWrongPassword(t *testing.T) {
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	_, _, clientErr, serverErr := runHandshakes(t, "password124", tp)
+	if serverErr == nil {
+		t.Fatal("expected the server to reject the client")
+	}
+	if clientErr == nil {
+		t.Fatal("expected the client to fail")
+	}
+}
+
+func TestHandshakeDriverWrongUsername(t *testing.T) {
+	tp := NewTriplet("bob", salt.Bytes(), v.Bytes())
+
+	_, _, clientErr, serverErr := runHandshakes(t, string(P), tp)
+	if serverErr == nil {
+		t.Fatal("expected the server to reject the username")
+	}
+	if clientErr == nil {
+		t.Fatal("expected the client to fail")
+	}
+}
+
+func TestHandshakeDriverUnexpectedMessage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMessage(&buf, Message{Type: MessageA, Payload: A.Bytes()}); err != nil {
+		t.Fatal(err)
+	}
+
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+	if _, err := ServerHandshake(&buf, params, tp); err == nil {
+		t.Fatal("expected an unexpected message to be rejected")
+	}
+}
+
+func TestHandshakeDriverNormalizedUsername(t *testing.T) {
+	// "é" is stored decomposed, and sent composed.
+	tp, err := ComputeVerifier(precisParams, "e\u0301", string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientKey, serverKey, clientErr, serverErr := runHandshakesWith(t, precisParams, "\u00e9", string(P), tp, nil, nil)
+	if clientErr != nil {
+		t.Fatal(clientErr)
+	}
+	if serverErr != nil {
+		t.Fatal(serverErr)
+	}
+	assertEqualBytes(t, "session key", clientKey, serverKey)
+}
+
+func TestHandshakeDriverKDFParams(t *testing.T) {
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+	kdf := KDFParams{Algorithm: "argon2id", Iterations: 3, Memory: 64 * 1024, Parallelism: 4, KeyLen: 32}
+
+	t.Run("Sent", func(t *testing.T) {
+		clientKey, serverKey, clientErr, serverErr := runHandshakesWith(t, params, string(I), string(P), tp, nil, []Option{WithKDFParams(kdf)})
+		if clientErr != nil {
+			t.Fatal(clientErr)
+		}
+		if serverErr != nil {
+			t.Fatal(serverErr)
+		}
+		assertEqualBytes(t, "session key", clientKey, serverKey)
+	})
+
+	t.Run("Expected", func(t *testing.T) {
+		opts := []Option{WithKDFParams(kdf)}
+		_, _, clientErr, serverErr := runHandshakesWith(t, params, string(I), string(P), tp, opts, opts)
+		if clientErr != nil {
+			t.Fatal(clientErr)
+		}
+		if serverErr != nil {
+			t.Fatal(serverErr)
+		}
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		weak := kdf
+		weak.Iterations = 1
+		_, _, clientErr, _ := runHandshakesWith(t, params, string(I), string(P), tp, []Option{WithKDFParams(kdf)}, []Option{WithKDFParams(weak)})
+		if clientErr == nil {
+			t.Fatal("expected the client to reject unexpected KDF params")
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, _, clientErr, serverErr := runHandshakesWith(t, params, string(I), string(P), tp, []Option{WithKDFParams(kdf)}, nil)
+		if clientErr == nil || serverErr == nil {
+			t.Fatalf("expected the handshake to fail, got %v, %v", clientErr, serverErr)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package srp
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// blindingBits is the length of the random factors
+// used to blind secret exponents.
+const blindingBits = 64
+
+// WithHardening enables the hardened mode, in which the
+// modular exponentiations involving long-lived secrets
+// (a, b and x) are protected against timing side channels.
+//
+// The exponentiations of [math/big] are not constant time:
+// their duration depends on the length of the exponent and,
+// to a lesser extent, on its bits. In hardened mode, each
+// secret exponent e is replaced by
+//
+//	e' = e + r * (N - 1)
+//
+// where r is a fresh random value whose most significant bit
+// is set. Since N is prime, the result is unchanged, but the
+// exponent actually processed is different for every
+// handshake, and its length no longer depends on e.
+//
+// Blinded exponents are as long as N, whereas private keys
+// are much shorter: with a 2048-bit group, the hardened mode
+// makes computing the premaster secret (S) about 5 times
+// slower on the server, and 8 times slower on the client
+// (see the benchmarks). It does not affect the proofs, and
+// does not need to match on both sides.
+func WithHardening() Option {
+	return func(o *options) {
+		o.hardened = true
+	}
+}
+
+// expMod returns base^exp % N, where N is the prime
+// modulus of params.
+//
+// If hardened is true, exp is blinded and padded to a
+// constant length first (see [WithHardening]).
+func expMod(params *Params, base, exp *big.Int, hardened bool) (*big.Int, error) {
+	N := params.Group.N
+	if !hardened || exp.Sign() <= 0 {
+		return new(big.Int).Exp(base, exp, N), nil
+	}
+
+	r, err := blindingFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	// x^(N-1) = 1 (mod N) for any x coprime with N, and the
+	// only other possible base is 0, whose positive powers are
+	// all 0.
+	order := new(big.Int).Sub(N, bigOne)
+	blinded := r.Mul(r, order)
+	blinded.Add(blinded, new(big.Int).Mod(exp, order))
+
+	// Reduce the base as well, so that the operands of the
+	// exponentiation always have the length of N.
+	reduced := new(big.Int).Mod(base, N)
+	return reduced.Exp(reduced, blinded, N), nil
+}
+
+// blindingFactor returns a random integer of exactly
+// blindingBits bits.
+func blindingFactor() (*big.Int, error) {
+	b := make([]byte, blindingBits/8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to get random bytes: %w", err)
+	}
+	b[0] |= 0x80
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,135 @@
+package srp
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestExpMod(t *testing.T) {
+	N := params.Group.N
+	bases := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(2),
+		A,
+		new(big.Int).Sub(N, bigOne),
+		new(big.Int).Add(N, big.NewInt(5)),
+		new(big.Int).Neg(B),
+	}
+	exps := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		a,
+		new(big.Int).Sub(N, bigOne),
+		new(big.Int).Lsh(N, 3),
+	}
+
+	for _, base := range bases {
+		for _, exp := range exps {
+			wanted := new(big.Int).Exp(base, exp, N)
+			got, err := expMod(params, base, exp, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Cmp(wanted) != 0 {
+				t.Fatalf("%x^%x: wanted %x, got %x", base, exp, wanted, got)
+			}
+		}
+	}
+}
+
+func TestComputeSHardened(t *testing.T) {
+	got, err := computeServerS(params, v, u, A, b, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "S (server)", S.Bytes(), got.Bytes())
+
+	got, err = computeClientS(params, k, x, u, B, a, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "S (client)", S.Bytes(), got.Bytes())
+}
+
+func TestHardening(t *testing.T) {
+	// The hardened mode does not need to match on both sides.
+	for _, opts := range [][2][]Option{
+		{{WithHardening()}, {WithHardening()}},
+		{{WithHardening()}, nil},
+		{nil, {WithHardening()}},
+	} {
+		client, err := NewClient(params, string(I), string(P), salt.Bytes(), opts[0]...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), opts[1]...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := handshake(client, server); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func benchmarkComputeServerS(bench *testing.B, hardened bool) {
+	p := &Params{Group: RFC5054Group2048, Hash: params.Hash, KDF: params.KDF}
+	bb, BB, err := newServerKeyPair(p, k, v, nil)
+	if err != nil {
+		bench.Fatal(err)
+	}
+	_, AA, err := newClientKeyPair(p, nil)
+	if err != nil {
+		bench.Fatal(err)
+	}
+	uu, err := computeLittleU(p, AA, BB)
+	if err != nil {
+		bench.Fatal(err)
+	}
+
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		if _, err := computeServerS(p, v, uu, AA, bb, hardened); err != nil {
+			bench.Fatal(err)
+		}
+	}
+}
+
+func benchmarkComputeClientS(bench *testing.B, hardened bool) {
+	p := &Params{Group: RFC5054Group2048, Hash: params.Hash, KDF: params.KDF}
+	_, BB, err := newServerKeyPair(p, k, v, nil)
+	if err != nil {
+		bench.Fatal(err)
+	}
+	aa, AA, err := newClientKeyPair(p, nil)
+	if err != nil {
+		bench.Fatal(err)
+	}
+	uu, err := computeLittleU(p, AA, BB)
+	if err != nil {
+		bench.Fatal(err)
+	}
+
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		if _, err := computeClientS(p, k, x, uu, BB, aa, hardened); err != nil {
+			bench.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkComputeServerS(bench *testing.B) {
+	benchmarkComputeServerS(bench, false)
+}
+
+func BenchmarkComputeServerSHardened(bench *testing.B) {
+	benchmarkComputeServerS(bench, true)
+}
+
+func BenchmarkComputeClientS(bench *testing.B) {
+	benchmarkComputeClientS(bench, false)
+}
+
+func BenchmarkComputeClientSHardened(bench *testing.B) {
+	benchmarkComputeClientS(bench, true)
+}
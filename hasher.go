@@ -0,0 +1,67 @@
+package srp
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+)
+
+// hasher wraps the hash function of params with a scratch
+// buffer, so that the integers and the digests hashed during
+// a handshake are encoded without allocating.
+type hasher struct {
+	hash.Hash
+	size int    // Length of N in bytes, to which integers are padded
+	buf  []byte // Scratch buffer, at least as long as N
+}
+
+// newHasher returns a new hasher for params.
+func newHasher(params *Params) *hasher {
+	size := (params.Group.N.BitLen() + 7) / 8
+	return &hasher{
+		Hash: params.Hash.New(),
+		size: size,
+		buf:  make([]byte, size),
+	}
+}
+
+// writeInt writes i as a big-endian byte array, left-padded
+// with zeros to the length of N if padded is true.
+func (h *hasher) writeInt(i *big.Int, padded bool) error {
+	n := (i.BitLen() + 7) / 8
+	if padded {
+		if n > h.size {
+			return errors.New("padding cannot be negative")
+		}
+		n = h.size
+	}
+	if n > cap(h.buf) {
+		h.buf = make([]byte, n)
+	}
+	h.Write(i.FillBytes(h.buf[:n]))
+	return nil
+}
+
+// writeHex writes the lowercase hexadecimal representation
+// of i, without leading zeros.
+func (h *hasher) writeHex(i *big.Int) {
+	h.buf = i.Append(h.buf[:0], 16)
+	h.Write(h.buf)
+}
+
+// sumInt returns the current digest as an integer,
+// and resets h.
+func (h *hasher) sumInt() *big.Int {
+	h.buf = h.Sum(h.buf[:0])
+	z := new(big.Int).SetBytes(h.buf[:h.Size()])
+	h.Reset()
+	return z
+}
+
+// sumInto returns the current digest appended to b,
+// and resets h.
+func (h *hasher) sumInto(b []byte) []byte {
+	b = h.Sum(b)
+	h.Reset()
+	return b
+}
@@ -0,0 +1,41 @@
+package srp
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"errors"
+)
+
+// hkdf derives a key of the given length from secret
+// using HKDF as defined in [RFC5869].
+//
+// [RFC5869]: https://datatracker.ietf.org/doc/html/rfc5869
+func hkdf(hash crypto.Hash, secret, salt, info []byte, length int) ([]byte, error) {
+	if length > 255*hash.Size() {
+		return nil, errors.New("hkdf: requested key length is too large")
+	}
+
+	// Extract
+	if salt == nil {
+		salt = make([]byte, hash.Size())
+	}
+	extractor := hmac.New(hash.New, salt)
+	extractor.Write(secret)
+	prk := extractor.Sum(nil)
+
+	// Expand
+	var (
+		expander = hmac.New(hash.New, prk)
+		okm      = make([]byte, 0, length+hash.Size())
+		block    []byte
+	)
+	for counter := byte(1); len(okm) < length; counter++ {
+		expander.Reset()
+		expander.Write(block)
+		expander.Write(info)
+		expander.Write([]byte{counter})
+		block = expander.Sum(nil)
+		okm = append(okm, block...)
+	}
+	return okm[:length], nil
+}
@@ -0,0 +1,55 @@
+package srp
+
+import (
+	"crypto"
+	_ "crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors imported from RFC 5869 – Appendix A
+// https://datatracker.ietf.org/doc/html/rfc5869#appendix-A
+func TestHKDF(t *testing.T) {
+	tests := []struct {
+		name   string
+		ikm    string
+		salt   string
+		info   string
+		length int
+		okm    string
+	}{
+		{
+			name:   "Case1",
+			ikm:    "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+			salt:   "000102030405060708090a0b0c",
+			info:   "f0f1f2f3f4f5f6f7f8f9",
+			length: 42,
+			okm:    "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+		},
+		{
+			name:   "Case3",
+			ikm:    "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b",
+			length: 42,
+			okm:    "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				ikm, _  = hex.DecodeString(tt.ikm)
+				salt, _ = hex.DecodeString(tt.salt)
+				info, _ = hex.DecodeString(tt.info)
+				okm, _  = hex.DecodeString(tt.okm)
+			)
+			if tt.salt == "" {
+				salt = nil
+			}
+
+			got, err := hkdf(crypto.SHA256, ikm, salt, info, tt.length)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEqualBytes(t, "okm", okm, got)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package srp
+
+import (
+	"fmt"
+	"time"
+)
+
+// Role identifies the side of a handshake.
+type Role int
+
+// Available roles.
+const (
+	RoleClient Role = iota + 1
+	RoleServer
+)
+
+// String returns the name of r.
+func (r Role) String() string {
+	switch r {
+	case RoleClient:
+		return "client"
+	case RoleServer:
+		return "server"
+	default:
+		return fmt.Sprintf("Role(%d)", int(r))
+	}
+}
+
+// HandshakeEvent describes an event of a handshake.
+type HandshakeEvent struct {
+	Role     Role
+	Params   string        // Name of the params
+	Username string        // Username of the handshake
+	Duration time.Duration // Time elapsed since the handshake started
+}
+
+// Hooks receives the events of the handshakes of clients
+// and servers configured with [WithHooks], e.g. to record
+// metrics or structured logs.
+//
+// The methods are called synchronously, while the client or
+// server is locked: they must not block, nor call methods of
+// the client or server that triggered the event.
+type Hooks interface {
+	// OnHandshakeStart is called when a client or a server
+	// is created or reset.
+	OnHandshakeStart(e HandshakeEvent)
+
+	// OnProofVerified is called when the proof of the peer
+	// is verified, which completes the handshake.
+	OnProofVerified(e HandshakeEvent)
+
+	// OnProofFailed is called when the proof of the peer
+	// is rejected.
+	OnProofFailed(e HandshakeEvent)
+
+	// OnError is called when a method of a client or a
+	// server returns err.
+	OnError(e HandshakeEvent, err error)
+}
+
+// NoHooks implements [Hooks] with methods that do nothing.
+// Embed it in a type to implement only some of the methods
+// of Hooks.
+type NoHooks struct{}
+
+// OnHandshakeStart does nothing.
+func (NoHooks) OnHandshakeStart(HandshakeEvent) {}
+
+// OnProofVerified does nothing.
+func (NoHooks) OnProofVerified(HandshakeEvent) {}
+
+// OnProofFailed does nothing.
+func (NoHooks) OnProofFailed(HandshakeEvent) {}
+
+// OnError does nothing.
+func (NoHooks) OnError(HandshakeEvent, error) {}
+
+// WithHooks configures h to receive the events of the
+// handshakes. It does not need to match on both sides.
+func WithHooks(h Hooks) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+// newHandshakeEvent returns the event of a handshake
+// started at start. The duration is zero if start is
+// unknown.
+func newHandshakeEvent(role Role, params *Params, username string, start time.Time) HandshakeEvent {
+	e := HandshakeEvent{
+		Role:     role,
+		Params:   params.Name,
+		Username: username,
+	}
+	if !start.IsZero() {
+		e.Duration = now().Sub(start)
+	}
+	return e
+}
+
+// reportStart calls the OnHandshakeStart hook of o, if any.
+func (o *options) reportStart(e HandshakeEvent) {
+	if o.hooks != nil {
+		o.hooks.OnHandshakeStart(e)
+	}
+}
+
+// reportProof calls the OnProofVerified or OnProofFailed
+// hook of o, if any.
+func (o *options) reportProof(e HandshakeEvent, verified bool) {
+	if o.hooks == nil {
+		return
+	}
+	if verified {
+		o.hooks.OnProofVerified(e)
+	} else {
+		o.hooks.OnProofFailed(e)
+	}
+}
+
+// reportError calls the OnError hook of o if err is not nil,
+// and returns err.
+func (o *options) reportError(e HandshakeEvent, err error) error {
+	if err != nil && o.hooks != nil {
+		o.hooks.OnError(e, err)
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package srp
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordedHooks records the events it receives as strings.
+type recordedHooks struct {
+	NoHooks
+	events []string
+}
+
+func (h *recordedHooks) record(name string, e HandshakeEvent) {
+	h.events = append(h.events, fmt.Sprintf("%s %s %s %s", name, e.Role, e.Username, e.Duration))
+}
+
+func (h *recordedHooks) OnHandshakeStart(e HandshakeEvent) {
+	h.record("start", e)
+}
+
+func (h *recordedHooks) OnProofVerified(e HandshakeEvent) {
+	h.record("verified", e)
+}
+
+func (h *recordedHooks) OnProofFailed(e HandshakeEvent) {
+	h.record("failed", e)
+}
+
+func (h *recordedHooks) OnError(e HandshakeEvent, err error) {
+	h.record("error", e)
+}
+
+func TestHooks(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	setNow(t, start)
+
+	hooks := new(recordedHooks)
+	client, err := NewClient(params, string(I), string(P), salt.Bytes(), WithHooks(hooks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithHooks(hooks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setNow(t, start.Add(time.Second))
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	wanted := []string{
+		"start client alice 0s",
+		"start server alice 0s",
+		"verified server alice 1s",
+		"verified client alice 1s",
+	}
+	if !reflect.DeepEqual(hooks.events, wanted) {
+		t.Fatalf("wanted %q, got %q", wanted, hooks.events)
+	}
+}
+
+func TestHooksFailure(t *testing.T) {
+	hooks := new(recordedHooks)
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithHooks(hooks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := server.CheckM1([]byte("invalid")); !errors.Is(err, ErrServerNoReady) {
+		t.Fatalf("expected ErrServerNoReady, got %v", err)
+	}
+	if err := server.SetA(make([]byte, 1)); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Fatalf("expected ErrInvalidPublicKey, got %v", err)
+	}
+	if err := server.SetA(A.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := server.CheckM1([]byte("invalid")); ok {
+		t.Fatal("expected an invalid proof to be rejected")
+	}
+	if _, err := server.ComputeM2(); err == nil {
+		t.Fatal("expected ComputeM2 to fail")
+	}
+
+	wanted := []string{"start", "error", "error", "failed", "error"}
+	if len(hooks.events) != len(wanted) {
+		t.Fatalf("wanted %d events, got %q", len(wanted), hooks.events)
+	}
+	for i, name := range wanted {
+		if got := hooks.events[i]; got[:len(name)] != name {
+			t.Fatalf("event %d: wanted %s, got %s", i, name, got)
+		}
+	}
+}
+
+func TestHooksRestore(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	setNow(t, start)
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := server.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setNow(t, start.Add(time.Minute))
+	hooks := new(recordedHooks)
+	server, err = RestoreServer(params, state, WithHooks(hooks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(make([]byte, 1)); err == nil {
+		t.Fatal("expected an invalid public key to be rejected")
+	}
+
+	wanted := []string{"error server alice 1m0s"}
+	if !reflect.DeepEqual(hooks.events, wanted) {
+		t.Fatalf("wanted %q, got %q", wanted, hooks.events)
+	}
+}
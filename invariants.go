@@ -0,0 +1,31 @@
+//go:build srpinvariants
+
+package srp
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Builds with the srpinvariants tag check the core
+// invariants of the protocol at runtime, and panic when
+// one of them is violated.
+//
+// They are meant to catch arithmetic regressions in tests
+// (e.g. go test -tags srpinvariants ./...), not to validate
+// untrusted input: values received from peers must still be
+// checked explicitly.
+
+// assertInRange panics if X is not in [1, N-1].
+func assertInRange(params *Params, name string, X *big.Int) {
+	if X == nil || X.Sign() <= 0 || X.Cmp(params.Group.N) >= 0 {
+		panic(fmt.Sprintf("srp: invariant violated: %s is not in [1, N-1]", name))
+	}
+}
+
+// assertNonZero panics if X is nil or zero.
+func assertNonZero(name string, X *big.Int) {
+	if X == nil || X.Sign() == 0 {
+		panic(fmt.Sprintf("srp: invariant violated: %s is zero", name))
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !srpinvariants
+
+package srp
+
+import "math/big"
+
+// The invariant checks compile to nothing unless
+// the srpinvariants build tag is set.
+
+func assertInRange(params *Params, name string, X *big.Int) {}
+
+func assertNonZero(name string, X *big.Int) {}
@@ -0,0 +1,65 @@
+//go:build srpinvariants
+
+package srp
+
+import (
+	"crypto"
+	"math/big"
+	"testing"
+)
+
+func TestInvariantsHandshake(t *testing.T) {
+	for _, g := range groups {
+		t.Run(g.ID, func(t *testing.T) {
+			params := &Params{
+				Group: g,
+				Hash:  crypto.SHA256,
+				KDF:   RFC5054KDF,
+			}
+
+			triplet, err := ComputeVerifier(params, "alice", "password123", NewSalt())
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, err := NewClient(params, "alice", "password123", triplet.Salt())
+			if err != nil {
+				t.Fatal(err)
+			}
+			server, err := NewServer(params, triplet.Username(), triplet.Salt(), triplet.Verifier())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := handshake(client, server); err != nil {
+				t.Fatal(err)
+			}
+
+			if client.xS.Cmp(server.xS) != 0 {
+				t.Fatal("client and server computed different premaster secrets")
+			}
+		})
+	}
+}
+
+func TestInvariantsPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{"Zero", func() { assertInRange(params, "X", big.NewInt(0)) }},
+		{"N", func() { assertInRange(params, "X", params.Group.N) }},
+		{"Negative", func() { assertInRange(params, "X", big.NewInt(-1)) }},
+		{"Nil", func() { assertInRange(params, "X", nil) }},
+		{"ZeroU", func() { assertNonZero("u", new(big.Int)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected a panic")
+				}
+			}()
+			tt.f()
+		})
+	}
+}
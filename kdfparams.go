@@ -0,0 +1,187 @@
+package srp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// KDFParams describes the key derivation function and the
+// cost parameters used to compute a user's verifier.
+//
+// A server stores them alongside the user's triplet (see
+// [KDFTriplet]) and sends them with the salt, so a client
+// logging in from a new device can compute x without
+// hard-coding the costs chosen at enrollment. Use
+// [WithKDFParams] on both sides to bind them to the proofs,
+// so they cannot be altered in transit.
+//
+// The meaning of each cost depends on Algorithm. Costs that
+// do not apply to an algorithm are left at zero.
+type KDFParams struct {
+	Algorithm   string `json:"alg"`           // e.g. "argon2id", "scrypt", "pbkdf2-sha256"
+	Iterations  uint32 `json:"t,omitempty"`   // Time cost, or number of iterations
+	Memory      uint32 `json:"m,omitempty"`   // Memory cost in KiB
+	Parallelism uint8  `json:"p,omitempty"`   // Degree of parallelism
+	KeyLen      uint32 `json:"len,omitempty"` // Length of x in bytes
+}
+
+// kdfParamsCostsSize is the length of the costs in
+// the binary form of [KDFParams].
+const kdfParamsCostsSize = 4 + 4 + 1 + 4
+
+// validate returns an error if p cannot be encoded.
+func (p KDFParams) validate() error {
+	if p.Algorithm == "" {
+		return errors.New("KDF algorithm cannot be empty")
+	}
+	if len(p.Algorithm) > math.MaxUint8 {
+		return fmt.Errorf("KDF algorithm cannot exceed %d bytes", math.MaxUint8)
+	}
+	return nil
+}
+
+// MarshalBinary encodes p as following:
+//
+//	+------------------------+
+//	| algorithmLen (1)       |
+//	+------------------------+
+//	| algorithm              |
+//	+------------------------+
+//	| iterations (4)         |
+//	+------------------------+
+//	| memory (4)             |
+//	+------------------------+
+//	| parallelism (1)        |
+//	+------------------------+
+//	| keyLen (4)             |
+//	+------------------------+
+//
+// The result can be sent as the payload of a
+// [MessageKDFParams] message.
+func (p KDFParams) MarshalBinary() ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return p.appendBinary(make([]byte, 0, 1+len(p.Algorithm)+kdfParamsCostsSize)), nil
+}
+
+// appendBinary appends the binary form of p to b,
+// without validating p.
+func (p KDFParams) appendBinary(b []byte) []byte {
+	b = append(b, byte(len(p.Algorithm)))
+	b = append(b, p.Algorithm...)
+	b = binary.BigEndian.AppendUint32(b, p.Iterations)
+	b = binary.BigEndian.AppendUint32(b, p.Memory)
+	b = append(b, p.Parallelism)
+	return binary.BigEndian.AppendUint32(b, p.KeyLen)
+}
+
+// UnmarshalBinary decodes p from data obtained
+// with MarshalBinary.
+func (p *KDFParams) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return errors.New("KDF params are empty")
+	}
+	algorithmLen := int(data[0])
+	if len(data) != 1+algorithmLen+kdfParamsCostsSize {
+		return errors.New("KDF params have an invalid length")
+	}
+
+	costs := data[1+algorithmLen:]
+	q := KDFParams{
+		Algorithm:   string(data[1 : 1+algorithmLen]),
+		Iterations:  binary.BigEndian.Uint32(costs[0:]),
+		Memory:      binary.BigEndian.Uint32(costs[4:]),
+		Parallelism: costs[8],
+		KeyLen:      binary.BigEndian.Uint32(costs[9:]),
+	}
+	if err := q.validate(); err != nil {
+		return err
+	}
+
+	*p = q
+	return nil
+}
+
+// WithKDFParams binds the handshake to the KDF params
+// the client used to compute x.
+//
+// The params are mixed into the client proof (M1), and therefore
+// into the server proof (M2), so a client that was sent weakened
+// costs by an attacker fails to authenticate the server.
+func WithKDFParams(p KDFParams) Option {
+	return func(o *options) {
+		o.kdfParams = &p
+	}
+}
+
+// KDFTriplet is a [Triplet] prefixed with the [KDFParams] it
+// was computed with, so that a server can store them together
+// and send the params with the salt of the user.
+//
+// A KDF triplet is structured as following:
+//
+//	+-------------------------+
+//	| paramsLen (2)           |
+//	+-------------------------+
+//	| KDF params (paramsLen)  |
+//	+-------------------------+
+//	| triplet                 |
+//	+-------------------------+
+//
+// where the KDF params are encoded with [KDFParams.MarshalBinary].
+type KDFTriplet []byte
+
+// NewKDFTriplet returns t prefixed with p.
+func NewKDFTriplet(p KDFParams, t Triplet) (KDFTriplet, error) {
+	params, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 0, 2+len(params)+len(t))
+	b = binary.BigEndian.AppendUint16(b, uint16(len(params)))
+	b = append(b, params...)
+	return append(b, t...), nil
+}
+
+// KDFParams returns the KDF params in t.
+func (t KDFTriplet) KDFParams() KDFParams {
+	var p KDFParams
+	p.UnmarshalBinary(t[2 : 2+t.paramsLen()])
+	return p
+}
+
+// Triplet returns the triplet in t.
+func (t KDFTriplet) Triplet() Triplet {
+	return Triplet(t[2+t.paramsLen():])
+}
+
+// paramsLen returns the length of the KDF params in t.
+func (t KDFTriplet) paramsLen() int {
+	return int(binary.BigEndian.Uint16(t))
+}
+
+// ParseKDFTriplet returns b as a [KDFTriplet], or an error
+// if it is mis-formatted.
+func ParseKDFTriplet(b []byte) (KDFTriplet, error) {
+	t := KDFTriplet(b)
+	if len(t) < 2 || len(t) < 2+t.paramsLen() {
+		return nil, errors.New("KDF triplet is too short to contain KDF params")
+	}
+
+	var p KDFParams
+	if err := p.UnmarshalBinary(t[2 : 2+t.paramsLen()]); err != nil {
+		return nil, err
+	}
+	if err := t.Triplet().validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,121 @@
+package srp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestKDFParamsBinary(t *testing.T) {
+	p := KDFParams{
+		Algorithm:   "argon2id",
+		Iterations:  3,
+		Memory:      64 * 1024,
+		Parallelism: 4,
+		KeyLen:      32,
+	}
+
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var q KDFParams
+	if err := q.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if q != p {
+		t.Fatalf("wanted %+v, got %+v", p, q)
+	}
+
+	if err := q.UnmarshalBinary(b[:len(b)-1]); err == nil {
+		t.Fatal("expected truncated KDF params to be rejected")
+	}
+	if err := q.UnmarshalBinary(nil); err == nil {
+		t.Fatal("expected empty KDF params to be rejected")
+	}
+	if _, err := (KDFParams{}).MarshalBinary(); err == nil {
+		t.Fatal("expected KDF params without an algorithm to be rejected")
+	}
+}
+
+func TestKDFParamsJSON(t *testing.T) {
+	p := KDFParams{Algorithm: "pbkdf2-sha256", Iterations: 600000, KeyLen: 32}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wanted := `{"alg":"pbkdf2-sha256","t":600000,"len":32}`
+	if string(b) != wanted {
+		t.Fatalf("wanted %s, got %s", wanted, b)
+	}
+}
+
+func TestWithKDFParams(t *testing.T) {
+	var (
+		enrolled = KDFParams{Algorithm: "argon2id", Iterations: 3, Memory: 65536, Parallelism: 4}
+		weakened = KDFParams{Algorithm: "argon2id", Iterations: 1, Memory: 8, Parallelism: 1}
+	)
+
+	tests := []struct {
+		name        string
+		client      []Option
+		shouldMatch bool
+	}{
+		{"Same", []Option{WithKDFParams(enrolled)}, true},
+		{"Weakened", []Option{WithKDFParams(weakened)}, false},
+		{"Missing", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(params, string(I), string(P), salt.Bytes(), tt.client...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithKDFParams(enrolled))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = handshake(client, server)
+			if tt.shouldMatch && err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+			if !tt.shouldMatch && err == nil {
+				t.Fatal("expected handshake to fail")
+			}
+		})
+	}
+}
+
+func TestKDFTriplet(t *testing.T) {
+	p := KDFParams{Algorithm: "argon2id", Iterations: 3, Memory: 64 * 1024, Parallelism: 4, KeyLen: 32}
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	stored, err := NewKDFTriplet(p, tp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseKDFTriplet(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := parsed.KDFParams(); q != p {
+		t.Fatalf("wanted %+v, got %+v", p, q)
+	}
+	assertEqualBytes(t, "triplet", tp, parsed.Triplet())
+
+	for _, invalid := range [][]byte{nil, {0}, {0, 200, 1}, stored[:len(stored)-len(tp)]} {
+		if _, err := ParseKDFTriplet(invalid); err == nil {
+			t.Fatalf("expected %x to be rejected", invalid)
+		}
+	}
+	if _, err := NewKDFTriplet(KDFParams{}, tp); err == nil {
+		t.Fatal("expected KDF params without an algorithm to be rejected")
+	}
+	if _, err := NewKDFTriplet(p, nil); err == nil {
+		t.Fatal("expected an empty triplet to be rejected")
+	}
+}
@@ -0,0 +1,111 @@
+package srp
+
+import (
+	"errors"
+	"log"
+	"math/big"
+	"time"
+)
+
+// now returns the current time. It is replaced in tests.
+var now = time.Now
+
+// AcceptLegacyProofsUntil configures a server to also accept
+// client proofs computed with legacy params until the given
+// deadline.
+//
+// This allows a fix to the way clients compute their proof
+// (e.g. a different [ProofScheme], [Padding] or
+// [KeyDerivation]) to be rolled out gradually, without
+// locking out clients that have not been updated yet.
+//
+// When the client proof matches the legacy form, the server
+// proof (M2) and the session key are computed with the legacy
+// params as well, so the client can verify them. Use
+// [Server.LegacyProof] to find out which form matched, or
+// [WithLogger] to log it.
+//
+// The legacy params must use the same group, hash, KDF and
+// value of k as the params of the server.
+func AcceptLegacyProofsUntil(deadline time.Time, legacy *Params) Option {
+	return func(o *options) {
+		o.legacy = legacy
+		o.legacyUntil = deadline
+	}
+}
+
+// WithLogger configures l to log notable events, such as a
+// client authenticating with a legacy proof.
+func WithLogger(l *log.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// logf logs a message if a logger was configured.
+func (o *options) logf(format string, args ...any) {
+	if o.logger != nil {
+		o.logger.Printf(format, args...)
+	}
+}
+
+// checkLegacy returns an error if the legacy params of o
+// are not compatible with params.
+func (o *options) checkLegacy(params *Params, k *big.Int) error {
+	if o.legacy == nil {
+		return nil
+	}
+
+	legacy := o.legacy
+	if legacy.Group != params.Group || legacy.Hash != params.Hash || !sameFunc(legacy.KDF, params.KDF) {
+		return errors.New("legacy params must use the same group, hash and KDF")
+	}
+
+	legacyK, err := computeLittleK(legacy)
+	if err != nil {
+		return err
+	}
+	if legacyK.Cmp(k) != 0 {
+		return errors.New("legacy params must produce the same value of k")
+	}
+	return nil
+}
+
+// checkLegacyM1 returns true if M1 matches the proof
+// computed with the legacy params, and the deadline to
+// accept legacy proofs has not passed yet.
+//
+// The values derived with the legacy params replace those
+// of s if it does.
+func (s *Server) checkLegacyM1(M1 []byte) bool {
+	if s.legacy == nil || !now().Before(s.opts.legacyUntil) {
+		return false
+	}
+	if !checkProof(s.legacy.m1.Bytes(), M1) {
+		return false
+	}
+
+	s.useLegacy()
+	s.opts.logf("srp: %q authenticated with a legacy proof (%s)", s.triplet.Username(), s.opts.legacy.Name)
+	return true
+}
+
+// useLegacy replaces the values of s with those
+// derived from the legacy params.
+func (s *Server) useLegacy() {
+	s.m1 = s.legacy.m1
+	s.m2 = s.legacy.m2
+	s.xS = s.legacy.xS
+	s.xK = s.legacy.xK
+	s.legacyMatched = true
+}
+
+// LegacyProof returns true if the client proof (M1)
+// verified by s was computed with the legacy params
+// configured with [AcceptLegacyProofsUntil].
+func (s *Server) LegacyProof() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.legacyMatched
+}
@@ -0,0 +1,152 @@
+package srp
+
+import (
+	"bytes"
+	"crypto"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+var legacyParams = &Params{
+	Name:  "legacy",
+	Group: RFC5054Group1024,
+	Hash:  crypto.SHA1,
+	KDF:   RFC5054KDF,
+	Proof: ProofSRP6a,
+}
+
+// setNow replaces the current time with
+// t until the test ends.
+func setNow(t *testing.T, current time.Time) {
+	t.Helper()
+
+	saved := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = saved })
+}
+
+func TestAcceptLegacyProofs(t *testing.T) {
+	var (
+		deadline = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		logs     bytes.Buffer
+	)
+	setNow(t, deadline.Add(-time.Hour))
+
+	client, err := NewClient(legacyParams, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(),
+		AcceptLegacyProofsUntil(deadline, legacyParams),
+		WithLogger(log.New(&logs, "", 0)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	if !server.LegacyProof() {
+		t.Fatal("expected the legacy proof to match")
+	}
+	if !strings.Contains(logs.String(), "legacy proof") {
+		t.Fatalf("expected the legacy proof to be logged, got %q", logs.String())
+	}
+
+	cK, err := client.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sK, err := server.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "K", cK, sK)
+}
+
+func TestAcceptLegacyProofsCanonical(t *testing.T) {
+	deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	setNow(t, deadline.Add(-time.Hour))
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), AcceptLegacyProofsUntil(deadline, legacyParams))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+	if server.LegacyProof() {
+		t.Fatal("expected the canonical proof to match")
+	}
+}
+
+func TestAcceptLegacyProofsExpired(t *testing.T) {
+	deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	setNow(t, deadline)
+
+	client, err := NewClient(legacyParams, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), AcceptLegacyProofsUntil(deadline, legacyParams))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err == nil {
+		t.Fatal("expected legacy proofs to be rejected after the deadline")
+	}
+}
+
+func TestAcceptLegacyProofsIncompatible(t *testing.T) {
+	incompatible := []*Params{
+		{Group: RFC5054Group2048, Hash: crypto.SHA1, KDF: RFC5054KDF},
+		{Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF, Padding: PadNone},
+	}
+	for _, legacy := range incompatible {
+		_, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), AcceptLegacyProofsUntil(time.Now(), legacy))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+}
+
+func TestRestoreServerLegacy(t *testing.T) {
+	deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	setNow(t, deadline.Add(-time.Hour))
+
+	client, err := NewClient(legacyParams, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), AcceptLegacyProofsUntil(deadline, legacyParams))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := server.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreServer(params, state, AcceptLegacyProofsUntil(deadline, legacyParams))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.LegacyProof() {
+		t.Fatal("expected the restored server to use the legacy proof")
+	}
+	assertEqualBytes(t, "K", server.xK, restored.xK)
+
+	if _, err := RestoreServer(params, state); err == nil {
+		t.Fatal("expected an error without legacy params")
+	}
+}
@@ -0,0 +1,38 @@
+package srp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// TagSize is the length of the tags returned by [Tag].
+const TagSize = sha256.Size
+
+// ConstantTimeEqual returns true if a and b are equal.
+//
+// The time it takes does not depend on the content of a and b,
+// which makes it suitable to compare secret values, such as
+// application-level proofs derived from a session key.
+func ConstantTimeEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Tag returns the HMAC-SHA256 authentication tag
+// of message, computed with key.
+//
+// key is typically derived from the session key shared by
+// a client and a server at the end of a handshake.
+func Tag(key, message []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// VerifyTag returns true if tag is the valid
+// authentication tag of message computed with [Tag].
+//
+// The comparison is performed in constant time.
+func VerifyTag(key, message, tag []byte) bool {
+	return hmac.Equal(Tag(key, message), tag)
+}
@@ -0,0 +1,51 @@
+package srp
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestConstantTimeEqual(t *testing.T) {
+	tests := []struct {
+		a, b   []byte
+		wanted bool
+	}{
+		{[]byte("abc"), []byte("abc"), true},
+		{[]byte("abc"), []byte("abd"), false},
+		{[]byte("abc"), []byte("ab"), false},
+		{nil, []byte{}, true},
+	}
+	for _, tt := range tests {
+		if got := ConstantTimeEqual(tt.a, tt.b); got != tt.wanted {
+			t.Fatalf("ConstantTimeEqual(%q, %q): wanted %v, got %v", tt.a, tt.b, tt.wanted, got)
+		}
+	}
+}
+
+// Test vector imported from RFC 4231 – Test Case 2
+// https://datatracker.ietf.org/doc/html/rfc4231#section-4.3
+func TestTag(t *testing.T) {
+	var (
+		key     = []byte("Jefe")
+		message = []byte("what do ya want for nothing?")
+		wanted  = "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	)
+
+	tag := Tag(key, message)
+	if got := hex.EncodeToString(tag); got != wanted {
+		t.Fatalf("wanted %s, got %s", wanted, got)
+	}
+	if len(tag) != TagSize {
+		t.Fatalf("expected a %d-byte tag, got %d", TagSize, len(tag))
+	}
+
+	if !VerifyTag(key, message, tag) {
+		t.Fatal("expected tag to be valid")
+	}
+	if VerifyTag(key, []byte("what do ya want for something?"), tag) {
+		t.Fatal("expected tag to be invalid for another message")
+	}
+	if VerifyTag([]byte("Joe"), message, tag) {
+		t.Fatal("expected tag to be invalid for another key")
+	}
+}
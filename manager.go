@@ -0,0 +1,232 @@
+package srp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSessionNotFound is returned when a session does not
+// exist, or has expired.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Store persists the state of pending handshakes.
+//
+// Implementations must be safe for concurrent use, and must
+// return [ErrSessionNotFound] from Get and GetAndDelete when key
+// does not exist or has expired.
+//
+// GetAndDelete must be atomic: when it is called concurrently
+// with the same key, only one of the calls may return the
+// value (e.g. GETDEL in Redis).
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	GetAndDelete(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// sessionIDSize is the number of random bytes
+// of a session ID.
+const sessionIDSize = 16
+
+// SessionManager keeps the state of the servers of pending
+// handshakes in a [Store], so a handshake can span several
+// requests, possibly served by different processes.
+//
+// Each server is identified by a random session ID, which
+// the client sends back with its next message.
+type SessionManager struct {
+	params *Params
+	store  Store
+	ttl    time.Duration
+	opts   []Option
+}
+
+// NewSessionManager returns a new SessionManager saving
+// servers to store.
+//
+// Servers expire from the store ttl after they were last
+// saved. The optional opts are used to restore servers, and
+// must match those they were created with.
+func NewSessionManager(params *Params, store Store, ttl time.Duration, opts ...Option) *SessionManager {
+	return &SessionManager{
+		params: params,
+		store:  store,
+		ttl:    ttl,
+		opts:   opts,
+	}
+}
+
+// Create saves s under a new session ID, and returns it.
+//
+// If the options of m include a [ThrottlePolicy] (see
+// [WithThrottle]), the policy is consulted first, so that a
+// throttled user is turned away before B is sent to them.
+func (m *SessionManager) Create(ctx context.Context, s *Server) (string, error) {
+	if err := m.checkThrottle(s); err != nil {
+		return "", err
+	}
+
+	id := base64.RawURLEncoding.EncodeToString(randomKey(sessionIDSize))
+	if err := m.Save(ctx, id, s); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Save saves the current state of s under id.
+func (m *SessionManager) Save(ctx context.Context, id string, s *Server) error {
+	state, err := s.Save()
+	if err != nil {
+		return err
+	}
+	return m.store.Set(ctx, id, state, m.ttl)
+}
+
+// Restore restores the server saved under id.
+//
+// ErrSessionNotFound is returned if id does not exist,
+// or has expired.
+func (m *SessionManager) Restore(ctx context.Context, id string) (*Server, error) {
+	state, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return RestoreServer(m.params, state, m.opts...)
+}
+
+// Take restores the server saved under id, and deletes it
+// from the store, so a client gets a single attempt to
+// complete the handshake.
+//
+// The server is removed atomically with [Store.GetAndDelete]:
+// when Take is called concurrently with the same id, only one
+// of the calls returns the server, and the others return
+// ErrSessionNotFound.
+func (m *SessionManager) Take(ctx context.Context, id string) (*Server, error) {
+	state, err := m.store.GetAndDelete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return RestoreServer(m.params, state, m.opts...)
+}
+
+// checkThrottle returns the error of the throttle policy of
+// m, if any, for the user of s.
+func (m *SessionManager) checkThrottle(s *Server) error {
+	o := newOptions(m.opts)
+	if o.throttle == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	username := s.triplet.Username()
+	s.mu.Unlock()
+	return o.checkThrottle(username)
+}
+
+// Delete deletes the server saved under id.
+func (m *SessionManager) Delete(ctx context.Context, id string) error {
+	return m.store.Delete(ctx, id)
+}
+
+// MemoryStore is a [Store] keeping values in memory.
+//
+// Expired values are removed lazily, when they are
+// accessed, or when [MemoryStore.Purge] is called.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// memoryEntry is a value held by a MemoryStore.
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryStore returns a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Get implements [Store].
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if !now().Before(e.expires) {
+		delete(s.entries, key)
+		return nil, ErrSessionNotFound
+	}
+	return e.value, nil
+}
+
+// GetAndDelete implements [Store].
+func (s *MemoryStore) GetAndDelete(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	delete(s.entries, key)
+	if !now().Before(e.expires) {
+		return nil, ErrSessionNotFound
+	}
+	return e.value, nil
+}
+
+// Set implements [Store].
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryEntry{
+		value:   append([]byte(nil), value...),
+		expires: now().Add(ttl),
+	}
+	return nil
+}
+
+// Delete implements [Store].
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Purge removes the expired values from s.
+func (s *MemoryStore) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+	for key, e := range s.entries {
+		if !t.Before(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// Len returns the number of values held by s,
+// including those that expired but were not
+// removed yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
@@ -0,0 +1,182 @@
+package srp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSessionManager(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		store   = NewMemoryStore()
+		manager = NewSessionManager(params, store, time.Minute)
+	)
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First request: the server sends B, and saves its state.
+	id, err := manager.Create(ctx, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetB(server.B()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Second request: the server receives A and M1.
+	restored, err := manager.Take(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.SetA(client.A()); err != nil {
+		t.Fatal(err)
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := restored.CheckM1(M1); err != nil || !ok {
+		t.Fatalf("expected M1 to be verified, got %v, %v", ok, err)
+	}
+
+	// The session can only be taken once.
+	if _, err := manager.Take(ctx, id); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestSessionManagerExpiration(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		store   = NewMemoryStore()
+		manager = NewSessionManager(params, store, time.Minute)
+		start   = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	setNow(t, start)
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := manager.Create(ctx, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setNow(t, start.Add(30*time.Second))
+	pending, err := manager.Create(ctx, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Restore(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	setNow(t, start.Add(time.Minute))
+	if _, err := manager.Restore(ctx, expired); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+	if _, err := manager.Restore(ctx, pending); err != nil {
+		t.Fatal(err)
+	}
+
+	setNow(t, start.Add(2*time.Minute))
+	store.Purge()
+	if n := store.Len(); n != 0 {
+		t.Fatalf("expected an empty store, got %d entries", n)
+	}
+}
+
+func TestSessionManagerDelete(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		manager = NewSessionManager(params, NewMemoryStore(), time.Minute)
+	)
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := manager.Create(ctx, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Restore(ctx, id); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestSessionManagerConcurrentTake(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		manager = NewSessionManager(params, NewMemoryStore(), time.Minute)
+	)
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := manager.Create(ctx, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 16
+	var (
+		wg    sync.WaitGroup
+		taken atomic.Int32
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := manager.Take(ctx, id)
+			switch {
+			case err == nil:
+				taken.Add(1)
+			case !errors.Is(err, ErrSessionNotFound):
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := taken.Load(); n != 1 {
+		t.Fatalf("expected the server to be taken once, got %d", n)
+	}
+}
+
+func TestMemoryStoreGetAndDeleteExpired(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemoryStore()
+		start = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	setNow(t, start)
+	if err := store.Set(ctx, "key", []byte("value"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	setNow(t, start.Add(time.Minute))
+	if _, err := store.GetAndDelete(ctx, "key"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+	if n := store.Len(); n != 0 {
+		t.Fatalf("expected the expired value to be removed, got %d values", n)
+	}
+}
@@ -0,0 +1,133 @@
+package srp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// MessageType identifies the content of a [Message].
+type MessageType uint8
+
+// Types of messages exchanged during a handshake.
+const (
+	MessageUsername  MessageType = iota + 1 // Username (I)
+	MessageSalt                             // User's salt (s)
+	MessageA                                // Client public ephemeral key (A)
+	MessageB                                // Server public ephemeral key (B)
+	MessageM1                               // Client proof (M1)
+	MessageM2                               // Server proof (M2)
+	MessageKDFParams                        // KDF params used to compute x
+	MessageTriplet                          // Triplet sent at registration
+)
+
+// String returns the name of t.
+func (t MessageType) String() string {
+	switch t {
+	case MessageUsername:
+		return "username"
+	case MessageSalt:
+		return "salt"
+	case MessageA:
+		return "A"
+	case MessageB:
+		return "B"
+	case MessageM1:
+		return "M1"
+	case MessageM2:
+		return "M2"
+	case MessageKDFParams:
+		return "KDF params"
+	case MessageTriplet:
+		return "triplet"
+	default:
+		return fmt.Sprintf("MessageType(%d)", uint8(t))
+	}
+}
+
+// MaxPayloadSize is the maximum length of the payload
+// of a [Message].
+const MaxPayloadSize = math.MaxUint16
+
+// messageHeaderSize is the length of the header
+// of a framed message.
+const messageHeaderSize = 3
+
+// Message represents a value exchanged between a client
+// and a server.
+//
+// A message is framed as following:
+//
+//	+------------------+
+//	| type (1)         |
+//	+------------------+
+//	| length (2)       |
+//	+------------------+
+//	| payload (length) |
+//	+------------------+
+type Message struct {
+	Type    MessageType
+	Payload []byte
+}
+
+// MarshalBinary returns m framed as a byte array.
+func (m Message) MarshalBinary() ([]byte, error) {
+	if len(m.Payload) > MaxPayloadSize {
+		return nil, fmt.Errorf("payload length cannot exceed %d bytes", MaxPayloadSize)
+	}
+
+	b := make([]byte, messageHeaderSize, messageHeaderSize+len(m.Payload))
+	b[0] = byte(m.Type)
+	binary.BigEndian.PutUint16(b[1:], uint16(len(m.Payload)))
+	return append(b, m.Payload...), nil
+}
+
+// UnmarshalBinary decodes a framed message
+// obtained with MarshalBinary.
+func (m *Message) UnmarshalBinary(data []byte) error {
+	if len(data) < messageHeaderSize {
+		return errors.New("message is too short")
+	}
+
+	length := int(binary.BigEndian.Uint16(data[1:]))
+	if len(data) != messageHeaderSize+length {
+		return errors.New("message length does not match its header")
+	}
+
+	m.Type = MessageType(data[0])
+	m.Payload = append([]byte(nil), data[messageHeaderSize:]...)
+	return nil
+}
+
+// WriteMessage writes m to w.
+func WriteMessage(w io.Writer, m Message) error {
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ReadMessage reads the next message from r.
+func ReadMessage(r io.Reader) (Message, error) {
+	var header [messageHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Message{}, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[1:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Message{}, err
+	}
+
+	return Message{
+		Type:    MessageType(header[0]),
+		Payload: payload,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package srp
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestMessageMarshalBinary(t *testing.T) {
+	m := Message{Type: MessageB, Payload: B.Bytes()}
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Message
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != m.Type {
+		t.Fatalf("wanted type %s, got %s", m.Type, got.Type)
+	}
+	assertEqualBytes(t, "payload", m.Payload, got.Payload)
+}
+
+func TestMessageUnmarshalBinaryInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"Empty":     {},
+		"Truncated": {byte(MessageA), 0x00, 0x02, 0x01},
+		"Trailing":  {byte(MessageA), 0x00, 0x01, 0x01, 0x02},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var m Message
+			if err := m.UnmarshalBinary(data); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestMessageTooLarge(t *testing.T) {
+	m := Message{Type: MessageA, Payload: make([]byte, MaxPayloadSize+1)}
+	if _, err := m.MarshalBinary(); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestReadWriteMessage(t *testing.T) {
+	var buf bytes.Buffer
+	messages := []Message{
+		{Type: MessageUsername, Payload: I},
+		{Type: MessageA, Payload: A.Bytes()},
+		{Type: MessageM1, Payload: []byte{}},
+	}
+	for _, m := range messages {
+		if err := WriteMessage(&buf, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, wanted := range messages {
+		got, err := ReadMessage(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Type != wanted.Type {
+			t.Fatalf("wanted type %s, got %s", wanted.Type, got.Type)
+		}
+		assertEqualBytes(t, "payload", wanted.Payload, got.Payload)
+	}
+
+	if _, err := ReadMessage(&buf); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	buf.Write([]byte{byte(MessageA), 0x00, 0x04, 0x01})
+	if _, err := ReadMessage(&buf); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package srp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TaggedTriplet is a [Triplet] prefixed with the name of the
+// params it was computed with, so that a server storing the
+// triplets of different params (e.g. during a migration) can
+// tell which params to use for each user.
+//
+// A tagged triplet is structured as following:
+//
+//	+--------------------+
+//	| nameLen (1)        |
+//	+--------------------+
+//	| name (nameLen)     |
+//	+--------------------+
+//	| triplet            |
+//	+--------------------+
+type TaggedTriplet []byte
+
+// NewTaggedTriplet returns t tagged with the name of params.
+//
+// An error is returned if params have no name, or if their
+// name is longer than 255 bytes.
+func NewTaggedTriplet(params *Params, t Triplet) (TaggedTriplet, error) {
+	if params.Name == "" {
+		return nil, errors.New("params must have a name to tag a triplet")
+	}
+	if len(params.Name) > math.MaxUint8 {
+		return nil, fmt.Errorf("params name cannot exceed %d bytes", math.MaxUint8)
+	}
+
+	var b bytes.Buffer
+	b.Grow(1 + len(params.Name) + len(t))
+	b.WriteByte(byte(len(params.Name)))
+	b.WriteString(params.Name)
+	b.Write(t)
+	return b.Bytes(), nil
+}
+
+// ParamsName returns the name of the params t
+// was computed with.
+func (t TaggedTriplet) ParamsName() string {
+	return string(t[1 : 1+int(t[0])])
+}
+
+// Triplet returns the triplet in t.
+func (t TaggedTriplet) Triplet() Triplet {
+	return Triplet(t[1+int(t[0]):])
+}
+
+// Params returns the registered params t was computed
+// with (see [Lookup]).
+func (t TaggedTriplet) Params() (*Params, error) {
+	return Lookup(t.ParamsName())
+}
+
+// validate returns an error if t is mis-formatted.
+func (t TaggedTriplet) validate() error {
+	if len(t) < 1 || len(t) < 1+int(t[0]) {
+		return errors.New("tagged triplet is too short to contain a name")
+	}
+	return t.Triplet().validate()
+}
+
+// ParseTaggedTriplet returns b as a [TaggedTriplet], or an
+// error if it is mis-formatted.
+func ParseTaggedTriplet(b []byte) (TaggedTriplet, error) {
+	t := TaggedTriplet(b)
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// taggedTripletTextPrefix is the prefix of the text encoding
+// of tagged triplets, which identifies its version.
+const taggedTripletTextPrefix = "srp-tagged-v1:"
+
+// Base64 returns t encoded as a string, made of a version
+// prefix followed by t in unpadded URL-safe base64:
+//
+//	srp-tagged-v1:EGZmZGhlMjA0OC1zaGEyNTYFYWxpY2...
+//
+// Unlike [Triplet.Base64], it keeps the name of the params,
+// and can be decoded with [ParseTaggedTripletBase64].
+func (t TaggedTriplet) Base64() string {
+	return taggedTripletTextPrefix + base64.RawURLEncoding.EncodeToString(t)
+}
+
+// ParseTaggedTripletBase64 returns the tagged triplet encoded
+// in s by [TaggedTriplet.Base64], or an error if s is
+// mis-formatted.
+func ParseTaggedTripletBase64(s string) (TaggedTriplet, error) {
+	encoded, ok := strings.CutPrefix(s, taggedTripletTextPrefix)
+	if !ok {
+		return nil, fmt.Errorf("tagged triplet must start with %q", taggedTripletTextPrefix)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("tagged triplet is not valid base64: %w", err)
+	}
+	return ParseTaggedTriplet(b)
+}
+
+// MarshalText returns t encoded as with [TaggedTriplet.Base64],
+// or an error if t is mis-formatted.
+func (t TaggedTriplet) MarshalText() ([]byte, error) {
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return []byte(t.Base64()), nil
+}
+
+// UnmarshalText decodes a tagged triplet encoded with
+// [TaggedTriplet.MarshalText].
+func (t *TaggedTriplet) UnmarshalText(text []byte) error {
+	parsed, err := ParseTaggedTripletBase64(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MigrateVerifier returns a request to replace the verifier
+// of the user with one computed with newParams, e.g. after a
+// successful login with params using a deprecated group or
+// KDF. The client sends the request to the server over the
+// established session.
+//
+// password must be the password used to log in. The request is
+// protected like those of [Session.ChangePassword], and is also
+// bound to the name of newParams, which must be set.
+//
+// As with a password change, the session keeps using its current
+// params until the client calls [Session.ConfirmMigration], once
+// the server acknowledged the migration.
+//
+// MigrateVerifier is called client-side, and runs the key
+// derivation function of newParams.
+func (s *Session) MigrateVerifier(newParams *Params, password string) ([]byte, error) {
+	if newParams.Name == "" {
+		return nil, errors.New("params must have a name to migrate a verifier")
+	}
+
+	newSalt := newParams.NewSalt()
+	tp, err := ComputeVerifier(newParams, s.username, password, newSalt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.verifier != nil {
+		return nil, errors.New("only the client can migrate the verifier")
+	}
+
+	request, err := s.sealTriplet(migrationInfo, tp, []byte(newParams.Name))
+	if err != nil {
+		return nil, err
+	}
+	s.pending = &sessionUpdate{info: migrationInfo, params: newParams, salt: newSalt}
+	return request, nil
+}
+
+// ConfirmMigration updates the session to the params of the
+// last request returned by [Session.MigrateVerifier], which
+// subsequent calls to [Session.Respond] use.
+//
+// It must only be called once the server acknowledged the
+// migration, i.e. once [Session.AcceptMigration] succeeded.
+// An error is returned if no migration is pending.
+//
+// ConfirmMigration is called client-side.
+func (s *Session) ConfirmMigration() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.confirm(migrationInfo, "no pending migration")
+}
+
+// AcceptMigration authenticates and decrypts a request returned
+// by [Session.MigrateVerifier] for newParams, and returns the
+// new triplet of the user, tagged with the name of newParams.
+//
+// The server must replace the stored triplet of the user with
+// the one returned. The session is updated to newParams and to
+// the new verifier.
+//
+// AcceptMigration is called server-side.
+func (s *Session) AcceptMigration(newParams *Params, request []byte) (TaggedTriplet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.verifier == nil {
+		return nil, errors.New("only the server can accept a migration")
+	}
+
+	tp, err := s.openTriplet(migrationInfo, request, []byte(newParams.Name), newParams)
+	if err != nil {
+		return nil, err
+	}
+	tagged, err := NewTaggedTriplet(newParams, tp)
+	if err != nil {
+		return nil, err
+	}
+
+	s.params = newParams
+	s.salt = tp.Salt()
+	s.verifier = tp.Verifier()
+	s.challenge = nil
+	return tagged, nil
+}
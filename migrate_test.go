@@ -0,0 +1,233 @@
+package srp
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	_ "crypto/sha256"
+)
+
+var migratedParams = &Params{
+	Name:  "DH14-SHA256-RFC5054",
+	Group: RFC5054Group2048,
+	Hash:  crypto.SHA256,
+	KDF:   RFC5054KDF,
+}
+
+func TestTaggedTriplet(t *testing.T) {
+	resetRegistry(t)
+	if err := Register(migratedParams); err != nil {
+		t.Fatal(err)
+	}
+
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+	tagged, err := NewTaggedTriplet(migratedParams, tp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseTaggedTriplet(tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := parsed.ParamsName(); name != migratedParams.Name {
+		t.Fatalf("wanted params %s, got %s", migratedParams.Name, name)
+	}
+	assertEqualBytes(t, "triplet", tp, parsed.Triplet())
+
+	p, err := parsed.Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != migratedParams {
+		t.Fatalf("wanted params %s, got %s", migratedParams, p)
+	}
+
+	if _, err := NewTaggedTriplet(params, tp); err == nil {
+		t.Fatal("expected params without a name to be rejected")
+	}
+	for _, b := range [][]byte{nil, {5, 'a'}, {1, 'a'}} {
+		if _, err := ParseTaggedTriplet(b); err == nil {
+			t.Fatalf("expected %x to be rejected", b)
+		}
+	}
+}
+
+func TestTaggedTripletBase64(t *testing.T) {
+	tagged, err := NewTaggedTriplet(migratedParams, NewTriplet(string(I), salt.Bytes(), v.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := tagged.Base64()
+	if !strings.HasPrefix(s, "srp-tagged-v1:") {
+		t.Fatalf("expected a version prefix, got %q", s)
+	}
+	parsed, err := ParseTaggedTripletBase64(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := parsed.ParamsName(); name != migratedParams.Name {
+		t.Fatalf("wanted params %s, got %s", migratedParams.Name, name)
+	}
+	assertEqualBytes(t, "tagged triplet", tagged, parsed)
+
+	// Tagged and untagged triplets are told apart.
+	invalid := []string{
+		"",
+		base64.RawURLEncoding.EncodeToString(tagged),
+		tagged.Triplet().Base64(),
+		"srp-tagged-v1:" + base64.StdEncoding.EncodeToString(tagged),
+		"srp-tagged-v1:" + base64.RawURLEncoding.EncodeToString(tagged[:1]),
+	}
+	for _, s := range invalid {
+		if _, err := ParseTaggedTripletBase64(s); err == nil {
+			t.Fatalf("expected %q to be rejected", s)
+		}
+	}
+	if _, err := ParseTripletBase64(s); err == nil {
+		t.Fatal("expected a tagged triplet to be rejected as a triplet")
+	}
+}
+
+func TestTaggedTripletMarshalText(t *testing.T) {
+	tagged, err := NewTaggedTriplet(migratedParams, NewTriplet(string(I), salt.Bytes(), v.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(map[string]TaggedTriplet{"triplet": tagged})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"triplet":"` + tagged.Base64() + `"}`; string(b) != want {
+		t.Fatalf("expected %s, got %s", want, b)
+	}
+
+	var decoded map[string]TaggedTriplet
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "tagged triplet", tagged, decoded["triplet"])
+
+	if _, err := TaggedTriplet(nil).MarshalText(); err == nil {
+		t.Fatal("expected an empty tagged triplet to be rejected")
+	}
+	var tp TaggedTriplet
+	if err := tp.UnmarshalText([]byte("srp-tagged-v1:!")); err == nil {
+		t.Fatal("expected invalid text to be rejected")
+	}
+}
+
+func TestMigrateVerifier(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	request, err := cs.MigrateVerifier(migratedParams, string(P))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagged, err := ss.AcceptMigration(migratedParams, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := tagged.ParamsName(); name != migratedParams.Name {
+		t.Fatalf("wanted params %s, got %s", migratedParams.Name, name)
+	}
+
+	// The new triplet authenticates the user with the new params.
+	tp := tagged.Triplet()
+	client, err := NewClient(migratedParams, string(I), string(P), tp.Salt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(migratedParams, tp.Username(), tp.Salt(), tp.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both sessions now use the new params.
+	if err := cs.ConfirmMigration(); err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := cs.Respond(challenge, string(P))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := ss.Verify(response); err != nil || !ok {
+		t.Fatalf("expected response to be valid, got %v, %v", ok, err)
+	}
+}
+
+func TestMigrateVerifierMismatch(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	request, err := cs.MigrateVerifier(migratedParams, string(P))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The request is bound to the new params.
+	other := *migratedParams
+	other.Name = "other"
+	if _, err := ss.AcceptMigration(&other, request); !errors.Is(err, ErrPasswordChangeRejected) {
+		t.Fatalf("expected ErrPasswordChangeRejected, got %v", err)
+	}
+
+	// A migration request is not a password change request.
+	if _, err := ss.AcceptPasswordChange(request); !errors.Is(err, ErrPasswordChangeRejected) {
+		t.Fatalf("expected ErrPasswordChangeRejected, got %v", err)
+	}
+
+	if _, err := cs.MigrateVerifier(params, string(P)); err == nil {
+		t.Fatal("expected params without a name to be rejected")
+	}
+}
+
+func TestMigrateVerifierPending(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	request, err := cs.MigrateVerifier(migratedParams, string(P))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Until the migration is confirmed, the client
+	// answers challenges with the current params.
+	challenge, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := cs.Respond(challenge, string(P))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := ss.Verify(response); err != nil || !ok {
+		t.Fatalf("expected response to be valid, got %v, %v", ok, err)
+	}
+
+	// A pending migration is not a password change.
+	if err := cs.ConfirmPasswordChange(); err == nil {
+		t.Fatal("expected an error without a pending password change")
+	}
+
+	if _, err := ss.AcceptMigration(migratedParams, request); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.ConfirmMigration(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.ConfirmMigration(); err == nil {
+		t.Fatal("expected a migration to be confirmed only once")
+	}
+}
@@ -0,0 +1,118 @@
+package srp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoCommonParams is returned when a client and a server
+// fail to agree on a common set of [Params].
+var ErrNoCommonParams = errors.New("no params in common with the client's offer")
+
+// WithOffer configures the names of the params a client
+// advertises to a server, in order of preference.
+//
+// The offer, and the params eventually selected by the
+// server, are bound to the proofs (M1, M2), so an attacker
+// tampering with the offer to force weaker params causes
+// the authentication to fail.
+//
+// The params the client is created with must be part of
+// the offer. See [Client.Offer] and [Server.Select].
+func WithOffer(params ...*Params) Option {
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.Name
+	}
+	return func(o *options) {
+		o.offer = names
+	}
+}
+
+// Offer returns the names of the params c advertises
+// to the server, in order of preference.
+//
+// If c was not configured with [WithOffer], it binds no
+// offer to its proofs, and Offer returns nil, which
+// [Server.Select] rejects.
+func (c *Client) Offer() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.opts.offer == nil {
+		return nil
+	}
+	return append([]string(nil), c.opts.offer...)
+}
+
+// Select checks that the params of s are part of the
+// offer advertised by a client, and returns their name
+// so it can be sent back to the client. An empty offer
+// is rejected.
+//
+// Select must be called before [Server.SetA] for the
+// offer to be bound to the proofs.
+//
+// Use [SelectParams] to pick the params of s among those
+// a server supports.
+func (s *Server) Select(offer []string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.xA != nil {
+		return "", errors.New("offer must be selected before A is set")
+	}
+	if err := validateOffer(offer); err != nil {
+		return "", err
+	}
+	if !containsName(offer, s.params.Name) {
+		return "", ErrNoCommonParams
+	}
+
+	s.opts.offer = append([]string(nil), offer...)
+	return s.params.Name, nil
+}
+
+// SelectParams returns the first params in supported,
+// listed in order of preference of the server, that are
+// part of the offer advertised by a client.
+func SelectParams(offer []string, supported ...*Params) (*Params, error) {
+	if err := validateOffer(offer); err != nil {
+		return nil, err
+	}
+	for _, p := range supported {
+		if containsName(offer, p.Name) {
+			return p, nil
+		}
+	}
+	return nil, ErrNoCommonParams
+}
+
+// validateOffer returns an error if offer is empty,
+// or contains empty or duplicate names.
+func validateOffer(offer []string) error {
+	if len(offer) == 0 {
+		return errors.New("offer is empty")
+	}
+	seen := make(map[string]bool, len(offer))
+	for _, name := range offer {
+		if name == "" {
+			return errors.New("offer contains params without a name")
+		}
+		if seen[name] {
+			return fmt.Errorf("offer contains %q more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// containsName returns true if name is in names.
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
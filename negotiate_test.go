@@ -0,0 +1,139 @@
+package srp
+
+import (
+	"crypto"
+	"testing"
+)
+
+func TestNegotiation(t *testing.T) {
+	var (
+		weak   = &Params{Name: "weak", Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF}
+		strong = &Params{Name: "strong", Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF}
+	)
+
+	client, err := NewClient(strong, string(I), string(P), salt.Bytes(), WithOffer(strong, weak))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	selected, err := SelectParams(client.Offer(), strong, weak)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selected != strong {
+		t.Fatalf("expected %q to be selected, got %q", strong.Name, selected.Name)
+	}
+
+	server, err := NewServer(selected, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := server.Select(client.Offer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != strong.Name {
+		t.Fatalf("expected %q to be selected, got %q", strong.Name, name)
+	}
+
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNegotiationDowngrade(t *testing.T) {
+	var (
+		weak   = &Params{Name: "weak", Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF}
+		strong = &Params{Name: "strong", Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF}
+	)
+
+	// The client offers both, but an attacker strips the
+	// strong params from the offer before it reaches the server.
+	client, err := NewClient(weak, string(I), string(P), salt.Bytes(), WithOffer(strong, weak))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := []string{weak.Name}
+
+	server, err := NewServer(weak, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Select(tampered); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handshake(client, server); err == nil {
+		t.Fatal("expected the downgrade to be detected")
+	}
+}
+
+func TestNegotiationWithoutOffer(t *testing.T) {
+	params := &Params{Name: "named", Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF}
+
+	// A client without an offer binds none to its proofs,
+	// so the server must not bind one either.
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offer := client.Offer(); offer != nil {
+		t.Fatalf("expected no offer, got %q", offer)
+	}
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Select(client.Offer()); err == nil {
+		t.Fatal("expected an empty offer to be rejected")
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerSelect(t *testing.T) {
+	params := &Params{Name: "named", Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		offer []string
+		err   bool
+	}{
+		{"Nil", nil, true},
+		{"Empty", []string{}, true},
+		{"Missing", []string{"other"}, true},
+		{"Duplicate", []string{params.Name, params.Name}, true},
+		{"Valid", []string{"other", params.Name}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server.Select(tt.offer)
+			if tt.err && err == nil {
+				t.Fatal("expected an error")
+			}
+			if !tt.err && err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if err := server.SetA(A.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Select([]string{params.Name}); err == nil {
+		t.Fatal("expected an error when A is already set")
+	}
+}
+
+func TestNewClientOfferMismatch(t *testing.T) {
+	other := &Params{Name: "other", Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF}
+	if _, err := NewClient(params, string(I), string(P), salt.Bytes(), WithOffer(other)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,254 @@
+package srp
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// OpenSSLEntry represents a user entry of a verifier file
+// managed with OpenSSL's srp command (srpvfile).
+//
+// OpenSSL computes verifiers with SHA-1 and the KDF defined
+// in RFC 5054, so entries should be used with [Params]
+// configured with crypto.SHA1 and [RFC5054KDF].
+type OpenSSLEntry struct {
+	Triplet Triplet // Username, salt and verifier
+	Group   *Group  // Group used to compute the verifier
+	Info    string  // Optional user information
+}
+
+// Fields of a line of an OpenSSL verifier file.
+const (
+	openSSLType = iota
+	openSSLVerifier
+	openSSLSalt
+	openSSLID
+	openSSLGroup
+	openSSLInfo
+	openSSLFields
+)
+
+// Types of lines of an OpenSSL verifier file.
+const (
+	openSSLValid   = "V" // Valid user entry
+	openSSLIndex   = "I" // Group definition
+	openSSLRevoked = "R" // Revoked user entry
+	openSSLPending = "v" // User entry pending validation
+)
+
+// openSSLGroups maps the identifiers OpenSSL uses for
+// the groups of RFC 5054.
+var openSSLGroups = []struct {
+	ID    string
+	Group *Group
+}{
+	{"1024", RFC5054Group1024},
+	{"1536", RFC5054Group1536},
+	{"2048", RFC5054Group2048},
+	{"3072", RFC5054Group3072},
+	{"4096", RFC5054Group4096},
+	{"6144", RFC5054Group6144},
+	{"8192", RFC5054Group8192},
+}
+
+// openSSLEncoding is the base64 alphabet used by OpenSSL
+// in SRP verifier files.
+var openSSLEncoding = base64.NewEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz./").WithPadding(base64.NoPadding)
+
+// openSSLLine is a line of an OpenSSL verifier file,
+// split into fields.
+type openSSLLine struct {
+	number int
+	fields []string
+}
+
+// ReadOpenSSLVerifiers reads the valid user entries of
+// an OpenSSL verifier file.
+//
+// Groups defined in the file itself (index lines) are
+// supported. Revoked entries and entries pending validation
+// are skipped.
+func ReadOpenSSLVerifiers(r io.Reader) ([]OpenSSLEntry, error) {
+	var (
+		scanner = bufio.NewScanner(r)
+		groups  = make(map[string]*Group)
+		users   []openSSLLine
+	)
+	for _, g := range openSSLGroups {
+		groups[g.ID] = g.Group
+	}
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != openSSLFields {
+			return nil, fmt.Errorf("line %d: expected %d fields, got %d", line, openSSLFields, len(fields))
+		}
+
+		switch fields[openSSLType] {
+		case openSSLIndex:
+			N, err := decodeOpenSSLInt(fields[openSSLVerifier])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid N: %w", line, err)
+			}
+			g, err := decodeOpenSSLInt(fields[openSSLSalt])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid g: %w", line, err)
+			}
+			groups[fields[openSSLID]] = &Group{
+				ID:           fields[openSSLID],
+				Generator:    g,
+				N:            N,
+				ExponentSize: minEphemeralKeySize,
+			}
+		case openSSLValid:
+			users = append(users, openSSLLine{line, fields})
+		case openSSLRevoked, openSSLPending:
+		default:
+			return nil, fmt.Errorf("line %d: unknown type %q", line, fields[openSSLType])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Index lines may appear after the
+	// entries referencing them.
+	entries := make([]OpenSSLEntry, 0, len(users))
+	for _, u := range users {
+		line, fields := u.number, u.fields
+
+		group, ok := groups[fields[openSSLGroup]]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown group %q", line, fields[openSSLGroup])
+		}
+		verifier, err := decodeOpenSSLBase64(fields[openSSLVerifier])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid verifier: %w", line, err)
+		}
+		salt, err := decodeOpenSSLBase64(fields[openSSLSalt])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid salt: %w", line, err)
+		}
+		username := fields[openSSLID]
+		if len(username) > 255 || len(salt) > 127 {
+			return nil, fmt.Errorf("line %d: username or salt is too long", line)
+		}
+
+		entries = append(entries, OpenSSLEntry{
+			Triplet: NewTriplet(username, salt, verifier),
+			Group:   group,
+			Info:    fields[openSSLInfo],
+		})
+	}
+	return entries, nil
+}
+
+// WriteOpenSSLVerifiers writes entries to w in the format
+// of an OpenSSL verifier file.
+//
+// Groups that are not defined in RFC 5054 are written as
+// index lines, identified by their ID.
+func WriteOpenSSLVerifiers(w io.Writer, entries []OpenSSLEntry) error {
+	var (
+		bw      = bufio.NewWriter(w)
+		indexed = make(map[string]*Group)
+	)
+
+	writeLine := func(fields ...string) {
+		bw.WriteString(strings.Join(fields, "\t"))
+		bw.WriteByte('\n')
+	}
+
+	for _, e := range entries {
+		if err := e.Triplet.validate(); err != nil {
+			return err
+		}
+		if e.Group == nil {
+			return fmt.Errorf("entry %q has no group", e.Triplet.Username())
+		}
+		if strings.ContainsAny(e.Triplet.Username()+e.Info, "\t\n") {
+			return fmt.Errorf("entry %q contains tabs or line breaks", e.Triplet.Username())
+		}
+
+		id := openSSLGroupID(e.Group)
+		if id == "" {
+			id = e.Group.ID
+			if id == "" || strings.ContainsAny(id, "\t\n") {
+				return errors.New("custom groups must have a valid ID")
+			}
+			if g, ok := indexed[id]; !ok {
+				writeLine(openSSLIndex, encodeOpenSSLBase64(e.Group.N.Bytes()), encodeOpenSSLBase64(e.Group.Generator.Bytes()), id, "", "")
+				indexed[id] = e.Group
+			} else if g.N.Cmp(e.Group.N) != 0 || g.Generator.Cmp(e.Group.Generator) != 0 {
+				return fmt.Errorf("different groups share the ID %q", id)
+			}
+		}
+
+		writeLine(
+			openSSLValid,
+			encodeOpenSSLBase64(e.Triplet.Verifier()),
+			encodeOpenSSLBase64(e.Triplet.Salt()),
+			e.Triplet.Username(),
+			id,
+			e.Info,
+		)
+	}
+	return bw.Flush()
+}
+
+// openSSLGroupID returns the identifier OpenSSL uses for g,
+// or an empty string if g is not one of its default groups.
+func openSSLGroupID(g *Group) string {
+	for _, known := range openSSLGroups {
+		if known.Group.N.Cmp(g.N) == 0 && known.Group.Generator.Cmp(g.Generator) == 0 {
+			return known.ID
+		}
+	}
+	return ""
+}
+
+// encodeOpenSSLBase64 encodes b the way OpenSSL does
+// in SRP verifier files.
+//
+// The input is left-padded with zeros to a multiple of 3
+// bytes before being encoded, and the resulting leading
+// characters are stripped.
+func encodeOpenSSLBase64(b []byte) string {
+	leadz := (3 - len(b)%3) % 3
+	padded := append(make([]byte, leadz, leadz+len(b)), b...)
+	return openSSLEncoding.EncodeToString(padded)[leadz:]
+}
+
+// decodeOpenSSLBase64 decodes a string encoded with
+// encodeOpenSSLBase64.
+func decodeOpenSSLBase64(s string) ([]byte, error) {
+	padsize := (4 - len(s)%4) % 4
+	if padsize == 3 {
+		return nil, errors.New("invalid base64 length")
+	}
+
+	b, err := openSSLEncoding.DecodeString(strings.Repeat("0", padsize) + s)
+	if err != nil {
+		return nil, err
+	}
+	return b[padsize:], nil
+}
+
+// decodeOpenSSLInt decodes a big-endian integer encoded
+// with encodeOpenSSLBase64.
+func decodeOpenSSLInt(s string) (*big.Int, error) {
+	b, err := decodeOpenSSLBase64(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,141 @@
+package srp
+
+import (
+	"bytes"
+	"crypto"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// Verifier file generated with OpenSSL 3.0:
+//
+//	openssl srp -srpvfile passwd.srpv -add -passout pass:password123 -gn 1024 alice
+//	openssl srp -srpvfile passwd.srpv -add -passout pass:secret -gn 2048 -userinfo "Bob B" bob
+const openSSLVerifierFile = "V\tAMMrMBekmPpQpLx.JhjkDE8TD8jGlwYWNnzT3v7b7ydhwWxZ4GrI4uAnOVdmvrH5xCHkPGKGQ.0ryR4nINdEtoCImWA4EB8d10mKJUBL2dbBVZH8jpWMd.jRWJMvmfYDzVV0zoB0gx0ZyMe38RWsr3v4Qpc2VRIMIqhn5.nRd/e\t6fj.OWqi.t08un7B2NA4CQ.Bak5\talice\t1024\t\n" +
+	"V\t1/T7/exvC56KUshENpikaqIIyZHdoEMul3eG1YJuPO6k4eCznab2o.j/3Svsxwi.8n70G1yoUeWqiqeokdoqd7OqCDjETLQStUzg2vL1HyteMtDLvpcKIUorGxFCn.WTyoAfqOpRGNkQ6rdMCNBnv3xua6LUeh7Cn0OlF9EA9Rwy/5.xDFaY4tbuLVfqKsl4wKzH7Ew3K0LJfNrI2lS8iLPmJ56..cLVs/oyLQ5WyKrnB7t4lnpYtbX8WmR7bqb6CrTHboF0spXcQZ42MEvE732ySpPJfkEb12w.uVMtIJbvFFXPCrULNXHHkC4BujYZc4i0wlR7V8zzc3sfzqf22H\t6a6g4kaJLuKeryy0yhS6rBrNAk2\tbob\t2048\tBob B\n"
+
+func TestReadOpenSSLVerifiers(t *testing.T) {
+	entries, err := ReadOpenSSLVerifiers(strings.NewReader(openSSLVerifierFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	tests := []struct {
+		username string
+		password string
+		group    *Group
+		info     string
+	}{
+		{"alice", "password123", RFC5054Group1024, ""},
+		{"bob", "secret", RFC5054Group2048, "Bob B"},
+	}
+	for i, tt := range tests {
+		e := entries[i]
+		if e.Triplet.Username() != tt.username {
+			t.Fatalf("wanted username %q, got %q", tt.username, e.Triplet.Username())
+		}
+		if e.Group != tt.group {
+			t.Fatalf("%s: unexpected group", tt.username)
+		}
+		if e.Info != tt.info {
+			t.Fatalf("wanted info %q, got %q", tt.info, e.Info)
+		}
+
+		params := &Params{Group: e.Group, Hash: crypto.SHA1, KDF: RFC5054KDF}
+		ok, err := VerifyLocal(params, e.Triplet, tt.username, tt.password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("%s: verifier does not match the password", tt.username)
+		}
+	}
+}
+
+func TestWriteOpenSSLVerifiers(t *testing.T) {
+	entries, err := ReadOpenSSLVerifiers(strings.NewReader(openSSLVerifierFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOpenSSLVerifiers(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != openSSLVerifierFile {
+		t.Fatalf("wanted:\n%s\ngot:\n%s", openSSLVerifierFile, buf.String())
+	}
+}
+
+func TestOpenSSLVerifiersCustomGroup(t *testing.T) {
+	group := &Group{
+		ID:           "custom",
+		Generator:    big.NewInt(7),
+		N:            RFC5054Group1024.N,
+		ExponentSize: 32,
+	}
+
+	var buf bytes.Buffer
+	err := WriteOpenSSLVerifiers(&buf, []OpenSSLEntry{
+		{Triplet: NewTriplet("alice", salt.Bytes(), v.Bytes()), Group: group},
+		{Triplet: NewTriplet("bob", salt.Bytes(), v.Bytes()), Group: group, Info: "info"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indexes int
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "I\t") {
+			indexes++
+		}
+	}
+	if indexes != 1 {
+		t.Fatalf("expected a single index line, got %d:\n%s", indexes, buf.String())
+	}
+
+	entries, err := ReadOpenSSLVerifiers(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Group.N.Cmp(group.N) != 0 || e.Group.Generator.Cmp(group.Generator) != 0 {
+			t.Fatal("group mismatch")
+		}
+		assertEqualBytes(t, "salt", salt.Bytes(), e.Triplet.Salt())
+		assertEqualBytes(t, "verifier", v.Bytes(), e.Triplet.Verifier())
+	}
+}
+
+func TestReadOpenSSLVerifiersInvalid(t *testing.T) {
+	tests := map[string]string{
+		"Fields":  "V\tabc\tdef\talice\n",
+		"Type":    "X\tabc\tdef\talice\t1024\t\n",
+		"Group":   "V\tabc\tdef\talice\tunknown\t\n",
+		"Base64":  "V\ta\tdef\talice\t1024\t\n",
+		"Charset": "V\tab!\tdef\talice\t1024\t\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadOpenSSLVerifiers(strings.NewReader(data)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestOpenSSLBase64(t *testing.T) {
+	for _, b := range [][]byte{{0x01}, {0x00, 0x01}, {0x01, 0x02, 0x03}, {0xff, 0xfe, 0xfd, 0xfc}} {
+		got, err := decodeOpenSSLBase64(encodeOpenSSLBase64(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, "base64", b, got)
+	}
+}
@@ -0,0 +1,141 @@
+package srp
+
+import (
+	"encoding/binary"
+	"io"
+	"log"
+	"time"
+)
+
+// Option configures optional features of a [Client]
+// or a [Server].
+//
+// Options that affect the proofs (M1, M2) must be identical on
+// both sides, otherwise authentication fails.
+type Option func(*options)
+
+// options holds the optional settings shared by
+// clients and servers.
+type options struct {
+	label          []byte   // Application protocol label
+	offer          []string // Names of the params advertised by the client
+	channelBinding []byte   // TLS channel binding data
+
+	kdfParams  *KDFParams          // KDF params used to compute x
+	entropy    io.Reader           // Additional entropy of the ephemeral keys
+	hardened   bool                // Blinds secret exponents
+	strict     bool                // Rejects weak params
+	pool       *EphemeralPool      // Source of pre-generated server keys
+	hooks      Hooks               // Receives handshake events
+	auditSink  AuditSink           // Receives proof verification outcomes
+	throttle   ThrottlePolicy      // Limits failed attempts
+	transcript *TranscriptRecorder // Records intermediate values
+
+	minPublicKeyBits int               // Minimum length of received public keys
+	minUBits         int               // Length of u below which it is reported
+	sanityHook       func(SanityEvent) // Called when a sanity check fails
+
+	legacy      *Params     // Params of legacy client proofs
+	legacyUntil time.Time   // Deadline to accept legacy client proofs
+	logger      *log.Logger // Optional logger
+
+	fakeSaltLen   int  // Length of the salts of fake servers
+	stateOverride bool // Restores states saved with params of another name
+}
+
+// newOptions returns the options resulting from
+// applying opts in order.
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithProtocolLabel binds the handshake to an application
+// protocol label (e.g. "myapp/3").
+//
+// The label is mixed into the client proof (M1), and therefore
+// into the server proof (M2), so a client and a server configured
+// with different labels fail to authenticate each other instead
+// of agreeing on a session they interpret differently.
+func WithProtocolLabel(label string) Option {
+	return func(o *options) {
+		o.label = []byte(label)
+	}
+}
+
+// WithAdditionalEntropy mixes the output of r into the
+// private ephemeral keys (a, b), in addition to the bytes
+// read from crypto/rand.
+//
+// It is meant for platforms whose random number generator
+// may be weak (e.g. embedded boards), where r reads from an
+// independent source such as a hardware TRNG. The keys remain
+// as unpredictable as the strongest of the two sources.
+//
+// Reading from r must not fail: an error is returned when
+// generating a key pair otherwise. Unlike most options, it
+// does not need to match on both sides.
+func WithAdditionalEntropy(r io.Reader) Option {
+	return func(o *options) {
+		o.entropy = r
+	}
+}
+
+// Tags identifying each value bound to the proofs.
+const (
+	bindingLabel byte = iota + 1
+	bindingOffer
+	bindingSelected
+	bindingKDFParams
+	bindingChannel
+)
+
+// binding returns the digest of all the values o binds to
+// the proofs, or nil if there is none.
+//
+// A nil binding leaves the proofs unchanged, which preserves
+// compatibility with [RFC5054].
+//
+// [RFC5054]: https://datatracker.ietf.org/doc/html/rfc5054
+func (o *options) binding(params *Params) []byte {
+	var items [][]byte
+	if o.label != nil {
+		items = append(items, bindingItem(bindingLabel, o.label))
+	}
+	if o.offer != nil {
+		for _, name := range o.offer {
+			items = append(items, bindingItem(bindingOffer, []byte(name)))
+		}
+		items = append(items, bindingItem(bindingSelected, []byte(params.Name)))
+	}
+	if o.kdfParams != nil {
+		items = append(items, bindingItem(bindingKDFParams, o.kdfParams.appendBinary(nil)))
+	}
+	if o.channelBinding != nil {
+		items = append(items, bindingItem(bindingChannel, o.channelBinding))
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	h := params.Hash.New()
+	for _, item := range items {
+		h.Write(item)
+	}
+	return h.Sum(nil)[:h.Size()]
+}
+
+// bindingItem encodes value as:
+//
+//	tag (1) | len(value) (4) | value
+func bindingItem(tag byte, value []byte) []byte {
+	b := make([]byte, 5, 5+len(value))
+	b[0] = tag
+	binary.BigEndian.PutUint32(b[1:], uint32(len(value)))
+	return append(b, value...)
+}
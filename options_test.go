@@ -0,0 +1,125 @@
+package srp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWithProtocolLabel(t *testing.T) {
+	tests := []struct {
+		name        string
+		client      []Option
+		server      []Option
+		shouldMatch bool
+	}{
+		{"None", nil, nil, true},
+		{"Same", []Option{WithProtocolLabel("myapp/3")}, []Option{WithProtocolLabel("myapp/3")}, true},
+		{"Different", []Option{WithProtocolLabel("myapp/2")}, []Option{WithProtocolLabel("myapp/3")}, false},
+		{"ClientOnly", []Option{WithProtocolLabel("myapp/3")}, nil, false},
+		{"ServerOnly", nil, []Option{WithProtocolLabel("myapp/3")}, false},
+		{"Empty", []Option{WithProtocolLabel("")}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(params, string(I), string(P), salt.Bytes(), tt.client...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), tt.server...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = handshake(client, server)
+			if tt.shouldMatch && err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+			if !tt.shouldMatch && err == nil {
+				t.Fatal("expected handshake to fail")
+			}
+		})
+	}
+}
+
+func TestRestoreServerWithProtocolLabel(t *testing.T) {
+	client, err := NewClient(params, string(I), string(P), salt.Bytes(), WithProtocolLabel("myapp/3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithProtocolLabel("myapp/3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(client.A()); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := server.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreServer(params, state, WithProtocolLabel("myapp/3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.SetB(restored.B()); err != nil {
+		t.Fatal(err)
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := restored.CheckM1(M1); !ok {
+		t.Fatalf("M1 not verified: %v", err)
+	}
+}
+
+// constantReader returns an infinite sequence of the same byte.
+type constantReader byte
+
+func (r constantReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestWithAdditionalEntropy(t *testing.T) {
+	entropy := WithAdditionalEntropy(constantReader(0xa5))
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes(), entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	// A predictable source of entropy does not make
+	// the keys predictable.
+	other, err := NewClient(params, string(I), string(P), salt.Bytes(), entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.a.Cmp(other.a) == 0 {
+		t.Fatal("expected private keys to differ")
+	}
+}
+
+func TestWithAdditionalEntropyFailure(t *testing.T) {
+	entropy := WithAdditionalEntropy(bytes.NewReader([]byte{0x01}))
+	if _, err := NewClient(params, string(I), string(P), salt.Bytes(), entropy); err == nil {
+		t.Fatal("expected client creation to fail")
+	}
+
+	entropy = WithAdditionalEntropy(bytes.NewReader(nil))
+	if _, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), entropy); err == nil {
+		t.Fatal("expected server creation to fail")
+	}
+}
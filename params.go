@@ -73,7 +73,17 @@ func mustParseHex(parts ...string) *big.Int {
 // [Scrypt]: https://pkg.go.dev/golang.org/x/crypto/scrypt
 // [PBKDF2]: https://pkg.go.dev/golang.org/x/crypto/pbkdf2
 func RFC5054KDF(username, password string, salt []byte) ([]byte, error) {
-	h := crypto.SHA1.New()
+	return rfc5054KDF(crypto.SHA1, username, password, salt)
+}
+
+// rfc5054KDF computes x as defined in [RFC5054], using the
+// given hash function.
+//
+//	x = H(s | H(U | ":" | p))
+//
+// [RFC5054]: https://datatracker.ietf.org/doc/html/rfc5054
+func rfc5054KDF(hash crypto.Hash, username, password string, salt []byte) ([]byte, error) {
+	h := hash.New()
 	h.Write([]byte(fmt.Sprintf("%s:%s", username, password)))
 	digest := h.Sum(nil)[:h.Size()]
 
@@ -113,8 +123,172 @@ type Params struct {
 	Group *Group
 	Hash  crypto.Hash
 	KDF   KDF
+
+	// Proof selects the formula used to compute the
+	// proofs M1 and M2. Defaults to [ProofRFC2945].
+	Proof ProofScheme
+
+	// KeyDerivation selects how the session key K is
+	// derived from the premaster secret S. Defaults
+	// to [KeyHash].
+	KeyDerivation KeyDerivation
+
+	// Padding selects which values are left-padded with
+	// zeros to the length of N before being hashed.
+	// Defaults to [PadRFC5054].
+	Padding Padding
+
+	// SaltWidth, if non-zero, is the length in bytes to
+	// which the salt is left-padded with zeros in the client
+	// proof (M1), for implementations that store the salt as
+	// an integer and hash it at a fixed width.
+	SaltWidth int
+
+	// Compat reproduces the non-standard derivations of
+	// other SRP implementations. It takes precedence over
+	// the other settings of the params.
+	Compat Compatibility
+
+	// SessionKeyFormat selects the length and the encoding
+	// of the key returned by SessionKey. Defaults to
+	// [SessionKeyRaw].
+	SessionKeyFormat SessionKeyFormat
+
+	// Normalization selects how usernames and passwords are
+	// prepared before being passed to the KDF. Defaults to
+	// [NormalizeNFKD].
+	Normalization Normalization
+
+	table *fixedBaseTable // Built by Precompute
 }
 
+// SessionKeyFormat identifies the length and the encoding
+// of the session key returned to applications.
+//
+// The format does not affect the proofs, nor the values
+// derived internally from the session key.
+type SessionKeyFormat int
+
+// Available session key formats.
+const (
+	// SessionKeyRaw returns K as derived by the params,
+	// whose length depends on the hash and on the
+	// [KeyDerivation].
+	SessionKeyRaw SessionKeyFormat = iota
+
+	// SessionKeyBase64 returns K encoded in standard
+	// base64, as defined in RFC 4648.
+	SessionKeyBase64
+
+	// SessionKey32 returns a 32-byte key derived from K
+	// with HKDF, using the hash of the params, which is
+	// suitable as an AES-256 or ChaCha20-Poly1305 key.
+	SessionKey32
+)
+
+// Padding identifies which values are left-padded with zeros
+// to the length of N before being hashed.
+type Padding int
+
+// Available padding policies.
+const (
+	// PadRFC5054 pads g when computing k, and A and B when
+	// computing u, as defined in RFC 5054. A and B are not
+	// padded in the proofs.
+	PadRFC5054 Padding = iota
+
+	// PadAll pads g when computing k, A and B when computing
+	// u, and A, B and S wherever they are hashed in the proofs:
+	// A and B in M1, S in M1 with [ProofSRP6a], A in M2, and S
+	// in M2 with [ProofSRP6a]. The proofs of implementations
+	// hashing fixed-width encodings then match even when
+	// these values start with zeros.
+	PadAll
+
+	// PadNone never pads any value, which is what many
+	// SRP-6a implementations do when computing k and u.
+	PadNone
+)
+
+// Compatibility identifies an SRP implementation whose
+// non-standard derivations should be reproduced.
+type Compatibility int
+
+// Available compatibility modes.
+const (
+	// CompatNone follows RFC 5054 and RFC 2945, as
+	// configured by the other settings of the params.
+	CompatNone Compatibility = iota
+
+	// CompatOnePassword reproduces the derivations of
+	// github.com/1Password/srp, from which this package
+	// is derived:
+	//
+	//	k = H(N | g)
+	//	u = H(hex(A) | hex(B))
+	//	K = H(hex(S))
+	//
+	// where hex(i) is the lowercase hexadecimal
+	// representation of i without leading zeros.
+	//
+	// See [OnePasswordProfile].
+	CompatOnePassword
+
+	// CompatThinbus reproduces the derivations of Thinbus,
+	// a JavaScript SRP-6a client, which hashes the hexadecimal
+	// representations of the values rather than their bytes:
+	//
+	//	u  = H(hex(A) | hex(B))
+	//	M1 = H(hex(A) | hex(B) | hex(S))
+	//	M2 = H(hex(A) | hex(M1) | hex(S))
+	//	K  = H(hex(S))
+	//
+	// k is computed as defined in RFC 5054.
+	//
+	// See [ThinbusProfile].
+	CompatThinbus
+)
+
+// KeyDerivation identifies the function used to derive
+// the session key K from the premaster secret S.
+type KeyDerivation int
+
+// Available session key derivations.
+const (
+	// KeyHash derives the session key as K = H(S).
+	KeyHash KeyDerivation = iota
+
+	// KeyInterleave derives the session key as
+	// K = SHA_Interleave(S), as defined in RFC 2945,
+	// using the hash of the params instead of SHA-1.
+	//
+	// The resulting key is twice as long as the
+	// output of the hash.
+	KeyInterleave
+)
+
+// ProofScheme identifies the formula used to compute
+// the client and server proofs (M1, M2).
+type ProofScheme int
+
+// Available proof schemes.
+const (
+	// ProofRFC2945 computes the proofs as defined
+	// in RFC 2945:
+	//
+	//	M1 = H(H(N) XOR H(g) | H(U) | s | A | B | K)
+	//	M2 = H(A | M1 | K)
+	ProofRFC2945 ProofScheme = iota
+
+	// ProofSRP6a computes the proofs the way many
+	// SRP-6a implementations do (e.g. Nimbus, Thinbus,
+	// srptools):
+	//
+	//	M1 = H(A | B | S)
+	//	M2 = H(A | M1 | S)
+	ProofSRP6a
+)
+
 // hashBytes returns the hash of a.
 func (p *Params) hashBytes(a []byte) []byte {
 	h := p.Hash.New()
@@ -127,6 +301,93 @@ func (p *Params) String() string {
 	return p.Name
 }
 
+// minStrictGroupBits is the minimum length of N accepted
+// by [Params.ValidateStrict].
+const minStrictGroupBits = 2048
+
+// Validate returns an error if p cannot be used to run a
+// handshake: the group must be complete and at least 1024
+// bits long, the hash must be linked into the binary, the
+// KDF must be set, and the other settings must be known.
+//
+// Unlike [Group.Validate], it does not check that N is a safe
+// prime, which is too expensive to do for each handshake.
+//
+// [NewClient] and [NewServer] call Validate, or
+// [Params.ValidateStrict] with [WithStrictParams].
+func (p *Params) Validate() error {
+	if p == nil {
+		return errors.New("params cannot be nil")
+	}
+	if p.Group == nil || p.Group.N == nil || p.Group.Generator == nil {
+		return fmt.Errorf("params %q: group is incomplete", p.Name)
+	}
+	if bits := p.Group.N.BitLen(); bits < minGroupBits {
+		return fmt.Errorf("params %q: %d-bit group is shorter than %d bits", p.Name, bits, minGroupBits)
+	}
+	if !p.Hash.Available() {
+		return fmt.Errorf("params %q: hash %v is not available; import its package (e.g. crypto/sha256)", p.Name, p.Hash)
+	}
+	if p.KDF == nil {
+		return fmt.Errorf("params %q: KDF cannot be nil", p.Name)
+	}
+
+	switch {
+	case p.Proof < ProofRFC2945 || p.Proof > ProofSRP6a:
+		return fmt.Errorf("params %q: unknown proof scheme %d", p.Name, p.Proof)
+	case p.KeyDerivation < KeyHash || p.KeyDerivation > KeyInterleave:
+		return fmt.Errorf("params %q: unknown key derivation %d", p.Name, p.KeyDerivation)
+	case p.Padding < PadRFC5054 || p.Padding > PadNone:
+		return fmt.Errorf("params %q: unknown padding %d", p.Name, p.Padding)
+	case p.SaltWidth < 0:
+		return fmt.Errorf("params %q: salt width cannot be negative", p.Name)
+	case p.Compat < CompatNone || p.Compat > CompatThinbus:
+		return fmt.Errorf("params %q: unknown compatibility mode %d", p.Name, p.Compat)
+	case p.SessionKeyFormat < SessionKeyRaw || p.SessionKeyFormat > SessionKey32:
+		return fmt.Errorf("params %q: unknown session key format %d", p.Name, p.SessionKeyFormat)
+	case p.Normalization < NormalizeNFKD || p.Normalization > NormalizePRECIS:
+		return fmt.Errorf("params %q: unknown normalization %d", p.Name, p.Normalization)
+	}
+	return nil
+}
+
+// ValidateStrict is like [Params.Validate], but also rejects
+// params that are not recommended for production use: groups
+// shorter than 2048 bits (e.g. [RFC5054Group1024] and
+// [RFC5054Group1536]), and SHA-1 or weaker hashes.
+func (p *Params) ValidateStrict() error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if bits := p.Group.N.BitLen(); bits < minStrictGroupBits {
+		return fmt.Errorf("params %q: %d-bit group is shorter than %d bits", p.Name, bits, minStrictGroupBits)
+	}
+	switch p.Hash {
+	case crypto.MD4, crypto.MD5, crypto.SHA1, crypto.MD5SHA1:
+		return fmt.Errorf("params %q: hash %v is too weak", p.Name, p.Hash)
+	}
+	return nil
+}
+
+// WithStrictParams validates params with
+// [Params.ValidateStrict] instead of [Params.Validate] when
+// creating a client or a server. It does not need to match on
+// both sides.
+func WithStrictParams() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// validateParams returns an error if params cannot be used
+// with o.
+func (o *options) validateParams(params *Params) error {
+	if o.strict {
+		return params.ValidateStrict()
+	}
+	return params.Validate()
+}
+
 // Group represents a Diffie-Hellman group.
 type Group struct {
 	ID           string
@@ -135,6 +396,48 @@ type Group struct {
 	ExponentSize int
 }
 
+// minGroupBits is the minimum length of N accepted
+// by [Group.Validate].
+const minGroupBits = 1024
+
+// primalityRounds is the number of Miller-Rabin rounds
+// used to check that N is a safe prime, in addition to
+// the Baillie-PSW test performed by [big.Int.ProbablyPrime].
+const primalityRounds = 4
+
+// Validate returns an error if g is not a usable
+// Diffie-Hellman group.
+//
+// N must be a safe prime of at least 1024 bits (i.e.
+// (N-1)/2 must be prime as well), the generator must
+// lie in [2, N-2], and the exponent size must be positive
+// and shorter than N.
+func (g *Group) Validate() error {
+	if g.N == nil || g.Generator == nil {
+		return errors.New("group is incomplete")
+	}
+	if g.N.BitLen() < minGroupBits {
+		return fmt.Errorf("N must be at least %d bits long", minGroupBits)
+	}
+
+	nMinusOne := new(big.Int).Sub(g.N, bigOne)
+	if g.Generator.Cmp(bigOne) <= 0 || g.Generator.Cmp(nMinusOne) >= 0 {
+		return errors.New("generator must be in [2, N-2]")
+	}
+	if g.ExponentSize <= 0 || g.ExponentSize >= (g.N.BitLen()+7)/8 {
+		return errors.New("exponent size must be positive and shorter than N")
+	}
+
+	if !g.N.ProbablyPrime(primalityRounds) {
+		return errors.New("N is not prime")
+	}
+	q := nMinusOne.Rsh(nMinusOne, 1)
+	if !q.ProbablyPrime(primalityRounds) {
+		return errors.New("N is not a safe prime")
+	}
+	return nil
+}
+
 // Diffie-Hellman group 2.
 //
 // Deprecated: This group is not recommended
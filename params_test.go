@@ -0,0 +1,98 @@
+package srp
+
+import (
+	"crypto"
+	"math/big"
+	"strings"
+	"testing"
+
+	_ "crypto/sha256"
+)
+
+func TestParamsValidate(t *testing.T) {
+	valid := func() *Params {
+		return &Params{
+			Name:  "test",
+			Group: RFC5054Group2048,
+			Hash:  crypto.SHA256,
+			KDF:   RFC5054KDF,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *Params)
+		err    string // Empty if p is valid
+		strict string // Empty if p is valid in strict mode
+	}{
+		{"Valid", func(p *Params) {}, "", ""},
+		{"NilGroup", func(p *Params) { p.Group = nil }, "group is incomplete", "group is incomplete"},
+		{"IncompleteGroup", func(p *Params) { p.Group = &Group{N: RFC5054Group2048.N} }, "group is incomplete", "group is incomplete"},
+		{"ShortGroup", func(p *Params) { p.Group = &Group{N: big.NewInt(23), Generator: big.NewInt(5)} }, "shorter than 1024 bits", "shorter than 1024 bits"},
+		{"UnavailableHash", func(p *Params) { p.Hash = crypto.BLAKE2b_256 }, "not available", "not available"},
+		{"ZeroHash", func(p *Params) { p.Hash = 0 }, "not available", "not available"},
+		{"NilKDF", func(p *Params) { p.KDF = nil }, "KDF cannot be nil", "KDF cannot be nil"},
+		{"UnknownProof", func(p *Params) { p.Proof = 42 }, "unknown proof scheme", "unknown proof scheme"},
+		{"UnknownKeyDerivation", func(p *Params) { p.KeyDerivation = -1 }, "unknown key derivation", "unknown key derivation"},
+		{"UnknownPadding", func(p *Params) { p.Padding = 42 }, "unknown padding", "unknown padding"},
+		{"NegativeSaltWidth", func(p *Params) { p.SaltWidth = -1 }, "salt width cannot be negative", "salt width cannot be negative"},
+		{"UnknownCompat", func(p *Params) { p.Compat = 42 }, "unknown compatibility mode", "unknown compatibility mode"},
+		{"UnknownSessionKeyFormat", func(p *Params) { p.SessionKeyFormat = 42 }, "unknown session key format", "unknown session key format"},
+		{"SHA1", func(p *Params) { p.Hash = crypto.SHA1 }, "", "too weak"},
+		{"Group1024", func(p *Params) { p.Group = RFC5054Group1024 }, "", "shorter than 2048 bits"},
+		{"Group1536", func(p *Params) { p.Group = RFC5054Group1536 }, "", "shorter than 2048 bits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.modify(p)
+
+			assertValidationError(t, "Validate", p.Validate(), tt.err)
+			assertValidationError(t, "ValidateStrict", p.ValidateStrict(), tt.strict)
+		})
+	}
+
+	var nilParams *Params
+	if err := nilParams.Validate(); err == nil {
+		t.Fatal("expected nil params to be rejected")
+	}
+}
+
+// assertValidationError fails t if err does not contain
+// wanted, or is not nil when wanted is empty.
+func assertValidationError(t *testing.T, name string, err error, wanted string) {
+	t.Helper()
+
+	switch {
+	case wanted == "" && err != nil:
+		t.Fatalf("%s: unexpected error: %v", name, err)
+	case wanted != "" && err == nil:
+		t.Fatalf("%s: expected an error containing %q", name, wanted)
+	case wanted != "" && !strings.Contains(err.Error(), wanted):
+		t.Fatalf("%s: expected an error containing %q, got %v", name, wanted, err)
+	}
+}
+
+func TestParamsValidateOnCreation(t *testing.T) {
+	invalid := &Params{Group: RFC5054Group2048, Hash: crypto.SHA256}
+	if _, err := NewClient(invalid, string(I), string(P), salt.Bytes()); err == nil {
+		t.Fatal("expected NewClient to reject params without a KDF")
+	}
+	if _, err := NewServer(invalid, string(I), salt.Bytes(), v.Bytes()); err == nil {
+		t.Fatal("expected NewServer to reject params without a KDF")
+	}
+	if _, err := NewFakeServer(invalid, string(I), fakeSecret); err == nil {
+		t.Fatal("expected NewFakeServer to reject params without a KDF")
+	}
+
+	// params use SHA-1 and a 1024-bit group.
+	if _, err := NewClient(params, string(I), string(P), salt.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(params, string(I), string(P), salt.Bytes(), WithStrictParams()); err == nil {
+		t.Fatal("expected NewClient to reject weak params in strict mode")
+	}
+	if _, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithStrictParams()); err == nil {
+		t.Fatal("expected NewServer to reject weak params in strict mode")
+	}
+}
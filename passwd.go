@@ -0,0 +1,192 @@
+package srp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"math/big"
+)
+
+// HKDF info used to derive the keys protecting the
+// triplets sent over an established session.
+const (
+	passwordChangeInfo = "srp password change"
+	migrationInfo      = "srp verifier migration"
+)
+
+// ErrPasswordChangeRejected is returned when a password
+// change or a verifier migration request cannot be
+// authenticated.
+var ErrPasswordChangeRejected = errors.New("password change request rejected")
+
+// ChangePassword returns a request to replace the password of
+// the user with newPassword, which the client sends to the
+// server over the established session.
+//
+// A new salt and a new verifier are computed with the params of
+// s, and encrypted with a key derived from the session key K.
+// Only the server that took part in the handshake can decrypt
+// the request, and a request that was tampered with, or created
+// for another session, is rejected by [Session.AcceptPasswordChange].
+//
+// The session keeps using the current password until the
+// client calls [Session.ConfirmPasswordChange], once the server
+// acknowledged the change. Until then, a new call replaces the
+// pending change.
+//
+// ChangePassword is called client-side, and runs the params'
+// key derivation function.
+func (s *Session) ChangePassword(newPassword string) ([]byte, error) {
+	s.mu.Lock()
+	params := s.params
+	s.mu.Unlock()
+
+	newSalt := params.NewSalt()
+	tp, err := ComputeVerifier(params, s.username, newPassword, newSalt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.verifier != nil {
+		return nil, errors.New("only the client can change the password")
+	}
+
+	request, err := s.sealTriplet(passwordChangeInfo, tp, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.pending = &sessionUpdate{info: passwordChangeInfo, params: params, salt: newSalt}
+	return request, nil
+}
+
+// ConfirmPasswordChange updates the session to the password
+// of the last request returned by [Session.ChangePassword],
+// which subsequent calls to [Session.Respond] must use.
+//
+// It must only be called once the server acknowledged the
+// change, i.e. once [Session.AcceptPasswordChange] succeeded:
+// until then, the server still expects the current password.
+// An error is returned if no password change is pending.
+//
+// ConfirmPasswordChange is called client-side.
+func (s *Session) ConfirmPasswordChange() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.confirm(passwordChangeInfo, "no pending password change")
+}
+
+// confirm updates s to its pending change if it was
+// requested for info, or returns an error with msg.
+//
+// The caller must hold s.mu.
+func (s *Session) confirm(info, msg string) error {
+	if s.pending == nil || s.pending.info != info {
+		return errors.New(msg)
+	}
+	s.params = s.pending.params
+	s.salt = s.pending.salt
+	s.pending = nil
+	return nil
+}
+
+// AcceptPasswordChange authenticates and decrypts a request
+// returned by [Session.ChangePassword], and returns the new
+// triplet of the user.
+//
+// The session is updated to the new verifier, so later challenges
+// must be answered with the new password. The server must replace
+// the stored triplet of the user with the one returned.
+//
+// The request is bound to the current salt of the user, so it
+// cannot be replayed once the password has been changed.
+//
+// AcceptPasswordChange is called server-side.
+func (s *Session) AcceptPasswordChange(request []byte) (Triplet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.verifier == nil {
+		return nil, errors.New("only the server can accept a password change")
+	}
+
+	tp, err := s.openTriplet(passwordChangeInfo, request, nil, s.params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.salt = tp.Salt()
+	s.verifier = tp.Verifier()
+	s.challenge = nil
+	return tp, nil
+}
+
+// sealTriplet encrypts tp with a key derived from the session
+// key for info, and authenticates it along with the current salt
+// of s and ad.
+//
+// The caller must hold s.mu.
+func (s *Session) sealTriplet(info string, tp Triplet, ad []byte) ([]byte, error) {
+	aead, err := s.tripletAEAD(info)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := randomKey(aead.NonceSize())
+	return aead.Seal(nonce, nonce, tp, s.additionalData(ad)), nil
+}
+
+// openTriplet decrypts a triplet sealed with sealTriplet, and
+// checks that it belongs to the user of s, and that its verifier
+// is valid for params.
+//
+// The caller must hold s.mu.
+func (s *Session) openTriplet(info string, request, ad []byte, params *Params) (Triplet, error) {
+	aead, err := s.tripletAEAD(info)
+	if err != nil {
+		return nil, err
+	}
+	if len(request) < aead.NonceSize() {
+		return nil, ErrPasswordChangeRejected
+	}
+
+	nonce, ciphertext := request[:aead.NonceSize()], request[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, s.additionalData(ad))
+	if err != nil {
+		return nil, ErrPasswordChangeRejected
+	}
+
+	tp := Triplet(plaintext)
+	if err := tp.validate(); err != nil {
+		return nil, err
+	}
+	if tp.Username() != s.username {
+		return nil, errors.New("triplet is for another user")
+	}
+	if !isValidEphemeralKey(params, new(big.Int).SetBytes(tp.Verifier())) {
+		return nil, errors.New("invalid verifier")
+	}
+	return tp, nil
+}
+
+// additionalData returns the current salt of s followed by ad.
+func (s *Session) additionalData(ad []byte) []byte {
+	return append(append([]byte{}, s.salt...), ad...)
+}
+
+// tripletAEAD returns the AEAD protecting the triplets
+// sent over s for info.
+func (s *Session) tripletAEAD(info string) (cipher.AEAD, error) {
+	key, err := hkdf(s.params.Hash, s.key, nil, []byte(info), 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,158 @@
+package srp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChangePassword(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	request, err := cs.ChangePassword("new password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp, err := ss.AcceptPasswordChange(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tp.Username() != string(I) {
+		t.Fatalf("wanted username %s, got %s", I, tp.Username())
+	}
+
+	// The new triplet authenticates the new password only.
+	for password, valid := range map[string]bool{"new password": true, string(P): false} {
+		client, err := NewClient(params, string(I), password, tp.Salt())
+		if err != nil {
+			t.Fatal(err)
+		}
+		server, err := NewServer(params, string(I), tp.Salt(), tp.Verifier())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := handshake(client, server); (err == nil) != valid {
+			t.Fatalf("password %q: unexpected result %v", password, err)
+		}
+	}
+
+	// Both sessions now use the new password.
+	if err := cs.ConfirmPasswordChange(); err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := cs.Respond(challenge, "new password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := ss.Verify(response); err != nil || !ok {
+		t.Fatalf("expected response to be valid, got %v, %v", ok, err)
+	}
+}
+
+func TestChangePasswordReplay(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	request, err := cs.ChangePassword("new password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ss.AcceptPasswordChange(request); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ss.AcceptPasswordChange(request); !errors.Is(err, ErrPasswordChangeRejected) {
+		t.Fatalf("expected ErrPasswordChangeRejected, got %v", err)
+	}
+}
+
+func TestChangePasswordTampered(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	request, err := cs.ChangePassword("new password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := append([]byte{}, request...)
+	tampered[len(tampered)-1] ^= 1
+	for _, r := range [][]byte{nil, request[:8], tampered} {
+		if _, err := ss.AcceptPasswordChange(r); !errors.Is(err, ErrPasswordChangeRejected) {
+			t.Fatalf("expected ErrPasswordChangeRejected, got %v", err)
+		}
+	}
+
+	// A request created in another session is rejected.
+	_, other := newSessions(t)
+	if _, err := other.AcceptPasswordChange(request); !errors.Is(err, ErrPasswordChangeRejected) {
+		t.Fatalf("expected ErrPasswordChangeRejected, got %v", err)
+	}
+}
+
+func TestChangePasswordRoles(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	if _, err := ss.ChangePassword("new password"); err == nil {
+		t.Fatal("expected the server to be unable to change the password")
+	}
+	request, err := cs.ChangePassword("new password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.AcceptPasswordChange(request); err == nil {
+		t.Fatal("expected the client to be unable to accept a password change")
+	}
+}
+
+func TestChangePasswordPending(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	if err := cs.ConfirmPasswordChange(); err == nil {
+		t.Fatal("expected an error without a pending password change")
+	}
+
+	respond := func(password string) bool {
+		t.Helper()
+		challenge, err := ss.Challenge()
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := cs.Respond(challenge, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := ss.Verify(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	// A request the server did not accept leaves
+	// the client on the current password.
+	if _, err := cs.ChangePassword("lost password"); err != nil {
+		t.Fatal(err)
+	}
+	if !respond(string(P)) {
+		t.Fatal("expected the current password to remain valid")
+	}
+
+	// The last request replaces the pending one.
+	request, err := cs.ChangePassword("new password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ss.AcceptPasswordChange(request); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.ConfirmPasswordChange(); err != nil {
+		t.Fatal(err)
+	}
+	if !respond("new password") {
+		t.Fatal("expected the new password to be valid")
+	}
+	if err := cs.ConfirmPasswordChange(); err == nil {
+		t.Fatal("expected a password change to be confirmed only once")
+	}
+}
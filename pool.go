@@ -0,0 +1,145 @@
+package srp
+
+import (
+	"math/big"
+	"sync"
+)
+
+// ephemeralPair holds a private ephemeral key x and
+// the matching power of the generator, g^x % N.
+type ephemeralPair struct {
+	x, gx *big.Int
+}
+
+// EphemeralPool pre-generates the private ephemeral keys of
+// servers in a background goroutine, so that a server drawing
+// from the pool does not compute a modular exponentiation
+// when it is created.
+//
+// Only g^b % N is pre-computed: the public key B, which
+// depends on the verifier of the user, is still computed by
+// the server at creation, which is cheap.
+//
+// A pool is only used by servers configured with
+// [WithEphemeralPool] and the same group. When the pool is
+// empty, servers generate their key pair themselves. An
+// EphemeralPool is safe for concurrent use by multiple
+// goroutines.
+type EphemeralPool struct {
+	params *Params
+	opts   options
+	pairs  chan ephemeralPair
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex // Guards err
+	err error      // Error that stopped the pool
+
+	closeOnce sync.Once
+}
+
+// NewEphemeralPool returns a new EphemeralPool holding up to
+// size key pairs for params, and starts filling it.
+//
+// If opts contain [WithAdditionalEntropy], the reader is only
+// used by the background goroutine of the pool, and its output
+// is mixed into every key pair of the pool. Call Close to stop
+// the goroutine when the pool is no longer needed.
+func NewEphemeralPool(params *Params, size int, opts ...Option) *EphemeralPool {
+	if size < 1 {
+		size = 1
+	}
+
+	p := &EphemeralPool{
+		params: params,
+		opts:   newOptions(opts),
+		pairs:  make(chan ephemeralPair, size),
+		done:   make(chan struct{}),
+	}
+	p.wg.Add(1)
+	go p.fill()
+	return p
+}
+
+// fill generates key pairs until p is closed, or until
+// generating one fails, in which case the error is
+// reported by p.Err.
+func (p *EphemeralPool) fill() {
+	defer p.wg.Done()
+
+	for {
+		x, gx, err := newClientKeyPair(p.params, p.opts.entropy)
+		if err != nil {
+			p.mu.Lock()
+			p.err = err
+			p.mu.Unlock()
+			return
+		}
+
+		select {
+		case p.pairs <- ephemeralPair{x, gx}:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// take returns a key pair for params from p, or false
+// if p is empty or was created for a different group.
+func (p *EphemeralPool) take(params *Params) (ephemeralPair, bool) {
+	if !sameGroup(p.params.Group, params.Group) {
+		return ephemeralPair{}, false
+	}
+
+	select {
+	case pair := <-p.pairs:
+		return pair, true
+	default:
+		return ephemeralPair{}, false
+	}
+}
+
+// Len returns the number of key pairs available in p.
+func (p *EphemeralPool) Len() int {
+	return len(p.pairs)
+}
+
+// Err returns the error that stopped p from generating key
+// pairs, e.g. when reading the additional entropy failed, or
+// nil if p is still filling or was closed.
+//
+// A failed pool is not refilled: servers draw the key pairs
+// left in it, then generate their own.
+func (p *EphemeralPool) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Close stops the background goroutine of p, and waits
+// for it to return.
+//
+// Key pairs already generated remain available to servers.
+func (p *EphemeralPool) Close() {
+	p.closeOnce.Do(func() { close(p.done) })
+	p.wg.Wait()
+}
+
+// WithEphemeralPool configures a server to draw its private
+// ephemeral key b from pool, instead of generating it when the
+// server is created or reset.
+//
+// Servers whose group differs from that of the pool ignore it.
+// The key pairs of the pool are generated with the additional
+// entropy of the pool (see [NewEphemeralPool]), not with that
+// of the server: a server configured with [WithAdditionalEntropy]
+// only mixes its entropy into the key pairs it generates itself,
+// when the pool is empty.
+//
+// This option has no effect on clients, and does not need
+// to match on both sides.
+func WithEphemeralPool(pool *EphemeralPool) Option {
+	return func(o *options) {
+		o.pool = pool
+	}
+}
@@ -0,0 +1,132 @@
+package srp
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// waitForPool waits until pool holds n key pairs.
+func waitForPool(t *testing.T, pool *EphemeralPool, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Len() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool holds %d key pairs, wanted %d", pool.Len(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEphemeralPool(t *testing.T) {
+	pool := NewEphemeralPool(params, 4)
+	waitForPool(t, pool, 4)
+
+	// Stop refilling the pool to count the key pairs drawn.
+	pool.Close()
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithEphemeralPool(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Len() != 3 {
+		t.Fatalf("expected the server to draw a key pair from the pool")
+	}
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEphemeralPoolGroup(t *testing.T) {
+	pool := NewEphemeralPool(&Params{Group: RFC5054Group2048}, 1)
+	waitForPool(t, pool, 1)
+	pool.Close()
+
+	if _, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithEphemeralPool(pool)); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Len() != 1 {
+		t.Fatal("expected a pool of a different group to be ignored")
+	}
+}
+
+func TestEphemeralPoolClose(t *testing.T) {
+	pool := NewEphemeralPool(params, 1)
+	waitForPool(t, pool, 1)
+	pool.Close()
+	pool.Close()
+
+	// Servers fall back to generating their key pair
+	// when the pool is empty.
+	for i := 0; i < 2; i++ {
+		client, err := NewClient(params, string(I), string(P), salt.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithEphemeralPool(pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := handshake(client, server); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pool.Len() != 0 {
+		t.Fatalf("expected the pool to be empty, got %d key pairs", pool.Len())
+	}
+}
+
+func TestEphemeralPoolErr(t *testing.T) {
+	pool := NewEphemeralPool(params, 4, WithAdditionalEntropy(iotest.ErrReader(io.ErrUnexpectedEOF)))
+	pool.wg.Wait()
+
+	if err := pool.Err(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected the entropy error, got %v", err)
+	}
+	if pool.Len() != 0 {
+		t.Fatalf("expected the pool to be empty, got %d key pairs", pool.Len())
+	}
+	pool.Close()
+
+	healthy := NewEphemeralPool(params, 1)
+	waitForPool(t, healthy, 1)
+	healthy.Close()
+	if err := healthy.Err(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func BenchmarkNewServer(bench *testing.B) {
+	p := &Params{Group: RFC5054Group4096, Hash: params.Hash, KDF: params.KDF}
+
+	bench.Run("Default", func(bench *testing.B) {
+		for i := 0; i < bench.N; i++ {
+			if _, err := NewServer(p, string(I), salt.Bytes(), v.Bytes()); err != nil {
+				bench.Fatal(err)
+			}
+		}
+	})
+
+	bench.Run("Pool", func(bench *testing.B) {
+		pool := NewEphemeralPool(p, bench.N)
+		defer pool.Close()
+		for pool.Len() < bench.N {
+			time.Sleep(time.Millisecond)
+		}
+
+		bench.ResetTimer()
+		for i := 0; i < bench.N; i++ {
+			if _, err := NewServer(p, string(I), salt.Bytes(), v.Bytes(), WithEphemeralPool(pool)); err != nil {
+				bench.Fatal(err)
+			}
+		}
+	})
+}
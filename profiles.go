@@ -0,0 +1,129 @@
+package srp
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	_ "crypto/sha512" // Used by AppleProfile
+)
+
+// AppleProfile is a [Params] instance compatible with Apple's
+// implementation of SRP-6a, as used by the HomeKit Accessory
+// Protocol (HAP) for device pairing.
+//
+// It uses the 3072-bit group of [RFC5054], SHA-512, and
+// derives x with SHA-512 as following:
+//
+//	x = SHA512(s | SHA512(U | ":" | p))
+//
+// A and B are padded to the length of N when computing u,
+// but not when computing M1, which are the defaults of this
+// package.
+//
+// AppleProfile deliberately does not use PBKDF2: HAP derives
+// x from the password directly. Apple's other uses of SRP,
+// such as Apple ID authentication, stretch the password with
+// PBKDF2 before deriving x, and are not covered by this
+// profile.
+//
+// [RFC5054]: https://datatracker.ietf.org/doc/html/rfc5054
+var AppleProfile = &Params{
+	Name:  "apple-hap",
+	Group: RFC5054Group3072,
+	Hash:  crypto.SHA512,
+	KDF:   AppleKDF,
+}
+
+// AppleKDF is the [KDF] used by [AppleProfile].
+//
+//	x = SHA512(s | SHA512(U | ":" | p))
+func AppleKDF(username, password string, salt []byte) ([]byte, error) {
+	return rfc5054KDF(crypto.SHA512, username, password, salt)
+}
+
+// OnePasswordProfile is a [Params] instance compatible with
+// github.com/1Password/srp, to authenticate users against
+// verifiers created with that library.
+//
+// It uses the 4096-bit group of [RFC5054], SHA-256, and
+// the derivations described in [CompatOnePassword].
+//
+// The KDF of 1Password/srp's KDFRFC5054 is reproduced by
+// [RFC5054KDF]. Replace it with the KDF that was used to
+// compute x when the verifiers were created.
+//
+// [RFC5054]: https://datatracker.ietf.org/doc/html/rfc5054
+var OnePasswordProfile = &Params{
+	Name:   "1password",
+	Group:  RFC5054Group4096,
+	Hash:   crypto.SHA256,
+	KDF:    RFC5054KDF,
+	Compat: CompatOnePassword,
+}
+
+// ThinbusProfile is a [Params] instance compatible with
+// Thinbus, a JavaScript SRP-6a client, to authenticate
+// browser clients built on Thinbus without changing the
+// frontend.
+//
+// It uses the 2048-bit group of [RFC5054], SHA-256, the
+// derivations described in [CompatThinbus], and
+// [ThinbusKDF].
+//
+// Thinbus exchanges values as hexadecimal strings: use
+// [ParseThinbusHex] to decode the values received from the
+// client (A, M1, the verifier), and [ThinbusHex] to encode
+// those sent to it (B, M2).
+//
+// [RFC5054]: https://datatracker.ietf.org/doc/html/rfc5054
+var ThinbusProfile = &Params{
+	Name:   "thinbus",
+	Group:  RFC5054Group2048,
+	Hash:   crypto.SHA256,
+	KDF:    ThinbusKDF,
+	Compat: CompatThinbus,
+}
+
+// ThinbusKDF is the [KDF] used by [ThinbusProfile].
+//
+//	x = SHA256(upper(s | hex(SHA256(U | ":" | p))))
+//
+// where the digests are represented as lowercase hexadecimal
+// strings without leading zeros, and upper converts a string
+// to uppercase.
+//
+// Thinbus salts are strings. salt must therefore be the salt
+// string as sent to the client, not its decoded value.
+func ThinbusKDF(username, password string, salt []byte) ([]byte, error) {
+	h := sha256.New()
+	h.Write([]byte(username + ":" + password))
+	inner := strings.TrimLeft(hex.EncodeToString(h.Sum(nil)), "0")
+
+	h.Reset()
+	h.Write([]byte(strings.ToUpper(string(salt) + inner)))
+	return h.Sum(nil), nil
+}
+
+// ThinbusHex returns b as a lowercase hexadecimal string
+// without leading zeros, the way Thinbus encodes values.
+func ThinbusHex(b []byte) string {
+	return string(hexBytes(new(big.Int).SetBytes(b)))
+}
+
+// ParseThinbusHex decodes a hexadecimal string sent by a
+// Thinbus client, such as A, M1 or a verifier.
+//
+// s may have an odd length and contain uppercase letters.
+// The result has no leading zeros, which is how this package
+// represents public keys and proofs.
+func ParseThinbusHex(s string) ([]byte, error) {
+	i, ok := new(big.Int).SetString(s, 16)
+	if !ok || i.Sign() < 0 || strings.HasPrefix(s, "+") {
+		return nil, fmt.Errorf("invalid hexadecimal value %q", s)
+	}
+	return i.Bytes(), nil
+}
@@ -0,0 +1,461 @@
+package srp
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// Test vectors for AppleProfile, as published in the SRP test
+// vectors of Apple's HomeKit Accessory Protocol Specification
+// (SRP-6a, 3072-bit group, SHA-512), which reuse the inputs of
+// RFC 5054 – Appendix B (I, P, s, a and b).
+var (
+	appleLittleK = mustParseHex(
+		"A9C2E255 9BF0EBB5 3F0CBBF6 2282906B EDE7F218 2F006782 11FBD5BD",
+		"E5B28503 3A499350 3B87397F 9BE5EC02 080FEDBC 0835587A D0390608",
+		"79B8621E 8C3659E0",
+	)
+	appleX = mustParseHex(
+		"B149ECB0 946B0B20 6D77E73D 95DEB7C4 1BD12E86 A5E2EEA3 893D5416",
+		"591A002F F94BFEA3 84DC0E1C 550F7ED4 D5A9D2AD 1F1526F0 1C56B5C1",
+		"0577730C C4A4D709",
+	)
+	appleV = mustParseHex(
+		"9B5E0617 01EA7AEB 39CF6E35 19655A85 3CF94C75 CAF2555E F1FAF759",
+		"BB79CB47 7014E04A 88D68FFC 05323891 D4C205B8 DE81C2F2 03D8FAD1",
+		"B24D2C10 9737F1BE BBD71F91 2447C4A0 3C26B9FA D8EDB3E7 80778E30",
+		"2529ED1E E138CCFC 36D4BA31 3CC48B14 EA8C22A0 186B222E 655F2DF5",
+		"603FD75D F76B3B08 FF895006 9ADD03A7 54EE4AE8 8587CCE1 BFDE3679",
+		"4DBAE459 2B7B904F 442B041C B17AEBAD 1E3AEBE3 CBE99DE6 5F4BB1FA",
+		"00B0E7AF 06863DB5 3B02254E C66E781E 3B62A821 2C86BEB0 D50B5BA6",
+		"D0B478D8 C4E9BBCE C2176532 6FBD1405 8D2BBDE2 C33045F0 3873E539",
+		"48D78B79 4F0790E4 8C36AED6 E880F557 427B2FC0 6DB5E1E2 E1D7E661",
+		"AC482D18 E528D729 5EF74372 95FF1A72 D4027717 13F16876 DD050AE5",
+		"B7AD53CC B90855C9 39566483 58ADFD96 6422F524 98732D68 D1D7FBEF",
+		"10D78034 AB8DCB6F 0FCF885C C2B2EA2C 3E6AC866 09EA058A 9DA8CC63",
+		"531DC915 414DF568 B09482DD AC1954DE C7EB714F 6FF7D44C D5B86F6B",
+		"D1158109 30637C01 D0F6013B C9740FA2 C633BA89",
+	)
+	appleA = mustParseHex(
+		"FAB6F5D2 615D1E32 3512E799 1CC37443 F487DA60 4CA8C923 0FCB04E5",
+		"41DCE628 0B27CA46 80B0374F 179DC3BD C7553FE6 2459798C 701AD864",
+		"A91390A2 8C93B644 ADBF9C00 745B942B 79F9012A 21B9B787 82319D83",
+		"A1F83628 66FBD6F4 6BFC0DDB 2E1AB6E4 B45A9906 B82E37F0 5D6F97F6",
+		"A3EB6E18 2079759C 4F684783 7B62321A C1B4FA68 641FCB4B B98DD697",
+		"A0C73641 385F4BAB 25B79358 4CC39FC8 D48D4BD8 67A9A3C1 0F8EA121",
+		"70268E34 FE3BBE6F F89998D6 0DA2F3E4 283CBEC1 393D52AF 724A5723",
+		"0C604E9F BCE583D7 613E6BFF D67596AD 121A8707 EEC46944 95703368",
+		"6A155F64 4D5C5863 B48F61BD BF19A53E AB6DAD0A 186B8C15 2E5F5D8C",
+		"AD4B0EF8 AA4EA500 8834C3CD 342E5E0F 167AD045 92CD8BD2 79639398",
+		"EF9E114D FAAAB919 E14E8509 89224DDD 98576D79 385D2210 902E9F9B",
+		"1F2D86CF A47EE244 635465F7 1058421A 0184BE51 DD10CC9D 079E6F16",
+		"04E7AA9B 7CF7883C 7D4CE12B 06EBE160 81E23F27 A231D184 32D7D1BB",
+		"55C28AE2 1FFCF005 F57528D1 5A88881B B3BBB7FE",
+	)
+	appleB = mustParseHex(
+		"40F57088 A482D4C7 733384FE 0D301FDD CA9080AD 7D4F6FDF 09A01006",
+		"C3CB6D56 2E41639A E8FA21DE 3B5DBA75 85B27558 9BDB2798 63C56280",
+		"7B2B9908 3CD1429C DBE89E25 BFBD7E3C AD3173B2 E3C5A0B1 74DA6D53",
+		"91E6A06E 465F037A 40062548 39A56BF7 6DA84B1C 94E0AE20 8576156F",
+		"E5C140A4 BA4FFC9E 38C3B07B 88845FC6 F7DDDA93 381FE0CA 6084C4CD",
+		"2D336E54 51C464CC B6EC65E7 D16E548A 273E8262 84AF2559 B6264274",
+		"215960FF F47BDD63 D3AFF064 D6137AF7 69661C9D 4FEE4738 2603C88E",
+		"AA098058 1D077584 61B777E4 356DDA58 35198B51 FEEA308D 70F75450",
+		"B71675C0 8C7D8302 FD7539DD 1FF2A11C B4258AA7 0D234436 AA42B6A0",
+		"615F3F91 5D55CC3B 966B2716 B36E4D1A 06CE5E5D 2EA3BEE5 A1270E87",
+		"51DA45B6 0B997B0F FDB0F996 2FEE4F03 BEE780BA 0A845B1D 92714217",
+		"83AE6601 A61EA2E3 42E4F2E8 BC935A40 9EAD19F2 21BD1B74 E2964DD1",
+		"9FC845F6 0EFC0933 8B60B6B2 56D8CAC8 89CCA306 CC370A0B 18C8B886",
+		"E95DA0AF 5235FEF4 393020D2 B7F30569 04759042",
+	)
+	appleU = mustParseHex(
+		"03AE5F3C 3FA9EFF1 A50D7DBB 8D2F60A1 EA66EA71 2D50AE97 6EE34641",
+		"A1CD0E51 C4683DA3 83E8595D 6CB56A15 D5FBC754 3E07FBDD D316217E",
+		"01A391A1 8EF06DFF",
+	)
+	appleS = mustParseHex(
+		"F1036FEC D017C823 9C0D5AF7 E0FCF0D4 08B009E3 6411618A 60B23AAB",
+		"BFC38339 72682312 14BAACDC 94CA1C53 F442FB51 C1B027C3 18AE238E",
+		"16414D60 D1881B66 486ADE10 ED02BA33 D098F6CE 9BCF1BB0 C46CA2C4",
+		"7F2F174C 59A9C61E 2560899B 83EF6113 1E6FB30B 714F4E43 B735C9FE",
+		"6080477C 1B83E409 3E4D456B 9BCA492C F9339D45 BC42E67C E6C02C24",
+		"3E49F5DA 42A869EC 855780E8 4207B8A1 EA6501C4 78AAC0DF D3D22614",
+		"F531A00D 826B7954 AE8B14A9 85A42931 5E6DD366 4CF47181 496A9432",
+		"9CDE8005 CAE63C2F 9CA4969B FE840019 24037C44 6559BDBB 9DB9D4DD",
+		"142FBCD7 5EEF2E16 2C843065 D99E8F05 762C4DB7 ABD9DB20 3D41AC85",
+		"A58C05BD 4E2DBF82 2A934523 D54E0653 D376CE8B 56DCB452 7DDDC1B9",
+		"94DC7509 463A7468 D7F02B1B EB168571 4CE1DD1E 71808A13 7F788847",
+		"B7C6B7BF A1364474 B3B7E894 78954F6A 8E68D45B 85A88E4E BFEC1336",
+		"8EC0891C 3BC86CF5 00978801 78D86135 E7287234 58538858 D715B7B2",
+		"47406222 C1019F53 603F0169 52D49710 0858824C",
+	)
+	appleSessionKey = mustParseHex(
+		"5CBC219D B052138E E1148C71 CD449896 3D682549 CE91CA24 F098468F",
+		"06015BEB 6AF245C2 093F98C3 651BCA83 AB8CAB2B 580BBF02 184FEFDF",
+		"26142F73 DF95AC50",
+	)
+	appleM1 = mustParseHex(
+		"5F7C14AB 57ED0E94 FD1D78C6 B4DD09ED 7E340B7E 05D419A9 FD760F6B",
+		"35E523D1 310777A1 AE1D2826 F596F3A8 5116CC45 7C7C964D 4F44DED5",
+		"559DA818 C88B617F",
+	)
+	appleM2 = mustParseHex(
+		"2FA0E81F 5CB73B88 FA096427 0F321DD6 41F2227A 5D805C40 F1BFE96A",
+		"AF6A19FF CE8E2328 7965A39E AB9D5A02 215F89E1 28177ED2 C4F103E6",
+		"55A04553 1BCBF7AD",
+	)
+)
+
+func TestAppleProfile(t *testing.T) {
+	params := AppleProfile
+
+	gotK, err := computeLittleK(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "k", appleLittleK.Bytes(), gotK.Bytes())
+
+	gotX, err := params.KDF(string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "x", appleX.Bytes(), gotX)
+
+	tp, err := ComputeVerifier(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "v", appleV.Bytes(), tp.Verifier())
+
+	gotU, err := computeLittleU(params, appleA, appleB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "u", appleU.Bytes(), gotU.Bytes())
+
+	gotS, err := computeServerS(params, appleV, appleU, appleA, b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "S (server)", appleS.Bytes(), gotS.Bytes())
+
+	gotS, err = computeClientS(params, appleLittleK, appleX, appleU, appleB, a, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "S (client)", appleS.Bytes(), gotS.Bytes())
+
+	gotSessionKey, err := computeK(params, appleS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "K", appleSessionKey.Bytes(), gotSessionKey)
+
+	gotM1, err := computeM1(params, I, salt.Bytes(), appleA, appleB, appleS, gotSessionKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "M1", appleM1.Bytes(), gotM1.Bytes())
+
+	gotM2, err := computeM2(params, appleA, gotM1, appleS, gotSessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "M2", appleM2.Bytes(), gotM2.Bytes())
+}
+
+func TestAppleProfileSession(t *testing.T) {
+	tp, err := ComputeVerifier(AppleProfile, "Pair-Setup", "123-45-678", NewSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(AppleProfile, "Pair-Setup", "123-45-678", tp.Salt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(AppleProfile, tp.Username(), tp.Salt(), tp.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOnePasswordProfile(t *testing.T) {
+	params := OnePasswordProfile
+	_, A, _ := newClientKeyPair(params, nil)
+	_, B, _ := newClientKeyPair(params, nil)
+
+	// k = SHA256(N | g)
+	h := sha256.New()
+	h.Write(params.Group.N.Bytes())
+	h.Write(params.Group.Generator.Bytes())
+	gotK, err := computeLittleK(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "k", h.Sum(nil), gotK.FillBytes(make([]byte, sha256.Size)))
+
+	// u = SHA256(hex(A) | hex(B))
+	h.Reset()
+	h.Write([]byte(fmt.Sprintf("%x%x", A, B)))
+	gotU, err := computeLittleU(params, A, B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "u", h.Sum(nil), gotU.FillBytes(make([]byte, sha256.Size)))
+
+	// K = SHA256(hex(S))
+	S := new(big.Int).SetBytes([]byte{0x00, 0x0a, 0xbc})
+	h.Reset()
+	h.Write([]byte("abc"))
+	gotSessionKey, err := computeK(params, S)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "K", h.Sum(nil), gotSessionKey)
+}
+
+func TestOnePasswordProfileSession(t *testing.T) {
+	tp, err := ComputeVerifier(OnePasswordProfile, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(OnePasswordProfile, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(OnePasswordProfile, tp.Username(), tp.Salt(), tp.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Test vectors for ThinbusProfile, computed with an independent
+// implementation of the formulas of the Thinbus JavaScript client
+// from the inputs of RFC 5054 – Appendix B (I, P, a and b), and
+// the salt string thinbusSalt.
+//
+// k matches the value Thinbus publishes for the 2048-bit group
+// with SHA-256 (k_base16).
+var (
+	thinbusSalt    = "beb25379d1a8581eb5a727673a2441ee"
+	thinbusLittleK = mustParseHex(
+		"05B9E8EF 059C6B32 EA59FC1D 322D37F0 4AA30BAE 5AA9003B 8321E21D",
+		"DB04E300",
+	)
+	thinbusX = mustParseHex(
+		"42841CA6 2B5D4A1F A610A221 58A4EF5C 7FB15E7E 799F9942 A446551F",
+		"980D5E20",
+	)
+	thinbusV = mustParseHex(
+		"3317BA50 D003EED1 B1B4DE48 3BF1BBD0 27FDCA4B 6BB101FE 90BDC990",
+		"CA849A70 C18C9767 65255068 15F5D4B6 C39D86D5 4C38C9C2 EE9448EF",
+		"D34E71BF BD1CCB7D F622EF5F 4779D58E 9CAFF9E1 5E55CC70 5A075945",
+		"6650EB74 4C8E3883 C6B37680 7CDBCA7A D437CD95 97945DCC 9D75A721",
+		"5C718A5F 87066FEB 38AF6AA3 466004BC ADCB5F84 7DD6DD7E 2C7E73EE",
+		"7D8D1684 1F6A2D67 A84469BD 708FAABD 4CA25BBE 4FE49F35 FBE98F3E",
+		"696940A6 0E95A2B3 7111DEDE 2C24F598 3CFA3424 ED4B4F86 2C69A0D8",
+		"59BDD833 F058AD99 9C6824C7 DBF341B4 18732B5F 86B2116D 9AC0A19E",
+		"6AFA877C 60A62311 019E8C18 317B1F31 11E08E2E 192769F0 7457B3B5",
+		"CD856DAB",
+	)
+	thinbusA = mustParseHex(
+		"4B700F8D 48E69C9A AE40C684 AC7C7C03 121E2B76 02EB4C35 14804CCA",
+		"DA0ED401 9193A351 ECC65A6F 854EDE91 EB096E72 1B22D701 C7ADC64E",
+		"9CEDACD7 5F2E26BB 2F5E45DD 53DC8DBE AFFFE82A A49FCA05 73444691",
+		"212537A7 3CF80E25 03925820 5A7EDF47 49B30ADA F25877C6 2FCD09D6",
+		"613598BC D4BAF2A9 727A5370 6A278148 992B2ABB 23AD5D51 2D269E16",
+		"CA11BC08 95B5A3B5 EC4721CD E40A8C39 C796E94F 0BE86DBB EB33DA70",
+		"37018983 921ABA3F 5053195D 5AC1DA4E 567E3C0E 75D9E060 9F92E850",
+		"657B2BE4 771F415B 9CACC5C1 ECEDC301 33BF6474 F5022C65 19D78076",
+		"0CA4D8D3 B966B034 BD73877C 1B3B33F4 74B9C3C5 299A1968 F3E6CD3B",
+		"FE84445A",
+	)
+	thinbusB = mustParseHex(
+		"49BEC1F1 73E4818E 78B2EB72 3088B468 7EFC4040 54DFB988 3187D93A",
+		"3B3863FC E00C3954 B6510EA6 A9F90017 9BF9676C 128C6AA3 5228BBBA",
+		"8C3B5ED0 EBD1145E 1C59EE0C 51913459 6F9DE9C8 2B66CA3E 15053AC3",
+		"2F3A71DF 7A51B92D C4C137E3 3A761921 84570CCB E74BDB3F 11B6D509",
+		"B4AAF07B BBBD54C1 FFB326D3 F9C53128 F5295480 B371F764 71F819A2",
+		"B285488F 4D34C8DC 4A6D8BC4 4D142845 3C7A776D 05521E6D 67E109D9",
+		"AA02CAB9 2DF24124 3D078803 779B9F44 54939FB0 6E5EB4B4 879DDE86",
+		"FB2AB2A3 C7CABDC9 12467525 8C5CED60 32996EAD EEC298CB F305F63E",
+		"93DE9B32 97C7B67C 6BF87A3D 7EDE44D7 9FFDF41F FD03B777 2C22C48F",
+		"8F9593FC",
+	)
+	thinbusU = mustParseHex(
+		"D4837492 9D6E8FC8 1CE2ECA1 A4151D6B 667EC308 7260AAE8 842773EF",
+		"374494D9",
+	)
+	thinbusS = mustParseHex(
+		"5A326F08 F287C520 B3D2DEF3 CEFAFD83 34198EDD 02CBA298 63FB0D92",
+		"0777308C 1E185A90 9159F4B4 71CF0560 0A9359FA 7EA46CFC B58A5129",
+		"161D3144 0BE73C64 DE745F61 1FFB5D02 92085786 B622E8E5 07E86AF0",
+		"A002541F DE3EEBF0 ED8D5ED7 EE726390 A7D29CD9 6D59ECC9 D7C31148",
+		"3047F240 69451617 EAF013AD 2020FFD1 10B82CB4 58FDBD87 D04AF3BD",
+		"F11B51BF 02FF573A 2FA90A45 1796A67D F19177F5 E9448831 6A403455",
+		"CD94F5E6 605A9FD5 FEE4F61F 0D110B3E A46F82A6 C9302EB8 8E07E47B",
+		"8D160A1D FD919D71 0C78CEB0 74062357 B08754F9 C3C0D7FD 322C55B1",
+		"E610E55F 03B88911 51C9255B EDF3F4D2 FB3ADA78 6EE31F62 52C802F8",
+		"77D28F76",
+	)
+	thinbusSessionKey = mustParseHex(
+		"D5BD30D9 C1690E86 D4857C44 A709089F C74B5F4D 80437A03 ABB25654",
+		"874C9128",
+	)
+	thinbusM1 = mustParseHex(
+		"7EBB9CD3 72F3FA66 641C2DF1 9FD60EEE CCAC19ED 7EC30339 2ADE3F63",
+		"6F15FE6F",
+	)
+	thinbusM2 = mustParseHex(
+		"9A5F7344 8E034C0D 04F9097D 841C6323 DD0EBCB3 3AA67F77 6A78FD19",
+		"9ED5AFC5",
+	)
+)
+
+func TestThinbusProfile(t *testing.T) {
+	params := ThinbusProfile
+
+	gotK, err := computeLittleK(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "k", thinbusLittleK.Bytes(), gotK.Bytes())
+
+	gotX, err := params.KDF(string(I), string(P), []byte(thinbusSalt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "x", thinbusX.Bytes(), gotX)
+
+	tp, err := ComputeVerifier(params, string(I), string(P), []byte(thinbusSalt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "v", thinbusV.Bytes(), tp.Verifier())
+
+	gotA := new(big.Int).Exp(params.Group.Generator, a, params.Group.N)
+	assertEqualBytes(t, "A", thinbusA.Bytes(), gotA.Bytes())
+
+	gotU, err := computeLittleU(params, thinbusA, thinbusB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "u", thinbusU.Bytes(), gotU.Bytes())
+
+	gotS, err := computeServerS(params, thinbusV, thinbusU, thinbusA, b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "S (server)", thinbusS.Bytes(), gotS.Bytes())
+
+	gotS, err = computeClientS(params, thinbusLittleK, thinbusX, thinbusU, thinbusB, a, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "S (client)", thinbusS.Bytes(), gotS.Bytes())
+
+	gotSessionKey, err := computeK(params, thinbusS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "K", thinbusSessionKey.Bytes(), gotSessionKey)
+
+	gotM1, err := computeM1(params, I, []byte(thinbusSalt), thinbusA, thinbusB, thinbusS, gotSessionKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "M1", thinbusM1.Bytes(), gotM1.Bytes())
+
+	gotM2, err := computeM2(params, thinbusA, gotM1, thinbusS, gotSessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "M2", thinbusM2.Bytes(), gotM2.Bytes())
+}
+
+// TestThinbusServer authenticates a client that exchanges
+// hexadecimal strings, as a Thinbus browser client does.
+func TestThinbusServer(t *testing.T) {
+	params := ThinbusProfile
+
+	// Registration: the browser sends the salt and the
+	// verifier as hexadecimal strings.
+	tp, err := ComputeVerifier(params, "alice", "password123", []byte(thinbusSalt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierHex := ThinbusHex(tp.Verifier())
+	verifier, err := ParseThinbusHex(verifierHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServer(params, "alice", []byte(thinbusSalt), verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(params, "alice", "password123", []byte(thinbusSalt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Login: B and M2 are sent as hexadecimal strings,
+	// A and M1 are received as such.
+	roundTrip := func(b []byte) []byte {
+		t.Helper()
+		decoded, err := ParseThinbusHex(ThinbusHex(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return decoded
+	}
+
+	if err := client.SetB(roundTrip(server.B())); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(roundTrip(client.A())); err != nil {
+		t.Fatal(err)
+	}
+	M1, err := client.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := server.CheckM1(roundTrip(M1)); err != nil || !ok {
+		t.Fatalf("expected M1 to be verified, got %v, %v", ok, err)
+	}
+	M2, err := server.ComputeM2()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := client.CheckM2(roundTrip(M2)); err != nil || !ok {
+		t.Fatalf("expected M2 to be verified, got %v, %v", ok, err)
+	}
+}
+
+func TestThinbusHex(t *testing.T) {
+	if got := ThinbusHex([]byte{0x00, 0x0a, 0xbc}); got != "abc" {
+		t.Fatalf("wanted abc, got %s", got)
+	}
+
+	b, err := ParseThinbusHex("ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "value", []byte{0x0a, 0xbc}, b)
+
+	for _, s := range []string{"", "xyz", "-1", "+1", "0x1"} {
+		if _, err := ParseThinbusHex(s); err == nil {
+			t.Fatalf("expected %q to be rejected", s)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package srp
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// registry holds the params registered with Register,
+// in order of registration.
+var registry struct {
+	sync.RWMutex
+	params []*Params
+}
+
+// ErrUnknownParams is returned when no params or group
+// match the requested identifier.
+var ErrUnknownParams = errors.New("unknown params")
+
+// Register makes params available to [ListParams] and
+// [Lookup].
+//
+// An error is returned if params are incomplete, if params
+// with the same name were already registered, or if their
+// group ID is already used by a different group.
+func Register(params *Params) error {
+	if params == nil {
+		return errors.New("params cannot be nil")
+	}
+	if params.Name == "" {
+		return errors.New("params must have a name to be registered")
+	}
+	if params.Group == nil || params.Group.N == nil || params.Group.Generator == nil || params.KDF == nil || !params.Hash.Available() {
+		return fmt.Errorf("params %q are incomplete", params.Name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, p := range registry.params {
+		if p.Name == params.Name {
+			return fmt.Errorf("params %q are already registered", params.Name)
+		}
+	}
+	if g := lookupGroup(params.Group.ID); g != nil && !sameGroup(g, params.Group) {
+		return fmt.Errorf("group ID %q is already used by a different group", params.Group.ID)
+	}
+	registry.params = append(registry.params, params)
+	return nil
+}
+
+// ParamsInfo describes a [Params] instance.
+type ParamsInfo struct {
+	Name       string `json:"name"`
+	GroupID    string `json:"groupID"`
+	GroupBits  int    `json:"groupBits"`
+	Hash       string `json:"hash"`
+	KDF        string `json:"kdf"`
+	Deprecated bool   `json:"deprecated"`
+}
+
+// Info returns a description of p.
+//
+// p is reported as deprecated if it uses a group, a hash
+// or a key derivation function that is not recommended
+// for production use.
+func (p *Params) Info() ParamsInfo {
+	return ParamsInfo{
+		Name:       p.Name,
+		GroupID:    p.Group.ID,
+		GroupBits:  p.Group.N.BitLen(),
+		Hash:       p.Hash.String(),
+		KDF:        kdfName(p.KDF),
+		Deprecated: p.deprecated(),
+	}
+}
+
+// ListParams returns the description of all the params
+// registered with [Register], in order of registration.
+func ListParams() []ParamsInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	infos := make([]ParamsInfo, len(registry.params))
+	for i, p := range registry.params {
+		infos[i] = p.Info()
+	}
+	return infos
+}
+
+// Lookup returns the params registered with [Register]
+// under the given name.
+//
+// ErrUnknownParams is returned if no params were
+// registered under that name.
+func Lookup(name string) (*Params, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, p := range registry.params {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownParams, name)
+}
+
+// LookupGroup returns the group identified by id on the
+// wire (e.g. "14", "16", "ffdhe3072").
+//
+// The groups predefined by this package are searched first,
+// then the groups of the params registered with [Register].
+// ErrUnknownParams is returned if no group matches id.
+func LookupGroup(id string) (*Group, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	if g := lookupGroup(id); g != nil {
+		return g, nil
+	}
+	return nil, fmt.Errorf("%w: group %q", ErrUnknownParams, id)
+}
+
+// lookupGroup returns the group identified by id, or nil.
+//
+// The caller must hold a lock on the registry.
+func lookupGroup(id string) *Group {
+	for _, g := range groups {
+		if g.ID == id {
+			return g
+		}
+	}
+	for _, p := range registry.params {
+		if p.Group.ID == id {
+			return p.Group
+		}
+	}
+	return nil
+}
+
+// sameGroup returns true if a and b define the same group.
+func sameGroup(a, b *Group) bool {
+	return a.N.Cmp(b.N) == 0 && a.Generator.Cmp(b.Generator) == 0
+}
+
+// deprecated returns true if p uses a group, a hash or a
+// key derivation function that is not recommended for
+// production use.
+func (p *Params) deprecated() bool {
+	switch p.Group {
+	case RFC5054Group1024, RFC5054Group1536:
+		return true
+	}
+	switch p.Hash {
+	case crypto.MD4, crypto.MD5, crypto.SHA1, crypto.MD5SHA1:
+		return true
+	}
+	return sameFunc(p.KDF, RFC5054KDF)
+}
+
+// kdfName returns the name of the function kdf,
+// without its package path.
+func kdfName(kdf KDF) string {
+	if kdf == nil {
+		return ""
+	}
+	f := runtime.FuncForPC(reflect.ValueOf(kdf).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+
+	name := path.Base(f.Name())
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// sameFunc returns true if a and b are the same function.
+func sameFunc(a, b KDF) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
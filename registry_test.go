@@ -0,0 +1,136 @@
+package srp
+
+import (
+	"crypto"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// resetRegistry empties the registry when t ends.
+func resetRegistry(t *testing.T) {
+	t.Helper()
+
+	registry.Lock()
+	saved := registry.params
+	registry.params = nil
+	registry.Unlock()
+
+	t.Cleanup(func() {
+		registry.Lock()
+		registry.params = saved
+		registry.Unlock()
+	})
+}
+
+func TestRegister(t *testing.T) {
+	resetRegistry(t)
+
+	if err := Register(AppleProfile); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(AppleProfile); err == nil {
+		t.Fatal("expected duplicate params to be rejected")
+	}
+
+	invalid := []*Params{
+		nil,
+		{Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF},
+		{Name: "no-group", Hash: crypto.SHA1, KDF: RFC5054KDF},
+		{Name: "no-kdf", Group: RFC5054Group1024, Hash: crypto.SHA1},
+		{Name: "no-hash", Group: RFC5054Group1024, KDF: RFC5054KDF},
+	}
+	for _, p := range invalid {
+		if err := Register(p); err == nil {
+			t.Fatalf("expected %v to be rejected", p)
+		}
+	}
+}
+
+func TestListParams(t *testing.T) {
+	resetRegistry(t)
+
+	legacy := &Params{Name: "legacy", Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF}
+	for _, p := range []*Params{AppleProfile, legacy} {
+		if err := Register(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	infos := ListParams()
+	wanted := []ParamsInfo{
+		{Name: "apple-hap", GroupID: "15", GroupBits: 3072, Hash: "SHA-512", KDF: "AppleKDF", Deprecated: false},
+		{Name: "legacy", GroupID: "2", GroupBits: 1024, Hash: "SHA-1", KDF: "RFC5054KDF", Deprecated: true},
+	}
+	if len(infos) != len(wanted) {
+		t.Fatalf("expected %d params, got %d", len(wanted), len(infos))
+	}
+	for i := range wanted {
+		if infos[i] != wanted[i] {
+			t.Fatalf("wanted %+v, got %+v", wanted[i], infos[i])
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	resetRegistry(t)
+
+	if err := Register(AppleProfile); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Lookup("apple-hap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != AppleProfile {
+		t.Fatalf("wanted %v, got %v", AppleProfile, p)
+	}
+
+	if _, err := Lookup("unknown"); !errors.Is(err, ErrUnknownParams) {
+		t.Fatalf("expected ErrUnknownParams, got %v", err)
+	}
+}
+
+func TestLookupGroup(t *testing.T) {
+	resetRegistry(t)
+
+	for _, g := range groups {
+		got, err := LookupGroup(g.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != g {
+			t.Fatalf("wanted group %s, got %s", g.ID, got.ID)
+		}
+	}
+
+	if _, err := LookupGroup("99"); !errors.Is(err, ErrUnknownParams) {
+		t.Fatalf("expected ErrUnknownParams, got %v", err)
+	}
+
+	custom := &Group{ID: "custom", Generator: big.NewInt(2), N: FFDHE2048.N, ExponentSize: 29}
+	if err := Register(&Params{Name: "custom", Group: custom, Hash: crypto.SHA256, KDF: RFC5054KDF}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LookupGroup("custom"); err != nil || got != custom {
+		t.Fatalf("expected registered group to be found, got %v, %v", got, err)
+	}
+}
+
+func TestRegisterConflictingGroupID(t *testing.T) {
+	resetRegistry(t)
+
+	// Same ID as RFC5054Group2048, but a different prime.
+	conflicting := &Group{ID: "14", Generator: big.NewInt(2), N: FFDHE2048.N, ExponentSize: 29}
+	err := Register(&Params{Name: "conflicting", Group: conflicting, Hash: crypto.SHA256, KDF: RFC5054KDF})
+	if err == nil {
+		t.Fatal("expected conflicting group ID to be rejected")
+	}
+
+	// An identical group under the same ID is accepted.
+	identical := &Group{ID: "14", Generator: big.NewInt(2), N: RFC5054Group2048.N, ExponentSize: 27}
+	if err := Register(&Params{Name: "identical", Group: identical, Hash: crypto.SHA256, KDF: RFC5054KDF}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,178 @@
+package srp
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Errors returned when a value of the handshake fails
+// a sanity check.
+var (
+	// ErrInvalidPublicKey is returned when a public ephemeral
+	// key is a multiple of N, or shares a factor with N.
+	//
+	// [ErrPublicKeyTooLong] and [ErrPublicKeyOutOfRange] wrap
+	// it, so that errors.Is(err, ErrInvalidPublicKey) reports
+	// all invalid public keys.
+	ErrInvalidPublicKey = errors.New("invalid public ephemeral key")
+
+	// ErrPublicKeyTooLong is returned when the encoding of a
+	// public ephemeral key is longer than N. Such keys are
+	// rejected before being decoded, so that hostile peers
+	// cannot cause large allocations.
+	ErrPublicKeyTooLong = fmt.Errorf("%w: longer than N", ErrInvalidPublicKey)
+
+	// ErrPublicKeyOutOfRange is returned when a public
+	// ephemeral key is not in [2, N-2]. 0, 1 and N-1 would
+	// force the premaster secret to a value known in advance.
+	ErrPublicKeyOutOfRange = fmt.Errorf("%w: not in [2, N-2]", ErrInvalidPublicKey)
+
+	// ErrSmallPublicKey is returned when a public ephemeral
+	// key is shorter than the minimum configured with
+	// [WithMinPublicKeyBits].
+	ErrSmallPublicKey = errors.New("public ephemeral key is too small")
+
+	// ErrZeroU is returned when the scrambling parameter u
+	// is zero, which would let an attacker impersonate the
+	// client without knowing the password.
+	ErrZeroU = errors.New("scrambling parameter u is zero")
+)
+
+// SanityCheck identifies a check performed on the values
+// of a handshake.
+type SanityCheck int
+
+// Available sanity checks.
+const (
+	// CheckPublicKey fails when a received public key is
+	// invalid. It always causes the handshake to fail with
+	// [ErrInvalidPublicKey].
+	CheckPublicKey SanityCheck = iota + 1
+
+	// CheckSmallPublicKey fails when a received public key is
+	// shorter than the minimum configured with
+	// [WithMinPublicKeyBits], and causes the handshake to fail
+	// with [ErrSmallPublicKey].
+	CheckSmallPublicKey
+
+	// CheckZeroU fails when u is zero. It always causes the
+	// handshake to fail with [ErrZeroU].
+	CheckZeroU
+
+	// CheckSmallU fails when u is shorter than the threshold
+	// configured with [WithMinUBits]. It is only reported, and
+	// does not cause the handshake to fail.
+	CheckSmallU
+)
+
+// String returns the name of c.
+func (c SanityCheck) String() string {
+	switch c {
+	case CheckPublicKey:
+		return "public key"
+	case CheckSmallPublicKey:
+		return "small public key"
+	case CheckZeroU:
+		return "zero u"
+	case CheckSmallU:
+		return "small u"
+	default:
+		return fmt.Sprintf("SanityCheck(%d)", int(c))
+	}
+}
+
+// SanityEvent describes a failed sanity check.
+type SanityEvent struct {
+	Check    SanityCheck
+	Rejected bool // True if the handshake was aborted
+}
+
+// WithMinPublicKeyBits rejects received public ephemeral keys
+// that are shorter than bits, with [ErrSmallPublicKey].
+//
+// Honest public keys are uniformly distributed in [1, N-1], so
+// a threshold well below the length of N (e.g. half of it)
+// only rejects degenerate values.
+func WithMinPublicKeyBits(bits int) Option {
+	return func(o *options) {
+		o.minPublicKeyBits = bits
+	}
+}
+
+// WithMinUBits reports values of u shorter than bits to the
+// hook configured with [WithSanityHook], as a [CheckSmallU]
+// event. Such values do not cause the handshake to fail.
+func WithMinUBits(bits int) Option {
+	return func(o *options) {
+		o.minUBits = bits
+	}
+}
+
+// WithSanityHook configures f to be called whenever a sanity
+// check fails, e.g. to count them in metrics.
+//
+// f is called synchronously, and must not block.
+func WithSanityHook(f func(SanityEvent)) Option {
+	return func(o *options) {
+		o.sanityHook = f
+	}
+}
+
+// checkPublicKey decodes the public key received from the
+// peer, and returns an error if it fails a sanity check.
+func (o *options) checkPublicKey(params *Params, public PublicKey) (*big.Int, error) {
+	X, err := decodePublicKey(params, public)
+	if err != nil {
+		o.reportSanity(CheckPublicKey, true)
+		return nil, err
+	}
+	if o.minPublicKeyBits > 0 && X.BitLen() < o.minPublicKeyBits {
+		o.reportSanity(CheckSmallPublicKey, true)
+		return nil, ErrSmallPublicKey
+	}
+	return X, nil
+}
+
+// decodePublicKey decodes b, and returns an error if it is
+// not a valid public ephemeral key for params.
+//
+// The length of b is checked before b is decoded. Encodings
+// padded with zeros to the length of N are accepted.
+func decodePublicKey(params *Params, b []byte) (*big.Int, error) {
+	if len(b) > (params.Group.N.BitLen()+7)/8 {
+		return nil, ErrPublicKeyTooLong
+	}
+
+	X := new(big.Int).SetBytes(b)
+	nMinusOne := new(big.Int).Sub(params.Group.N, bigOne)
+	if X.Cmp(bigOne) <= 0 || X.Cmp(nMinusOne) >= 0 {
+		return nil, ErrPublicKeyOutOfRange
+	}
+	if !isValidEphemeralKey(params, X) {
+		return nil, ErrInvalidPublicKey
+	}
+	return X, nil
+}
+
+// checkU returns an error if u fails a sanity check.
+func (o *options) checkU(u *big.Int) error {
+	if u.Sign() == 0 {
+		o.reportSanity(CheckZeroU, true)
+		return ErrZeroU
+	}
+	if o.minUBits > 0 && u.BitLen() < o.minUBits {
+		o.reportSanity(CheckSmallU, false)
+	}
+	return nil
+}
+
+// reportSanity calls the sanity hook of o, if any.
+func (o *options) reportSanity(check SanityCheck, rejected bool) {
+	if o.sanityHook != nil {
+		o.sanityHook(SanityEvent{
+			Check:    check,
+			Rejected: rejected,
+		})
+	}
+}
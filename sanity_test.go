@@ -0,0 +1,178 @@
+package srp
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// recordSanity returns an option recording the
+// sanity events in events.
+func recordSanity(events *[]SanityEvent) Option {
+	return WithSanityHook(func(e SanityEvent) {
+		*events = append(*events, e)
+	})
+}
+
+func TestSanityInvalidPublicKey(t *testing.T) {
+	var events []SanityEvent
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), recordSanity(&events))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.SetA(params.Group.N.Bytes()); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Fatalf("expected ErrInvalidPublicKey, got %v", err)
+	}
+	wanted := SanityEvent{Check: CheckPublicKey, Rejected: true}
+	if len(events) != 1 || events[0] != wanted {
+		t.Fatalf("wanted %+v, got %+v", wanted, events)
+	}
+}
+
+func TestSanityMaliciousPublicKeys(t *testing.T) {
+	var (
+		N         = params.Group.N
+		size      = len(N.Bytes())
+		nMinusOne = new(big.Int).Sub(N, bigOne)
+		padded    = A.FillBytes(make([]byte, size))
+	)
+
+	tests := []struct {
+		name   string
+		public []byte
+		err    error // Nil if public is valid
+	}{
+		{"Empty", nil, ErrPublicKeyOutOfRange},
+		{"Zero", []byte{0}, ErrPublicKeyOutOfRange},
+		{"PaddedZero", make([]byte, size), ErrPublicKeyOutOfRange},
+		{"One", []byte{1}, ErrPublicKeyOutOfRange},
+		{"NMinusOne", nMinusOne.Bytes(), ErrPublicKeyOutOfRange},
+		{"N", N.Bytes(), ErrPublicKeyOutOfRange},
+		{"NPlusOne", new(big.Int).Add(N, bigOne).Bytes(), ErrPublicKeyOutOfRange},
+		{"AllOnes", bytes.Repeat([]byte{0xff}, size), ErrPublicKeyOutOfRange},
+		{"TwoN", new(big.Int).Lsh(N, 1).Bytes(), ErrPublicKeyTooLong},
+		{"ExtraLeadingZero", append([]byte{0}, padded...), ErrPublicKeyTooLong},
+		{"Huge", make([]byte, 1<<20), ErrPublicKeyTooLong},
+		{"Two", []byte{2}, nil},
+		{"NMinusTwo", new(big.Int).Sub(N, big.NewInt(2)).Bytes(), nil},
+		{"Valid", A.Bytes(), nil},
+		{"Padded", padded, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []SanityEvent
+
+			server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), recordSanity(&events))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, err := NewClient(params, string(I), string(P), salt.Bytes(), recordSanity(&events))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := map[string]error{
+				"SetA": server.SetA(tt.public),
+				"SetB": client.SetB(tt.public),
+			}
+			if len(tt.public) > 0 {
+				// NewPublicKey rejects empty keys with another error.
+				_, errs["NewPublicKey"] = NewPublicKey(params, tt.public)
+			}
+
+			for name, err := range errs {
+				if tt.err == nil {
+					if err != nil {
+						t.Fatalf("%s: unexpected error: %v", name, err)
+					}
+					continue
+				}
+				if !errors.Is(err, tt.err) || !errors.Is(err, ErrInvalidPublicKey) {
+					t.Fatalf("%s: wanted %v, got %v", name, tt.err, err)
+				}
+			}
+
+			if tt.err != nil && len(events) != 2 {
+				t.Fatalf("expected 2 sanity events, got %+v", events)
+			}
+		})
+	}
+}
+
+func TestSanitySmallPublicKey(t *testing.T) {
+	var (
+		events []SanityEvent
+		small  = big.NewInt(2).Bytes()
+		opts   = []Option{WithMinPublicKeyBits(512), recordSanity(&events)}
+	)
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(small); !errors.Is(err, ErrSmallPublicKey) {
+		t.Fatalf("expected ErrSmallPublicKey, got %v", err)
+	}
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetB(small); !errors.Is(err, ErrSmallPublicKey) {
+		t.Fatalf("expected ErrSmallPublicKey, got %v", err)
+	}
+
+	wanted := SanityEvent{Check: CheckSmallPublicKey, Rejected: true}
+	if len(events) != 2 || events[0] != wanted || events[1] != wanted {
+		t.Fatalf("wanted 2 x %+v, got %+v", wanted, events)
+	}
+
+	// Without the option, small keys are accepted.
+	server, err = NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(small); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSanityZeroU(t *testing.T) {
+	var events []SanityEvent
+	o := newOptions([]Option{recordSanity(&events)})
+
+	if err := o.checkU(new(big.Int)); !errors.Is(err, ErrZeroU) {
+		t.Fatalf("expected ErrZeroU, got %v", err)
+	}
+	wanted := SanityEvent{Check: CheckZeroU, Rejected: true}
+	if len(events) != 1 || events[0] != wanted {
+		t.Fatalf("wanted %+v, got %+v", wanted, events)
+	}
+}
+
+func TestSanitySmallU(t *testing.T) {
+	var (
+		events []SanityEvent
+		opts   = []Option{WithMinUBits(params.Hash.Size()*8 + 1), recordSanity(&events)}
+	)
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A small u is reported but does not abort the handshake.
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+	wanted := SanityEvent{Check: CheckSmallU, Rejected: false}
+	if len(events) != 2 || events[0] != wanted || events[1] != wanted {
+		t.Fatalf("wanted 2 x %+v, got %+v", wanted, events)
+	}
+}
@@ -1,107 +1,307 @@
 package srp
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"sync"
+	"time"
 )
 
 // ErrServerNoReady is returned when the server
 // is not ready for the invoked action.
 var ErrServerNoReady = errors.New("client's public ephemeral key (A) must be set first")
 
+// ErrServerRekeyRequired is returned when a client's public
+// ephemeral key (A) is set on a server whose key pair was
+// already used with another one.
+var ErrServerRekeyRequired = errors.New("server's public ephemeral key (B) was used with another A, call Rekey first")
+
+// errProofChecked is returned when the ephemeral keys of
+// a server are changed after the client proof was checked.
+var errProofChecked = errors.New("client proof was already checked")
+
 // serverState holds information that allows
 // a server instance to be restored.
 type serverState struct {
-	Triplet    []byte `json:"triplet"`
-	LittleB    []byte `json:"b"`
-	BigB       []byte `json:"B"`
-	BigA       []byte `json:"A,omitempty"`
-	VerifiedM1 bool   `json:"verifiedM1"`
+	Params     string   `json:"params,omitempty"`
+	Group      string   `json:"group,omitempty"`
+	Triplet    []byte   `json:"triplet"`
+	LittleB    []byte   `json:"b"`
+	BigB       []byte   `json:"B"`
+	BigA       []byte   `json:"A,omitempty"`
+	VerifiedM1 bool     `json:"verifiedM1"`
+	Offer      []string `json:"offer,omitempty"`
+	Legacy     bool     `json:"legacy,omitempty"`
+	Fake       bool     `json:"fake,omitempty"`
+	Started    int64    `json:"started,omitempty"`
+
+	ChannelBinding []byte `json:"channelBinding,omitempty"`
 }
 
 // Server represents the server-side perspective of an SRP
 // session.
+//
+// A Server is safe for concurrent use by multiple goroutines.
 type Server struct {
-	triplet    Triplet  // User information
-	xA         *big.Int // Client public ephemeral
-	b          *big.Int // Server private ephemeral
-	xB         *big.Int // Server public ephemeral
-	m1         *big.Int // Client proof
-	m2         *big.Int // Server proof
-	xS         *big.Int // Pre-master key
-	xK         []byte   // Session key
-	params     *Params  // Params combination
-	err        error    // Tracks any systemic errors
-	verifiedM1 bool     // Tracks if the client proof was successfully checked
+	mu sync.Mutex // Guards all the fields below
+
+	triplet    Triplet   // User information
+	xA         *big.Int  // Client public ephemeral
+	b          *big.Int  // Server private ephemeral
+	xB         *big.Int  // Server public ephemeral
+	m1         *big.Int  // Client proof
+	m2         *big.Int  // Server proof
+	xS         *big.Int  // Pre-master key
+	xK         []byte    // Session key
+	params     *Params   // Params combination
+	opts       options   // Optional features
+	err        error     // Tracks any systemic errors
+	verifiedM1 bool      // Tracks if the client proof was successfully checked
+	fake       bool      // Always rejects the client proof
+	start      time.Time // Time the handshake started
+
+	legacy        *serverProofs // Values derived with legacy params
+	legacyMatched bool          // Tracks if the client proof matched the legacy form
 }
 
 // SetA configures the public ephemeral key
-// (B) of this server.
-func (s *Server) SetA(public []byte) error {
-	A := new(big.Int).SetBytes(public)
-	if !isValidEphemeralKey(s.params, A) {
-		return errors.New("invalid public exponent")
+// (A) of the client.
+//
+// Setting the same A again has no effect, e.g. when a client
+// retransmits it. A different A is only accepted after a call
+// to [Server.Rekey], so that the key pair (b, B) is never used
+// with two of them: [ErrServerRekeyRequired] is returned
+// otherwise, or another error once the client proof has been
+// checked.
+func (s *Server) SetA(public PublicKey) error {
+	return s.SetAContext(context.Background(), public)
+}
+
+// SetAContext is like [Server.SetA], but returns early with
+// the error of ctx if ctx is done before the premaster secret
+// is computed. The server is left unchanged in that case.
+//
+// The modular exponentiation cannot be interrupted: it keeps
+// running in the background until it returns, and its result
+// is discarded.
+func (s *Server) SetAContext(ctx context.Context, public PublicKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.opts.reportError(s.event(), s.setA(ctx, public))
+}
+
+// setA implements SetAContext. The caller must hold s.mu.
+func (s *Server) setA(ctx context.Context, public PublicKey) error {
+	A, err := s.opts.checkPublicKey(s.params, public)
+	if err != nil {
+		return err
+	}
+	if s.xA != nil {
+		switch {
+		case s.xA.Cmp(A) == 0:
+			return nil
+		case s.verifiedM1 || s.err != nil:
+			return errProofChecked
+		default:
+			return ErrServerRekeyRequired
+		}
 	}
 
+	p, err := s.computeProofs(ctx, s.params, A)
+	if err != nil {
+		return err
+	}
+
+	var legacy *serverProofs
+	if s.opts.legacy != nil {
+		if legacy, err = s.computeProofs(ctx, s.opts.legacy, A); err != nil {
+			return err
+		}
+	}
+
+	s.opts.recordTranscript(func(r *TranscriptRecorder) {
+		r.recordInt("A", &r.t.BigA, A)
+		r.recordInt("u", &r.t.U, p.u)
+		r.recordInt("S", &r.t.S, p.xS)
+		r.recordBytes("K", &r.t.K, p.xK)
+		r.recordInt("M1", &r.t.M1, p.m1)
+		r.recordInt("M2", &r.t.M2, p.m2)
+	})
+
+	s.legacy = legacy
+	s.xA = A
+	s.m1 = p.m1
+	s.m2 = p.m2
+	s.xS = p.xS
+	s.xK = p.xK
+	return nil
+}
+
+// serverProofs holds the values a server derives
+// from the client's public ephemeral key A.
+type serverProofs struct {
+	u  *big.Int // Scrambling parameter
+	m1 *big.Int // Client proof
+	m2 *big.Int // Server proof
+	xS *big.Int // Pre-master key
+	xK []byte   // Session key
+}
+
+// computeProofs computes the values s derives from A,
+// using params.
+func (s *Server) computeProofs(ctx context.Context, params *Params, A *big.Int) (*serverProofs, error) {
 	var (
 		username = []byte(s.triplet.Username())
 		salt     = s.triplet.Salt()
 		v        = new(big.Int).SetBytes(s.triplet.Verifier())
 	)
 
-	u, err := computeLittleU(s.params, A, s.xB)
+	u, err := computeLittleU(params, A, s.xB)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := s.opts.checkU(u); err != nil {
+		return nil, err
 	}
 
-	S, err := computeServerS(s.params, v, u, A, s.b)
+	var (
+		S        *big.Int
+		b        = s.b
+		hardened = s.opts.hardened
+	)
+	err = runContext(ctx, func() (err error) {
+		S, err = computeServerS(params, v, u, A, b, hardened)
+		return
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	K := s.params.hashBytes(S.Bytes())
+	K, err := computeK(params, S)
+	if err != nil {
+		return nil, err
+	}
 
-	M1, err := computeM1(s.params, username, salt, A, s.xB, K)
+	M1, err := computeM1(params, username, salt, A, s.xB, S, K, s.opts.binding(params))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	M2, err := computeM2(s.params, A, M1, K)
+	M2, err := computeM2(params, A, M1, S, K)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	s.xA = A
-	s.m1 = M1
-	s.m2 = M2
-	s.xS = S
-	s.xK = K
-	return nil
+	return &serverProofs{
+		u:  u,
+		m1: M1,
+		m2: M2,
+		xS: S,
+		xK: K,
+	}, nil
 }
 
 // B returns the server's public ephemeral key B.
-func (s *Server) B() []byte {
+func (s *Server) B() PublicKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	return s.xB.Bytes()
 }
 
+// Rekey replaces the ephemeral key pair (b, B) of s with a
+// fresh one, and returns the new B, which must be sent to the
+// client.
+//
+// It lets a client retry with a new A (e.g. after a dropped
+// connection) without losing the user's triplet: the previous
+// A and the values derived from it are discarded, and
+// [Server.SetA] accepts a new A. A [TranscriptRecorder] only
+// records the first key pair of s.
+//
+// An error is returned once the client proof has been checked:
+// a verified proof completes the handshake, and a failed one
+// ends it.
+func (s *Server) Rekey() (PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	B, err := s.rekey()
+	return B, s.opts.reportError(s.event(), err)
+}
+
+// rekey implements Rekey. The caller must hold s.mu.
+func (s *Server) rekey() (PublicKey, error) {
+	if s.verifiedM1 || s.err != nil {
+		return nil, errProofChecked
+	}
+
+	k, err := computeLittleK(s.params)
+	if err != nil {
+		return nil, err
+	}
+	b, B, err := s.newKeyPair(s.params, k, new(big.Int).SetBytes(s.triplet.Verifier()))
+	if err != nil {
+		return nil, err
+	}
+
+	s.xA = nil
+	s.b, s.xB = b, B
+	s.m1 = nil
+	s.m2 = nil
+	s.xS = nil
+	s.xK = nil
+	s.legacy = nil
+	s.legacyMatched = false
+	return B.Bytes(), nil
+}
+
 // CheckM1 returns true if the client proof M1 is verified.
-func (s *Server) CheckM1(M1 []byte) (bool, error) {
+func (s *Server) CheckM1(M1 Proof) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.err != nil {
-		return false, s.err
+		return false, s.opts.reportError(s.event(), s.err)
 	}
 
 	if s.m1 == nil {
-		return false, ErrServerNoReady
+		return false, s.opts.reportError(s.event(), ErrServerNoReady)
+	}
+	username := s.triplet.Username()
+	done, err := s.opts.beginThrottle(username)
+	if err != nil {
+		return false, s.opts.reportError(s.event(), err)
 	}
 
-	if checkProof(s.m1.Bytes(), M1) {
+	var reason AuditReason
+	if s.fake {
+		// A fake server compares the proofs anyway,
+		// so that it takes as long as a real one.
+		checkProof(s.m1.Bytes(), M1)
+		s.verifiedM1 = false
+		s.err = errors.New("failed to verify client proof M1")
+		reason = AuditUnknownUser
+	} else if checkProof(s.m1.Bytes(), M1) {
+		s.verifiedM1 = true
+		reason = AuditVerified
+	} else if s.checkLegacyM1(M1) {
 		s.verifiedM1 = true
+		reason = AuditLegacyProof
 	} else {
 		s.verifiedM1 = false
 		s.err = errors.New("failed to verify client proof M1")
+		reason = AuditBadProof
 	}
 
+	e := s.event()
+	s.opts.reportProof(e, s.verifiedM1)
+	s.opts.audit(e, s.params, reason)
+	done(s.verifiedM1)
 	return s.verifiedM1, nil
 }
 
@@ -110,15 +310,18 @@ func (s *Server) CheckM1(M1 []byte) (bool, error) {
 //
 // An error is returned if the client's proof (M1) has
 // not been checked by calling the s.CheckM1 method first.
-func (s *Server) ComputeM2() ([]byte, error) {
+func (s *Server) ComputeM2() (Proof, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.err != nil {
-		return nil, s.err
+		return nil, s.opts.reportError(s.event(), s.err)
 	}
 	if s.m2 == nil {
-		return nil, ErrServerNoReady
+		return nil, s.opts.reportError(s.event(), ErrServerNoReady)
 	}
 	if !s.verifiedM1 {
-		return nil, errors.New("client must show their proof first")
+		return nil, s.opts.reportError(s.event(), errors.New("client must show their proof first"))
 	}
 	return s.m2.Bytes(), nil
 }
@@ -128,29 +331,46 @@ func (s *Server) ComputeM2() ([]byte, error) {
 //
 // An error is returned if the client's proof (M1) has
 // not been checked by calling the s.CheckM1 method first.
-func (s *Server) SessionKey() ([]byte, error) {
+func (s *Server) SessionKey() (SessionKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.err != nil {
-		return nil, s.err
+		return nil, s.opts.reportError(s.event(), s.err)
 	}
 	if s.xK == nil {
-		return nil, ErrServerNoReady
+		return nil, s.opts.reportError(s.event(), ErrServerNoReady)
 	}
 
-	return s.xK, nil
+	key, err := formatSessionKey(s.params, s.xK)
+	return key, s.opts.reportError(s.event(), err)
 }
 
 // MarshalJSON returns a JSON object representing
 // the current state of s.
 func (s *Server) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.err != nil {
 		return nil, s.err
 	}
 
 	state := &serverState{
+		Params:     s.params.Name,
+		Group:      s.params.Group.ID,
 		Triplet:    s.triplet,
 		LittleB:    s.b.Bytes(),
 		BigB:       s.xB.Bytes(),
 		VerifiedM1: s.verifiedM1,
+		Offer:      s.opts.offer,
+		Legacy:     s.legacyMatched,
+		Fake:       s.fake,
+
+		ChannelBinding: s.opts.channelBinding,
+	}
+	if !s.start.IsZero() {
+		state.Started = s.start.UnixNano()
 	}
 	if s.xA != nil {
 		state.BigA = s.xA.Bytes()
@@ -161,12 +381,28 @@ func (s *Server) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON restores from an existing state object
 // obtained with MarshalJSON.
+//
+// If s has no params, the registered params named in the
+// state are used (see [Register]). Otherwise, the params of s
+// must match those named in the state.
 func (s *Server) UnmarshalJSON(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	state := &serverState{}
 	if err := json.Unmarshal(data, state); err != nil {
 		return err
 	}
 
+	params, err := stateParams(s.params, state, s.opts.stateOverride)
+	if err != nil {
+		return err
+	}
+	if err := s.opts.validateParams(params); err != nil {
+		return err
+	}
+	s.params = params
+
 	s.triplet = nil
 	s.xA = nil
 	s.b = nil
@@ -177,14 +413,34 @@ func (s *Server) UnmarshalJSON(data []byte) error {
 	s.xK = nil
 	s.err = nil
 	s.verifiedM1 = false
+	s.fake = false
+	s.start = time.Time{}
+	s.legacy = nil
+	s.legacyMatched = false
 
 	s.triplet = state.Triplet
 	s.b = new(big.Int).SetBytes(state.LittleB)
 	s.xB = new(big.Int).SetBytes(state.BigB)
-	s.verifiedM1 = state.VerifiedM1
+	s.verifiedM1 = state.VerifiedM1 && !state.Fake
+	s.fake = state.Fake
+	if state.Started != 0 {
+		s.start = time.Unix(0, state.Started)
+	}
+	if state.Offer != nil {
+		s.opts.offer = state.Offer
+	}
+	s.opts.channelBinding = state.ChannelBinding
 
 	if state.BigA != nil {
-		return s.SetA(state.BigA)
+		if err := s.setA(context.Background(), state.BigA); err != nil {
+			return err
+		}
+		if state.Legacy {
+			if s.legacy == nil {
+				return errors.New("state requires legacy params")
+			}
+			s.useLegacy()
+		}
 	}
 
 	return nil
@@ -196,11 +452,51 @@ func (s *Server) Save() ([]byte, error) {
 	return s.MarshalJSON()
 }
 
+// stateParams returns the params to restore state with:
+// params if not nil, or the registered params named in state
+// otherwise.
+//
+// An error is returned if the params do not have the name and
+// the group recorded in state, or only the group if override is
+// true. States saved by older versions, which record neither,
+// are only checked against params.
+func stateParams(params *Params, state *serverState, override bool) (*Params, error) {
+	if params == nil {
+		if state.Params == "" {
+			return nil, errors.New("state does not name its params, which must be provided")
+		}
+		p, err := Lookup(state.Params)
+		if err != nil {
+			return nil, err
+		}
+		params = p
+	}
+
+	if state.Params != "" && state.Params != params.Name && !override {
+		return nil, fmt.Errorf("state was saved with params %q, not %q", state.Params, params.Name)
+	}
+	if state.Group != "" && params.Group != nil && state.Group != params.Group.ID {
+		return nil, fmt.Errorf("state was saved with group %q, not %q", state.Group, params.Group.ID)
+	}
+	return params, nil
+}
+
 // RestoreServer restores a server from a previous state obtained
 // with [Server.Save].
-func RestoreServer(params *Params, state []byte) (*Server, error) {
+//
+// The state records the name and the group of the params of
+// the server. If params is nil, they are looked up among the
+// params registered with [Register]. Otherwise, params are used
+// instead of the registry, but must still have the name and the
+// group recorded in the state, unless opts contain
+// [WithStateParamsOverride].
+//
+// The optional opts must match those the server was
+// originally created with.
+func RestoreServer(params *Params, state []byte, opts ...Option) (*Server, error) {
 	s := &Server{
 		params: params,
+		opts:   newOptions(opts),
 	}
 	if err := json.Unmarshal(state, s); err != nil {
 		return nil, err
@@ -208,16 +504,64 @@ func RestoreServer(params *Params, state []byte) (*Server, error) {
 	return s, nil
 }
 
+// WithStateParamsOverride lets [RestoreServer] restore a state
+// with params whose name differs from the one recorded in the
+// state, e.g. after the params were renamed.
+//
+// The params must still have the group recorded in the state,
+// and otherwise be identical to those the state was saved with:
+// the server cannot tell if they are not. This option only has
+// an effect on servers restored with params.
+func WithStateParamsOverride() Option {
+	return func(o *options) {
+		o.stateOverride = true
+	}
+}
+
+// ResetFromTriplet is like [Server.Reset], with the values
+// of triplet.
+//
+// An error is returned if triplet is mis-formatted, in which
+// case s is left unchanged.
+func (s *Server) ResetFromTriplet(params *Params, triplet Triplet) error {
+	if err := triplet.validate(); err != nil {
+		return err
+	}
+	return s.Reset(params, triplet.Username(), triplet.Salt(), triplet.Verifier())
+}
+
 // Reset resets s to its initial state.
 func (s *Server) Reset(params *Params, username string, salt, verifier []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.opts.validateParams(params); err != nil {
+		return err
+	}
+	if err := params.checkSalt(salt); err != nil {
+		return err
+	}
+	username, err := params.normalizeUsername(username)
+	if err != nil {
+		return err
+	}
+
 	k, err := computeLittleK(params)
 	if err != nil {
 		return err
 	}
+	if err := s.opts.checkLegacy(params, k); err != nil {
+		return err
+	}
+
+	b, B, err := s.newKeyPair(params, k, new(big.Int).SetBytes(verifier))
+	if err != nil {
+		return err
+	}
 
-	s.triplet = NewTriplet(NFKD(username), salt, verifier)
+	s.triplet = NewTriplet(username, salt, verifier)
 	s.xA = nil
-	s.b, s.xB = newServerKeyPair(params, k, new(big.Int).SetBytes(verifier))
+	s.b, s.xB = b, B
 	s.m1 = nil
 	s.m2 = nil
 	s.xS = nil
@@ -225,12 +569,67 @@ func (s *Server) Reset(params *Params, username string, salt, verifier []byte) e
 	s.params = params
 	s.err = nil
 	s.verifiedM1 = false
-
+	s.fake = false
+	s.start = now()
+	s.legacy = nil
+	s.legacyMatched = false
+
+	s.opts.recordTranscript(func(r *TranscriptRecorder) {
+		r.recordParams(params)
+		r.record("I", &r.t.Username, s.triplet.Username())
+		r.recordBytes("s", &r.t.Salt, salt)
+		r.recordInt("v", &r.t.Verifier, new(big.Int).SetBytes(verifier))
+		r.recordInt("k", &r.t.LittleK, k)
+		r.recordInt("b", &r.t.LittleB, b)
+		r.recordInt("B", &r.t.BigB, B)
+	})
+	s.opts.reportStart(s.event())
 	return nil
 }
 
+// event returns the event describing the current
+// state of the handshake of s.
+func (s *Server) event() HandshakeEvent {
+	var username string
+	if len(s.triplet) > 0 {
+		username = s.triplet.Username()
+	}
+	return newHandshakeEvent(RoleServer, s.params, username, s.start)
+}
+
+// newKeyPair returns a new ephemeral key pair (b, B) for s,
+// drawn from its pool if it has one.
+//
+// A pooled b already went through the entropy of the pool,
+// and g^b is precomputed, so the additional entropy of s is
+// not mixed into it.
+func (s *Server) newKeyPair(params *Params, k, v *big.Int) (b, B *big.Int, err error) {
+	if s.opts.pool != nil {
+		if pair, ok := s.opts.pool.take(params); ok {
+			return pair.x, serverPublicKey(params, k, v, pair.gx), nil
+		}
+	}
+	return newServerKeyPair(params, k, v, s.opts.entropy)
+}
+
 // NewServer returns a new SRP server instance.
-func NewServer(params *Params, username string, salt, verifier []byte) (*Server, error) {
-	s := &Server{}
+//
+// The optional opts must match those used by the client.
+func NewServer(params *Params, username string, salt, verifier []byte, opts ...Option) (*Server, error) {
+	s := &Server{
+		opts: newOptions(opts),
+	}
 	return s, s.Reset(params, username, salt, verifier)
 }
+
+// NewServerFromTriplet returns a new SRP server instance for
+// the user of triplet, typically retrieved from storage.
+//
+// An error is returned if triplet is mis-formatted. The
+// optional opts must match those used by the client.
+func NewServerFromTriplet(params *Params, triplet Triplet, opts ...Option) (*Server, error) {
+	s := &Server{
+		opts: newOptions(opts),
+	}
+	return s, s.ResetFromTriplet(params, triplet)
+}
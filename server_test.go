@@ -1,6 +1,11 @@
 package srp
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
 
 func TestRestoreServerJSON(t *testing.T) {
 	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
@@ -30,6 +35,93 @@ func TestRestoreServerJSON(t *testing.T) {
 	assertEqualBytes(t, "K", server.xK, restored.xK)
 }
 
+func TestRestoreServerParams(t *testing.T) {
+	resetRegistry(t)
+
+	named := &Params{Name: "test-1024", Group: params.Group, Hash: params.Hash, KDF: params.KDF}
+	if err := Register(named); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewServer(named, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(A.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	state, err := server.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The params are resolved from the registry.
+	restored, err := RestoreServer(nil, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.params != named {
+		t.Fatalf("wanted params %q, got %q", named, restored.params)
+	}
+	assertEqualBytes(t, "K", server.xK, restored.xK)
+
+	// Explicit params must match the state.
+	renamed := &Params{Name: "other", Group: params.Group, Hash: params.Hash, KDF: params.KDF}
+	if _, err := RestoreServer(renamed, state); err == nil {
+		t.Fatal("expected params with another name to be rejected")
+	}
+	regrouped := &Params{Name: named.Name, Group: RFC5054Group2048, Hash: params.Hash, KDF: params.KDF}
+	if _, err := RestoreServer(regrouped, state); err == nil {
+		t.Fatal("expected params with another group to be rejected")
+	}
+
+	// The name, but not the group, can be overridden.
+	restored, err = RestoreServer(renamed, state, WithStateParamsOverride())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.params != renamed {
+		t.Fatalf("wanted params %q, got %q", renamed, restored.params)
+	}
+	assertEqualBytes(t, "K", server.xK, restored.xK)
+	if _, err := RestoreServer(regrouped, state, WithStateParamsOverride()); err == nil {
+		t.Fatal("expected params with another group to be rejected")
+	}
+
+	// The params of an unnamed state must be provided.
+	server, err = NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err = server.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreServer(nil, state); err == nil {
+		t.Fatal("expected a state without params name to require params")
+	}
+
+	// States saved by older versions do not name their params.
+	old := []byte(`{"triplet":` + mustMarshalJSON(t, []byte(server.triplet)) + `,"b":` + mustMarshalJSON(t, server.b.Bytes()) + `,"B":` + mustMarshalJSON(t, server.xB.Bytes()) + `}`)
+	if _, err := RestoreServer(named, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreServer(nil, old); err == nil {
+		t.Fatal("expected an old state to require params")
+	}
+}
+
+// mustMarshalJSON returns the JSON encoding of v.
+func mustMarshalJSON(t *testing.T, v any) string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
 func TestServerReset(t *testing.T) {
 	s, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
 	if err != nil {
@@ -37,7 +129,7 @@ func TestServerReset(t *testing.T) {
 	}
 	s.SetA(A.Bytes())
 
-	M1, err := computeM1(params, I, salt.Bytes(), A, s.xB, s.xK)
+	M1, err := computeM1(params, I, salt.Bytes(), A, s.xB, s.xS, s.xK, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -57,3 +149,112 @@ func TestServerReset(t *testing.T) {
 		t.Fatal("expected M1 to not be verified")
 	}
 }
+
+func TestNewServerFromTriplet(t *testing.T) {
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	server, err := NewServerFromTriplet(params, tp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, invalid := range []Triplet{nil, {5, 'a'}, NewTriplet(string(I), salt.Bytes(), nil)} {
+		if _, err := NewServerFromTriplet(params, invalid); err == nil {
+			t.Fatalf("expected triplet %v to be rejected", invalid)
+		}
+		if err := server.ResetFromTriplet(params, invalid); err == nil {
+			t.Fatalf("expected triplet %v to be rejected", invalid)
+		}
+	}
+
+	// A rejected triplet leaves the server unchanged.
+	if server.triplet.Username() != string(I) || !server.verifiedM1 {
+		t.Fatal("expected the server to be unchanged")
+	}
+
+	other := NewTriplet("bob", salt.Bytes(), v.Bytes())
+	if err := server.ResetFromTriplet(params, other); err != nil {
+		t.Fatal(err)
+	}
+	if server.triplet.Username() != "bob" || server.verifiedM1 {
+		t.Fatal("expected the server to be reset")
+	}
+}
+
+func TestServerRekey(t *testing.T) {
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first attempt is interrupted after A was set.
+	first, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(first.A()); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(first.A()); err != nil {
+		t.Fatalf("expected a retransmitted A to be accepted, got %v", err)
+	}
+	B := server.B()
+
+	retry, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(retry.A()); !errors.Is(err, ErrServerRekeyRequired) {
+		t.Fatalf("expected ErrServerRekeyRequired, got %v", err)
+	}
+
+	newB, err := server.Rekey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(B, newB) {
+		t.Fatal("expected a fresh B")
+	}
+	assertEqualBytes(t, "B", newB, server.B())
+	if server.xA != nil || server.m1 != nil {
+		t.Fatal("expected the values derived from A to be discarded")
+	}
+	if err := handshake(retry, server); err != nil {
+		t.Fatal(err)
+	}
+
+	// The key pair cannot change once the proof was checked.
+	if _, err := server.Rekey(); err == nil {
+		t.Fatal("expected Rekey to fail after a verified proof")
+	}
+	if err := server.SetA(first.A()); err == nil {
+		t.Fatal("expected a new A to be rejected after a verified proof")
+	}
+	if err := server.SetA(retry.A()); err != nil {
+		t.Fatalf("expected a retransmitted A to be accepted, got %v", err)
+	}
+}
+
+func TestServerRekeyAfterFailedProof(t *testing.T) {
+	client, err := NewClient(params, string(I), "wrong password", salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err == nil {
+		t.Fatal("expected the handshake to fail")
+	}
+	if _, err := server.Rekey(); err == nil {
+		t.Fatal("expected Rekey to fail after a failed proof")
+	}
+}
@@ -0,0 +1,271 @@
+package srp
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// ChallengeSize is the length of the random nonce
+// included in the challenges returned by
+// [Session.Challenge].
+const ChallengeSize = 32
+
+// Session represents a session established by a successful
+// SRP handshake.
+//
+// It allows a server to ask the user to prove they still know
+// their password (e.g. before a sensitive operation) without
+// going through a new handshake:
+//
+//	// Server
+//	challenge, err := session.Challenge()
+//
+//	// Client
+//	response, err := session.Respond(challenge, password)
+//
+//	// Server
+//	ok, err := session.Verify(response)
+//
+// Each challenge runs a new SRP exchange, with fresh ephemeral
+// keys, bound to a random nonce and to the session key K:
+//
+//	challenge = nonce | PAD(B)
+//	response  = PAD(A) | R
+//	R         = H(K | nonce | PAD(A) | PAD(B) | K')
+//
+// where K' is the key of the new exchange. The client derives
+// K' from the password, and the server from the verifier v
+// through the SRP math, so the verifier alone is not enough to
+// answer a challenge. Binding R to K prevents it from being
+// replayed in another session.
+//
+// A Session is safe for concurrent use by multiple goroutines.
+type Session struct {
+	mu sync.Mutex // Guards params, salt, verifier, challenge and pending
+
+	username  string
+	key       []byte // Session key (K)
+	params    *Params
+	salt      []byte
+	verifier  []byte            // Only known by the server
+	challenge *sessionChallenge // Outstanding server challenge
+	pending   *sessionUpdate    // Change sent by the client, not yet confirmed
+}
+
+// sessionUpdate holds the values a client session switches
+// to once the server acknowledged a change.
+type sessionUpdate struct {
+	info   string // HKDF info of the request
+	params *Params
+	salt   []byte
+}
+
+// sessionChallenge holds the values of an outstanding
+// challenge.
+type sessionChallenge struct {
+	nonce []byte
+	b     *big.Int // Server private ephemeral
+	xB    *big.Int // Server public ephemeral
+}
+
+// Session returns the session established by c.
+//
+// An error is returned if the server's public ephemeral key (B)
+// has not been set yet.
+func (c *Client) Session() (*Session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.xK == nil {
+		return nil, ErrClientNotReady
+	}
+
+	return &Session{
+		params:   c.params,
+		username: string(c.username),
+		salt:     c.salt,
+		key:      c.xK,
+	}, nil
+}
+
+// Session returns the session established by s.
+//
+// An error is returned if the client's proof (M1) has
+// not been verified by calling the s.CheckM1 method first.
+func (s *Server) Session() (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.xK == nil {
+		return nil, ErrServerNoReady
+	}
+	if !s.verifiedM1 {
+		return nil, errors.New("client must show their proof first")
+	}
+
+	return &Session{
+		params:   s.params,
+		username: s.triplet.Username(),
+		salt:     s.triplet.Salt(),
+		verifier: s.triplet.Verifier(),
+		key:      s.xK,
+	}, nil
+}
+
+// Challenge returns a new challenge the client must answer
+// with [Session.Respond].
+//
+// Only the last challenge returned can be answered, and
+// only once.
+//
+// Challenge is called server-side.
+func (s *Session) Challenge() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.verifier == nil {
+		return nil, errors.New("only the server can issue a challenge")
+	}
+
+	k, err := computeLittleK(s.params)
+	if err != nil {
+		return nil, err
+	}
+	b, B, err := newServerKeyPair(s.params, k, new(big.Int).SetBytes(s.verifier), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &sessionChallenge{
+		nonce: randomKey(ChallengeSize),
+		b:     b,
+		xB:    B,
+	}
+	s.challenge = c
+	return append(append([]byte{}, c.nonce...), padKey(s.params, B)...), nil
+}
+
+// Respond returns the response to challenge, computed
+// from the user's password.
+//
+// Respond is called client-side, and runs the params'
+// key derivation function.
+func (s *Session) Respond(challenge []byte, password string) ([]byte, error) {
+	s.mu.Lock()
+	params, salt, key := s.params, s.salt, s.key
+	s.mu.Unlock()
+
+	if len(challenge) != ChallengeSize+keyLength(params) {
+		return nil, errors.New("invalid challenge")
+	}
+	nonce := challenge[:ChallengeSize]
+	B, err := decodePublicKey(params, challenge[ChallengeSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := deriveX(context.Background(), params, s.username, password, salt)
+	if err != nil {
+		return nil, err
+	}
+	a, A, err := newClientKeyPair(params, nil)
+	if err != nil {
+		return nil, err
+	}
+	k, err := computeLittleK(params)
+	if err != nil {
+		return nil, err
+	}
+	u, err := computeLittleU(params, A, B)
+	if err != nil {
+		return nil, err
+	}
+	S, err := computeClientS(params, k, x, u, B, a, false)
+	if err != nil {
+		return nil, err
+	}
+
+	R, err := computeChallengeResponse(params, key, nonce, A, B, S)
+	if err != nil {
+		return nil, err
+	}
+	return append(padKey(params, A), R...), nil
+}
+
+// Verify returns true if response is the valid answer
+// to the last challenge returned by [Session.Challenge].
+//
+// Verify is called server-side.
+func (s *Session) Verify(response []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.challenge == nil {
+		return false, errors.New("no outstanding challenge")
+	}
+
+	c := s.challenge
+	s.challenge = nil
+
+	length := keyLength(s.params)
+	if len(response) != length+s.params.Hash.Size() {
+		return false, nil
+	}
+	A, err := decodePublicKey(s.params, response[:length])
+	if err != nil {
+		return false, nil
+	}
+
+	u, err := computeLittleU(s.params, A, c.xB)
+	if err != nil {
+		return false, err
+	}
+	S, err := computeServerS(s.params, new(big.Int).SetBytes(s.verifier), u, A, c.b, false)
+	if err != nil {
+		return false, err
+	}
+
+	R, err := computeChallengeResponse(s.params, s.key, c.nonce, A, c.xB, S)
+	if err != nil {
+		return false, err
+	}
+	return checkProof(R, response[length:]), nil
+}
+
+// computeChallengeResponse computes the response
+// to a re-authentication challenge.
+//
+// Formula:
+//
+//	R = H(K | nonce | PAD(A) | PAD(B) | K')
+func computeChallengeResponse(params *Params, K, nonce []byte, A, B, S *big.Int) ([]byte, error) {
+	exchangeKey, err := computeK(params, S)
+	if err != nil {
+		return nil, err
+	}
+
+	h := params.Hash.New()
+	h.Write(K)
+	h.Write(nonce)
+	h.Write(padKey(params, A))
+	h.Write(padKey(params, B))
+	h.Write(exchangeKey)
+	return h.Sum(nil)[:h.Size()], nil
+}
+
+// keyLength returns the length of N in bytes, to which
+// public keys are padded.
+func keyLength(params *Params) int {
+	return (params.Group.N.BitLen() + 7) / 8
+}
+
+// padKey returns X left-padded with zeros to the
+// length of N.
+func padKey(params *Params, X *big.Int) []byte {
+	return X.FillBytes(make([]byte, keyLength(params)))
+}
@@ -0,0 +1,164 @@
+package srp
+
+import (
+	"bytes"
+	"testing"
+)
+
+// newSessions returns the sessions of a client and a server
+// after a successful handshake.
+func newSessions(t *testing.T) (*Session, *Session) {
+	t.Helper()
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := client.Session()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := server.Session()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cs, ss
+}
+
+func TestSessionChallenge(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	challenge, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := cs.Respond(challenge, string(P))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := ss.Verify(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected response to be valid")
+	}
+
+	if _, err := ss.Verify(response); err == nil {
+		t.Fatal("expected challenge to be consumed")
+	}
+}
+
+func TestSessionChallengeWrongPassword(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	challenge, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := cs.Respond(challenge, "wrong-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := ss.Verify(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("expected response to be rejected")
+	}
+}
+
+func TestSessionChallengeReplay(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	old, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := cs.Respond(old, string(P))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ss.Challenge(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := ss.Verify(response); ok {
+		t.Fatal("expected response to a previous challenge to be rejected")
+	}
+}
+
+func TestSessionNotReady(t *testing.T) {
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(A.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Session(); err == nil {
+		t.Fatal("expected an error before M1 is verified")
+	}
+
+	cs, _ := newSessions(t)
+	if _, err := cs.Challenge(); err == nil {
+		t.Fatal("expected the client to be unable to issue challenges")
+	}
+}
+
+func TestSessionChallengeVerifierOnly(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	challenge, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An attacker knowing K and v, but not the password, cannot
+	// derive the key of the exchange.
+	h := params.Hash.New()
+	h.Write(cs.key)
+	h.Write(challenge)
+	h.Write(v.Bytes())
+	forged := append(padKey(params, A), h.Sum(nil)...)
+
+	if ok, err := ss.Verify(forged); err != nil || ok {
+		t.Fatalf("expected a response derived from v to be rejected, got %v, %v", ok, err)
+	}
+}
+
+func TestSessionChallengeFresh(t *testing.T) {
+	cs, ss := newSessions(t)
+
+	first, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ss.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first[ChallengeSize:], second[ChallengeSize:]) {
+		t.Fatal("expected each challenge to use a fresh B")
+	}
+
+	if _, err := cs.Respond(second[:ChallengeSize], string(P)); err == nil {
+		t.Fatal("expected a truncated challenge to be rejected")
+	}
+	if ok, err := ss.Verify([]byte("short")); err != nil || ok {
+		t.Fatalf("expected a malformed response to be rejected, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,280 @@
+//go:build soak
+
+package srp
+
+import (
+	"crypto"
+	"flag"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The soak test runs a large number of handshakes while tracking
+// heap growth and goroutine leaks. It is excluded from regular
+// builds and must be run explicitly:
+//
+//	go test -tags soak -run TestSoak -timeout 0 \
+//	  -soak.n 1000000 -soak.cpuprofile cpu.out -soak.memprofile mem.out
+var (
+	soakHandshakes  = flag.Int("soak.n", 1000000, "number of handshakes to run")
+	soakWorkers     = flag.Int("soak.workers", runtime.GOMAXPROCS(0), "number of concurrent workers")
+	soakGroupBits   = flag.Int("soak.group", 2048, "size in bits of the RFC 5054 group to use")
+	soakInterval    = flag.Duration("soak.interval", 10*time.Second, "interval between heap samples")
+	soakMaxGrowth   = flag.Float64("soak.maxgrowth", 2, "maximum heap growth factor allowed between the first and last sample")
+	soakCPUProfile  = flag.String("soak.cpuprofile", "", "write a CPU profile to this file")
+	soakHeapProfile = flag.String("soak.memprofile", "", "write a heap profile to this file when the test ends")
+	soakPoolSize    = flag.Int("soak.pool", 64, "number of key pairs held by the ephemeral pool of TestSoakPool")
+)
+
+// soakGroups lists the groups available to the soak test.
+var soakGroups = map[int]*Group{
+	1024: RFC5054Group1024,
+	1536: RFC5054Group1536,
+	2048: RFC5054Group2048,
+	3072: RFC5054Group3072,
+	4096: RFC5054Group4096,
+	6144: RFC5054Group6144,
+	8192: RFC5054Group8192,
+}
+
+// heapInUse returns the number of bytes in use in
+// the heap after a garbage collection.
+func heapInUse() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapInuse
+}
+
+// soakParams returns the params of the group selected
+// with -soak.group.
+func soakParams(t *testing.T) *Params {
+	group, ok := soakGroups[*soakGroupBits]
+	if !ok {
+		t.Fatalf("unknown group size %d", *soakGroupBits)
+	}
+	return &Params{
+		Name:  "soak",
+		Group: group,
+		Hash:  crypto.SHA1,
+		KDF:   RFC5054KDF,
+	}
+}
+
+// checkGoroutineLeaks reports an error if more goroutines than
+// the given number are still running after a grace period.
+func checkGoroutineLeaks(t *testing.T, goroutines int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		pprof.Lookup("goroutine").WriteTo(os.Stderr, 1)
+		t.Errorf("%d goroutines leaked", n-goroutines)
+	}
+}
+
+func TestSoak(t *testing.T) {
+	params := soakParams(t)
+
+	tp, err := ComputeVerifier(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *soakCPUProfile != "" {
+		f, err := os.Create(*soakCPUProfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			t.Fatal(err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	var (
+		goroutines = runtime.NumGoroutine()
+		baseline   = heapInUse()
+		samples    = []uint64{baseline}
+		done       atomic.Int64
+		failed     atomic.Int64
+		next       atomic.Int64
+		wg         sync.WaitGroup
+		stop       = make(chan struct{})
+		sampled    = make(chan struct{})
+		start      = time.Now()
+	)
+
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(*soakInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				heap := heapInUse()
+				samples = append(samples, heap)
+				n := done.Load()
+				t.Logf("%d handshakes, %.0f/s, heap in use: %d KiB", n, float64(n)/time.Since(start).Seconds(), heap/1024)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < *soakWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next.Add(1) <= int64(*soakHandshakes) {
+				client, err := NewClient(params, string(I), string(P), tp.Salt())
+				if err != nil {
+					failed.Add(1)
+					continue
+				}
+				server, err := NewServer(params, tp.Username(), tp.Salt(), tp.Verifier())
+				if err != nil {
+					failed.Add(1)
+					continue
+				}
+				if err := handshake(client, server); err != nil {
+					failed.Add(1)
+					continue
+				}
+				done.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-sampled
+
+	elapsed := time.Since(start)
+	final := heapInUse()
+	t.Logf("%d handshakes in %s (%.0f/s), %d failed", done.Load(), elapsed, float64(done.Load())/elapsed.Seconds(), failed.Load())
+	t.Logf("heap in use: %d KiB at start, %d KiB at the end", baseline/1024, final/1024)
+
+	if *soakHeapProfile != "" {
+		f, err := os.Create(*soakHeapProfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := failed.Load(); n > 0 {
+		t.Errorf("%d handshakes failed", n)
+	}
+
+	// The heap is compared to the first sample taken after
+	// the workers warmed up, when there is one.
+	reference := baseline
+	if len(samples) > 1 {
+		reference = samples[1]
+	}
+	if growth := float64(final) / float64(reference); growth > *soakMaxGrowth {
+		t.Errorf("heap grew by a factor of %.2f (max: %.2f)", growth, *soakMaxGrowth)
+	}
+
+	checkGoroutineLeaks(t, goroutines)
+}
+
+// TestSoakPool runs handshakes with servers drawing their keys
+// from an EphemeralPool, using precomputed params, faster than
+// the pool refills. It checks that the pool keeps refilling
+// once drained, and that closing it leaks neither memory nor
+// goroutines.
+func TestSoakPool(t *testing.T) {
+	params := soakParams(t)
+	params.Precompute()
+
+	tp, err := ComputeVerifier(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		goroutines = runtime.NumGoroutine()
+		baseline   = heapInUse()
+		pool       = NewEphemeralPool(params, *soakPoolSize)
+		done       atomic.Int64
+		failed     atomic.Int64
+		drained    atomic.Int64
+		next       atomic.Int64
+		wg         sync.WaitGroup
+		start      = time.Now()
+	)
+
+	waitFull := func() bool {
+		deadline := time.Now().Add(time.Minute)
+		for pool.Len() < *soakPoolSize && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return pool.Len() == *soakPoolSize
+	}
+	if !waitFull() {
+		t.Fatalf("pool holds %d key pairs, expected %d", pool.Len(), *soakPoolSize)
+	}
+
+	for i := 0; i < *soakWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next.Add(1) <= int64(*soakHandshakes) {
+				if pool.Len() == 0 {
+					drained.Add(1)
+				}
+				client, err := NewClient(params, string(I), string(P), tp.Salt())
+				if err != nil {
+					failed.Add(1)
+					continue
+				}
+				server, err := NewServer(params, tp.Username(), tp.Salt(), tp.Verifier(), WithEphemeralPool(pool))
+				if err != nil {
+					failed.Add(1)
+					continue
+				}
+				if err := handshake(client, server); err != nil {
+					failed.Add(1)
+					continue
+				}
+				done.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	t.Logf("%d handshakes in %s (%.0f/s), %d failed, pool found empty %d times", done.Load(), elapsed, float64(done.Load())/elapsed.Seconds(), failed.Load(), drained.Load())
+
+	if n := failed.Load(); n > 0 {
+		t.Errorf("%d handshakes failed", n)
+	}
+	if drained.Load() == 0 {
+		t.Log("the pool was never drained, consider a smaller -soak.pool")
+	}
+
+	// The pool must refill once the load stops.
+	if !waitFull() {
+		t.Errorf("pool holds %d key pairs after the load stopped, expected %d", pool.Len(), *soakPoolSize)
+	}
+
+	pool.Close()
+	pool = nil
+	final := heapInUse()
+	t.Logf("heap in use: %d KiB at start, %d KiB at the end", baseline/1024, final/1024)
+	if growth := float64(final) / float64(baseline); growth > *soakMaxGrowth {
+		t.Errorf("heap grew by a factor of %.2f (max: %.2f)", growth, *soakMaxGrowth)
+	}
+	checkGoroutineLeaks(t, goroutines)
+}
@@ -0,0 +1,136 @@
+package spake2plus
+
+import (
+	"crypto/subtle"
+	"errors"
+	"math/big"
+	"sync"
+
+	"code.posterity.life/srp/v2"
+)
+
+// ErrNotReady is returned when the public share of the
+// peer must be set before the invoked action.
+var ErrNotReady = errors.New("peer's public share must be set first")
+
+// Client represents the client-side (prover) perspective of
+// a SPAKE2+ handshake.
+//
+// A Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	mu sync.Mutex // Guards all the fields below
+
+	params   *Params
+	username string
+	w0, w1   *big.Int // Derived from the password
+	x        *big.Int // Private ephemeral
+	shareP   []byte   // Public share, x*P + w0*M
+	keys     *keys    // Set by SetServerShare
+}
+
+// NewClient returns a new client for the user, with the
+// salt sent by the server.
+//
+// NewClient runs the key derivation function of params.
+func NewClient(params *Params, username, password string, salt []byte) (*Client, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	w0, w1, err := deriveW(params, username, password, salt)
+	if err != nil {
+		return nil, err
+	}
+	x, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		params:   params,
+		username: username,
+		w0:       w0,
+		w1:       w1,
+		x:        x,
+		shareP:   baseMul(x).add(pointM.mul(w0)).bytes(),
+	}, nil
+}
+
+// Share returns the public share (shareP) of the client,
+// which should be sent to the server.
+func (c *Client) Share() srp.PublicKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.shareP
+}
+
+// SetServerShare configures the public share (shareV) of
+// the server, and derives the keys of the handshake.
+//
+// The share can only be set once: an error is returned if
+// it was already set.
+//
+// Formula:
+//
+//	Z = x*(shareV - w0*N)
+//	V = w1*(shareV - w0*N)
+func (c *Client) SetServerShare(shareV srp.PublicKey) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keys != nil {
+		return errors.New("server share is already set")
+	}
+
+	Y, err := decodePoint(shareV)
+	if err != nil {
+		return err
+	}
+	T := Y.sub(pointN.mul(c.w0))
+	if T.isIdentity() {
+		return errors.New("invalid public share")
+	}
+
+	k := deriveKeys(c.params.Context, c.username, "", c.shareP, shareV, T.mul(c.x), T.mul(c.w1), c.w0)
+	c.keys = &k
+	return nil
+}
+
+// Confirmation returns the confirmation message (confirmP)
+// which should be sent to the server.
+func (c *Client) Confirmation() (srp.Proof, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keys == nil {
+		return nil, ErrNotReady
+	}
+	return c.keys.confirmP, nil
+}
+
+// CheckConfirmation returns true if the confirmation message
+// of the server (confirmV) is verified.
+func (c *Client) CheckConfirmation(confirmV srp.Proof) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keys == nil {
+		return false, ErrNotReady
+	}
+	return subtle.ConstantTimeCompare(c.keys.confirmV, confirmV) == 1, nil
+}
+
+// SessionKey returns the 32-byte key shared with the server.
+//
+// The key must only be used once the confirmation of the
+// server is verified with [Client.CheckConfirmation].
+func (c *Client) SessionKey() (srp.SessionKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keys == nil {
+		return nil, ErrNotReady
+	}
+	return c.keys.shared, nil
+}
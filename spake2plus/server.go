@@ -0,0 +1,163 @@
+package spake2plus
+
+import (
+	"crypto/subtle"
+	"errors"
+	"math/big"
+	"sync"
+
+	"code.posterity.life/srp/v2"
+)
+
+// errBadConfirmation is returned once the confirmation
+// message of the client was rejected.
+var errBadConfirmation = errors.New("failed to verify client confirmation")
+
+// Server represents the server-side (verifier) perspective
+// of a SPAKE2+ handshake.
+//
+// A Server is safe for concurrent use by multiple goroutines.
+type Server struct {
+	mu sync.Mutex // Guards all the fields below
+
+	params   *Params
+	username string
+	w0       *big.Int // Part of the verifier
+	L        point    // Part of the verifier, w1*P
+	y        *big.Int // Private ephemeral
+	shareV   []byte   // Public share, y*P + w0*N
+	keys     *keys    // Set by SetClientShare
+	verified bool     // Tracks if the client confirmation was verified
+	err      error    // Set once the client confirmation was rejected
+}
+
+// NewServer returns a new server for the user, with the salt
+// and the verifier computed by [ComputeVerifier].
+func NewServer(params *Params, username string, salt, verifier []byte) (*Server, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	w0, L, err := parseVerifier(verifier)
+	if err != nil {
+		return nil, err
+	}
+	y, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		params:   params,
+		username: username,
+		w0:       w0,
+		L:        L,
+		y:        y,
+		shareV:   baseMul(y).add(pointN.mul(w0)).bytes(),
+	}, nil
+}
+
+// Share returns the public share (shareV) of the server,
+// which should be sent to the client.
+func (s *Server) Share() srp.PublicKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.shareV
+}
+
+// SetClientShare configures the public share (shareP) of
+// the client, and derives the keys of the handshake.
+//
+// Formula:
+//
+//	Z = y*(shareP - w0*M)
+//	V = y*L
+func (s *Server) SetClientShare(shareP srp.PublicKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys != nil {
+		return errors.New("client share is already set")
+	}
+
+	X, err := decodePoint(shareP)
+	if err != nil {
+		return err
+	}
+	T := X.sub(pointM.mul(s.w0))
+	if T.isIdentity() {
+		return errors.New("invalid public share")
+	}
+
+	k := deriveKeys(s.params.Context, s.username, "", shareP, s.shareV, T.mul(s.y), s.L.mul(s.y), s.w0)
+	s.keys = &k
+	return nil
+}
+
+// CheckConfirmation returns true if the confirmation message
+// of the client (confirmP) is verified.
+//
+// Once a confirmation is rejected, the server must not be
+// used anymore.
+func (s *Server) CheckConfirmation(confirmP srp.Proof) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return false, s.err
+	}
+	if s.keys == nil {
+		return false, ErrNotReady
+	}
+
+	s.verified = subtle.ConstantTimeCompare(s.keys.confirmP, confirmP) == 1
+	if !s.verified {
+		s.err = errBadConfirmation
+	}
+	return s.verified, nil
+}
+
+// Confirmation returns the confirmation message (confirmV)
+// which should be sent to the client.
+//
+// An error is returned if the confirmation of the client
+// was not verified with [Server.CheckConfirmation] first.
+func (s *Server) Confirmation() (srp.Proof, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+	return s.keys.confirmV, nil
+}
+
+// SessionKey returns the 32-byte key shared with the client.
+//
+// An error is returned if the confirmation of the client
+// was not verified with [Server.CheckConfirmation] first.
+func (s *Server) SessionKey() (srp.SessionKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+	return s.keys.shared, nil
+}
+
+// ready returns an error if the confirmation of the client
+// was not verified. The caller must hold s.mu.
+func (s *Server) ready() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.keys == nil {
+		return ErrNotReady
+	}
+	if !s.verified {
+		return errors.New("client must show their confirmation first")
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+// Package spake2plus is an implementation of the SPAKE2+
+// augmented password-authenticated key exchange, as defined
+// in [RFC9383], over the P-256 curve.
+//
+// It offers the same guarantees as SRP — the server only
+// stores a verifier from which the password cannot be
+// recovered without an offline dictionary attack — with a
+// [Client], a [Server] and [srp.Triplet] values shaped like
+// those of the srp package. Elliptic-curve operations are much
+// faster than the modular exponentiations of SRP groups, which
+// makes it better suited to constrained devices when
+// interoperability with RFC 5054 is not required.
+//
+// The messages of a handshake are exchanged in the same order
+// as those of SRP:
+//
+//	Client → Server: I
+//	Server → Client: s, shareV
+//	Client → Server: shareP, confirmP
+//	Server → Client: confirmV
+//
+// Only the ciphersuite SPAKE2+-P256-SHA256-HKDF-SHA256-HMAC-SHA256
+// is supported.
+//
+// [RFC9383]: https://datatracker.ietf.org/doc/html/rfc9383
+package spake2plus // code.posterity.life/srp/v2/spake2plus
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"code.posterity.life/srp/v2"
+	"filippo.io/nistec"
+)
+
+// Params represents the settings that a client and a server
+// jointly agreed to use.
+type Params struct {
+	Name string
+
+	// KDF is the password-based key derivation function from
+	// which w0 and w1 are derived (e.g. Argon2). Its output is
+	// expanded with HKDF-SHA256.
+	KDF srp.KDF
+
+	// Context binds the handshake to an application protocol,
+	// as the Context value of RFC 9383. It must be identical on
+	// both sides.
+	Context string
+}
+
+// validate returns an error if p cannot be used.
+func (p *Params) validate() error {
+	if p == nil {
+		return errors.New("params cannot be nil")
+	}
+	if p.KDF == nil {
+		return fmt.Errorf("params %q: KDF cannot be nil", p.Name)
+	}
+	return nil
+}
+
+// Length of the encoding of a scalar, and of an
+// uncompressed point.
+const (
+	scalarSize = 32
+	pointSize  = 1 + 2*scalarSize
+)
+
+// verifierSize is the length of the verifier of a user,
+// made of w0 and L.
+const verifierSize = scalarSize + pointSize
+
+// wSize is the length of w0s and w1s, as recommended by
+// RFC 9383 for P-256.
+const wSize = scalarSize + 8
+
+// HKDF info strings defined in RFC 9383, and used to expand
+// the output of the KDF.
+const (
+	infoConfirmationKeys = "ConfirmationKeys"
+	infoSharedKey        = "SharedKey"
+	infoW                = "SPAKE2+-P256 w0s w1s"
+)
+
+var (
+	// Order of the generator of P-256.
+	order, _ = new(big.Int).SetString("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551", 16)
+
+	// Points M and N for P-256, defined
+	// in RFC 9383.
+	pointM = mustDecodePoint("02886e2f97ace46e55ba9dd7242579f2993b64e16ef3dcab95afd497333d8fa12f")
+	pointN = mustDecodePoint("03d8bbd6c639c62937b04d997f38c3770719c629d7014d49a24b4f98baa1292b49")
+)
+
+// point is a point of the curve. Its methods return new
+// points, and leave their receiver unchanged.
+type point struct {
+	p *nistec.P256Point
+}
+
+// mustDecodePoint decodes a compressed point from s,
+// or panics.
+func mustDecodePoint(s string) point {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	p, err := nistec.NewP256Point().SetBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return point{p}
+}
+
+// decodePoint decodes an uncompressed point received from
+// the peer, and returns an error if it is not on the curve.
+func decodePoint(b []byte) (point, error) {
+	if len(b) != pointSize || b[0] != 4 {
+		return point{}, errors.New("invalid public share")
+	}
+	p, err := nistec.NewP256Point().SetBytes(b)
+	if err != nil {
+		return point{}, errors.New("invalid public share")
+	}
+	return point{p}, nil
+}
+
+// bytes returns the uncompressed encoding of p, or a single
+// zero byte if p is the point at infinity.
+func (p point) bytes() []byte {
+	return p.p.Bytes()
+}
+
+// isIdentity returns true if p is the point at infinity.
+func (p point) isIdentity() bool {
+	return len(p.p.Bytes()) == 1
+}
+
+// add returns p + q.
+func (p point) add(q point) point {
+	return point{nistec.NewP256Point().Add(p.p, q.p)}
+}
+
+// sub returns p - q.
+func (p point) sub(q point) point {
+	neg := nistec.NewP256Point().Negate(q.p)
+	return point{neg.Add(p.p, neg)}
+}
+
+// mul returns k*p.
+func (p point) mul(k *big.Int) point {
+	r, err := nistec.NewP256Point().ScalarMult(p.p, scalarBytes(k))
+	if err != nil {
+		panic(err)
+	}
+	return point{r}
+}
+
+// baseMul returns k*P, where P is the generator.
+func baseMul(k *big.Int) point {
+	r, err := nistec.NewP256Point().ScalarBaseMult(scalarBytes(k))
+	if err != nil {
+		panic(err)
+	}
+	return point{r}
+}
+
+// scalarBytes returns k as a fixed-size big-endian
+// byte slice.
+func scalarBytes(k *big.Int) []byte {
+	return k.FillBytes(make([]byte, scalarSize))
+}
+
+// randomScalar returns a random scalar in [1, n-1].
+func randomScalar() (*big.Int, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(order, big.NewInt(1)))
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// deriveW derives w0 and w1 from the credentials of a user.
+//
+//	w0s || w1s = HKDF(KDF(I, p, s), s, "SPAKE2+-P256 w0s w1s")
+//	w0 = w0s mod n
+//	w1 = w1s mod n
+func deriveW(params *Params, username, password string, salt []byte) (w0, w1 *big.Int, err error) {
+	secret, err := params.KDF(srp.NFKD(username), srp.NFKD(password), salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := hkdf(secret, salt, []byte(infoW), 2*wSize)
+	w0 = new(big.Int).SetBytes(b[:wSize])
+	w0.Mod(w0, order)
+	w1 = new(big.Int).SetBytes(b[wSize:])
+	w1.Mod(w1, order)
+	return w0, w1, nil
+}
+
+// ComputeVerifier computes the verifier of a user from
+// their username, password and salt, and returns it in a
+// triplet to be stored by the server.
+//
+// The verifier is made of w0 and L = w1*P:
+//
+//	+------------------------+
+//	| w0 (32)                |
+//	+------------------------+
+//	| L (65)                 |
+//	+------------------------+
+func ComputeVerifier(params *Params, username, password string, salt []byte) (srp.Triplet, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	w0, w1, err := deriveW(params, username, password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := make([]byte, 0, verifierSize)
+	verifier = append(verifier, scalarBytes(w0)...)
+	verifier = append(verifier, baseMul(w1).bytes()...)
+	return srp.NewTriplet(username, salt, verifier), nil
+}
+
+// parseVerifier returns w0 and L from the verifier
+// of a user.
+func parseVerifier(verifier []byte) (w0 *big.Int, L point, err error) {
+	if len(verifier) != verifierSize {
+		return nil, point{}, fmt.Errorf("verifier must be %d bytes long", verifierSize)
+	}
+	w0 = new(big.Int).SetBytes(verifier[:scalarSize])
+	if w0.Cmp(order) >= 0 {
+		return nil, point{}, errors.New("invalid verifier")
+	}
+	L, err = decodePoint(verifier[scalarSize:])
+	if err != nil {
+		return nil, point{}, errors.New("invalid verifier")
+	}
+	return w0, L, nil
+}
+
+// keys holds the keys derived from the transcript
+// of a handshake.
+type keys struct {
+	confirmP []byte // Confirmation of the client
+	confirmV []byte // Confirmation of the server
+	shared   []byte // Session key
+}
+
+// deriveKeys derives the confirmation messages and the
+// session key from the transcript of a handshake:
+//
+//	K_main = SHA256(TT)
+//	K_confirmP | K_confirmV = HKDF(K_main, "ConfirmationKeys")
+//	K_shared = HKDF(K_main, "SharedKey")
+//	confirmP = HMAC(K_confirmP, shareV)
+//	confirmV = HMAC(K_confirmV, shareP)
+//
+// The client and the server use the username as the identity
+// of the prover (idProver), and an empty identity for the
+// verifier (idVerifier).
+func deriveKeys(context, idProver, idVerifier string, shareP, shareV []byte, Z, V point, w0 *big.Int) keys {
+	main := sha256.Sum256(transcript(context, idProver, idVerifier, shareP, shareV, Z, V, w0))
+
+	confirmation := hkdf(main[:], nil, []byte(infoConfirmationKeys), 2*sha256.Size)
+	return keys{
+		confirmP: mac(confirmation[:sha256.Size], shareV),
+		confirmV: mac(confirmation[sha256.Size:], shareP),
+		shared:   hkdf(main[:], nil, []byte(infoSharedKey), sha256.Size),
+	}
+}
+
+// transcript returns the transcript (TT) of a handshake:
+//
+//	TT = Context | idProver | idVerifier | M | N | shareP | shareV | Z | V | w0
+//
+// where each value is prefixed with its length, encoded on
+// 8 bytes in little-endian order.
+func transcript(context, idProver, idVerifier string, shareP, shareV []byte, Z, V point, w0 *big.Int) []byte {
+	var tt []byte
+	for _, b := range [][]byte{
+		[]byte(context),
+		[]byte(idProver),
+		[]byte(idVerifier),
+		pointM.bytes(),
+		pointN.bytes(),
+		shareP,
+		shareV,
+		Z.bytes(),
+		V.bytes(),
+		scalarBytes(w0),
+	} {
+		tt = binary.LittleEndian.AppendUint64(tt, uint64(len(b)))
+		tt = append(tt, b...)
+	}
+	return tt
+}
+
+// mac returns the HMAC-SHA256 of msg with key.
+func mac(key, msg []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// hkdf derives a key of the given length from secret using
+// HKDF-SHA256, as defined in RFC 5869.
+func hkdf(secret, salt, info []byte, length int) []byte {
+	if salt == nil {
+		salt = make([]byte, sha256.Size)
+	}
+	prk := mac(salt, secret)
+
+	var (
+		expander = hmac.New(sha256.New, prk)
+		okm      = make([]byte, 0, length+sha256.Size)
+		block    []byte
+	)
+	for counter := byte(1); len(okm) < length; counter++ {
+		expander.Reset()
+		expander.Write(block)
+		expander.Write(info)
+		expander.Write([]byte{counter})
+		block = expander.Sum(nil)
+		okm = append(okm, block...)
+	}
+	return okm[:length]
+}
@@ -0,0 +1,374 @@
+package spake2plus
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"code.posterity.life/srp/v2"
+
+	_ "crypto/sha256"
+)
+
+var params = &Params{
+	Name:    "test",
+	KDF:     srp.RFC5054KDF,
+	Context: "spake2plus test",
+}
+
+// handshake runs a handshake between c and s, and returns
+// an error if either side fails to authenticate the other.
+func handshake(c *Client, s *Server) error {
+	if err := c.SetServerShare(s.Share()); err != nil {
+		return err
+	}
+	if err := s.SetClientShare(c.Share()); err != nil {
+		return err
+	}
+
+	confirmP, err := c.Confirmation()
+	if err != nil {
+		return err
+	}
+	if ok, err := s.CheckConfirmation(confirmP); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("client confirmation rejected")
+	}
+
+	confirmV, err := s.Confirmation()
+	if err != nil {
+		return err
+	}
+	if ok, err := c.CheckConfirmation(confirmV); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("server confirmation rejected")
+	}
+	return nil
+}
+
+// newPair returns a client using password, and a server
+// with the verifier of "password123".
+func newPair(t testing.TB, password string) (*Client, *Server) {
+	t.Helper()
+
+	salt := srp.NewSalt()
+	tp, err := ComputeVerifier(params, "alice", "password123", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(params, "alice", password, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(params, tp.Username(), tp.Salt(), tp.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, s
+}
+
+func TestHandshake(t *testing.T) {
+	c, s := newPair(t, "password123")
+	if err := handshake(c, s); err != nil {
+		t.Fatal(err)
+	}
+
+	cK, err := c.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sK, err := s.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cK, sK) {
+		t.Fatal("session keys differ")
+	}
+	if len(cK) != 32 {
+		t.Fatalf("wanted a 32-byte key, got %d bytes", len(cK))
+	}
+}
+
+func TestHandshakeWrongPassword(t *testing.T) {
+	c, s := newPair(t, "password124")
+	if err := handshake(c, s); err == nil {
+		t.Fatal("expected a wrong password to be rejected")
+	}
+
+	if _, err := s.Confirmation(); err == nil {
+		t.Fatal("expected the server not to confirm")
+	}
+	if _, err := s.SessionKey(); err == nil {
+		t.Fatal("expected the server not to return a session key")
+	}
+	if _, err := s.CheckConfirmation(nil); err == nil {
+		t.Fatal("expected a rejected server to fail")
+	}
+}
+
+func TestSharesSetOnce(t *testing.T) {
+	c, s := newPair(t, "password123")
+	if err := handshake(c, s); err != nil {
+		t.Fatal(err)
+	}
+	key, err := c.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another share must not replace the keys of the handshake.
+	_, other := newPair(t, "password123")
+	if err := c.SetServerShare(other.Share()); err == nil {
+		t.Fatal("expected a second server share to be rejected")
+	}
+	if err := s.SetClientShare(c.Share()); err == nil {
+		t.Fatal("expected a second client share to be rejected")
+	}
+	if k, err := c.SessionKey(); err != nil || !bytes.Equal(k, key) {
+		t.Fatalf("expected the session key to be unchanged, got %x, %v", k, err)
+	}
+}
+
+func TestHandshakeContext(t *testing.T) {
+	salt := srp.NewSalt()
+	tp, err := ComputeVerifier(params, "alice", "password123", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := &Params{KDF: params.KDF, Context: "other"}
+	c, err := NewClient(other, "alice", "password123", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(params, tp.Username(), tp.Salt(), tp.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(c, s); err == nil {
+		t.Fatal("expected different contexts to fail")
+	}
+}
+
+func TestInvalidShares(t *testing.T) {
+	c, s := newPair(t, "password123")
+
+	// w0*M cancels out the blinding of the client share.
+	w0M := pointM.mul(s.w0).bytes()
+
+	for name, share := range map[string][]byte{
+		"Empty":      nil,
+		"Compressed": w0M[:33],
+		"OffCurve":   append([]byte{4}, make([]byte, 64)...),
+		"Identity":   w0M,
+	} {
+		if err := s.SetClientShare(share); err == nil {
+			t.Fatalf("%s: expected share to be rejected", name)
+		}
+	}
+	if err := c.SetServerShare(pointN.mul(c.w0).bytes()); err == nil {
+		t.Fatal("expected share to be rejected")
+	}
+
+	if _, err := c.Confirmation(); err != ErrNotReady {
+		t.Fatalf("wanted ErrNotReady, got %v", err)
+	}
+	if _, err := s.CheckConfirmation(nil); err != ErrNotReady {
+		t.Fatalf("wanted ErrNotReady, got %v", err)
+	}
+}
+
+func TestInvalidVerifier(t *testing.T) {
+	tp, err := ComputeVerifier(params, "alice", "password123", srp.NewSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := tp.Verifier()
+
+	for name, verifier := range map[string][]byte{
+		"Short":        v[:len(v)-1],
+		"InvalidPoint": append(append([]byte(nil), v[:scalarSize]...), make([]byte, pointSize)...),
+		"LargeW0":      append(bytes.Repeat([]byte{0xff}, scalarSize), v[scalarSize:]...),
+	} {
+		if _, err := NewServer(params, "alice", tp.Salt(), verifier); err == nil {
+			t.Fatalf("%s: expected verifier to be rejected", name)
+		}
+	}
+
+	if _, err := NewClient(&Params{}, "alice", "password123", nil); err == nil {
+		t.Fatal("expected params without a KDF to be rejected")
+	}
+}
+
+// TestRFC9383Vectors checks the computations of the client and
+// the key schedule against the test vectors of RFC 9383,
+// Appendix C (SPAKE2+-P256-SHA256-HKDF-SHA256-HMAC-SHA256).
+//
+// The vectors use "client" and "server" as identities. The
+// share of the server (shareV = y*P + w0*N) is taken as given,
+// and checked through Z and V, which the client derives from
+// it with x and w1.
+func TestRFC9383Vectors(t *testing.T) {
+	var (
+		context    = "SPAKE2+-P256-SHA256-HKDF-SHA256-HMAC-SHA256 Test Vectors"
+		idProver   = "client"
+		idVerifier = "server"
+
+		w0 = mustDecodeScalar(t, "bb8e1bbcf3c48f62c08db243652ae55d3e5586053fca77102994f23ad95491b3")
+		w1 = mustDecodeScalar(t, "7e945f34d78785b8a3ef44d0df5a1a97d6b3b460409a345ca7830387a74b1dba")
+		x  = mustDecodeScalar(t, "d1232c8e8693d02368976c174e2088851b8365d0d79a9eee709c6a05a2fad539")
+
+		L      = mustDecodeHex(t, "04eb7c9db3d9a9eb1f8adab81b5794c1f13ae3e225efbe91ea487425854c7fc00f00bfedcbd09b2400142d40a14f2064ef31dfaa903b91d1faea7093d835966efd")
+		shareP = mustDecodeHex(t, "04ef3bd051bf78a2234ec0df197f7828060fe9856503579bb1733009042c15c0c1de127727f418b5966afadfdd95a6e4591d171056b333dab97a79c7193e341727")
+		shareV = mustDecodeHex(t, "04c0f65da0d11927bdf5d560c69e1d7d939a05b0e88291887d679fcadea75810fb5cc1ca7494db39e82ff2f50665255d76173e09986ab46742c798a9a68437b048")
+		Z      = mustDecodeHex(t, "04bbfce7dd7f277819c8da21544afb7964705569bdf12fb92aa388059408d50091a0c5f1d3127f56813b5337f9e4e67e2ca633117a4fbd559946ab474356c41839")
+		V      = mustDecodeHex(t, "0458bf27c6bca011c9ce1930e8984a797a3419797b936629a5a937cf2f11c8b9514b82b993da8a46e664f23db7c01edc87faa530db01c2ee405230b18997f16b68")
+
+		TT = mustDecodeHex(t, ""+
+			"38000000000000005350414b45322b2d503235362d5348413235362d484b4446"+
+			"2d5348413235362d484d41432d534841323536205465737420566563746f7273"+
+			"0600000000000000636c69656e74060000000000000073657276657241000000"+
+			"0000000004886e2f97ace46e55ba9dd7242579f2993b64e16ef3dcab95afd497"+
+			"333d8fa12f5ff355163e43ce224e0b0e65ff02ac8e5c7be09419c785e0ca547d"+
+			"55a12e2d20410000000000000004d8bbd6c639c62937b04d997f38c3770719c6"+
+			"29d7014d49a24b4f98baa1292b4907d60aa6bfade45008a636337f5168c64d9b"+
+			"d36034808cd564490b1e656edbe7410000000000000004ef3bd051bf78a2234e"+
+			"c0df197f7828060fe9856503579bb1733009042c15c0c1de127727f418b5966a"+
+			"fadfdd95a6e4591d171056b333dab97a79c7193e341727410000000000000004"+
+			"c0f65da0d11927bdf5d560c69e1d7d939a05b0e88291887d679fcadea75810fb"+
+			"5cc1ca7494db39e82ff2f50665255d76173e09986ab46742c798a9a68437b048"+
+			"410000000000000004bbfce7dd7f277819c8da21544afb7964705569bdf12fb9"+
+			"2aa388059408d50091a0c5f1d3127f56813b5337f9e4e67e2ca633117a4fbd55"+
+			"9946ab474356c4183941000000000000000458bf27c6bca011c9ce1930e8984a"+
+			"797a3419797b936629a5a937cf2f11c8b9514b82b993da8a46e664f23db7c01e"+
+			"dc87faa530db01c2ee405230b18997f16b682000000000000000bb8e1bbcf3c4"+
+			"8f62c08db243652ae55d3e5586053fca77102994f23ad95491b3",
+		)
+		mainKey  = mustDecodeHex(t, "4c59e1ccf2cfb961aa31bd9434478a1089b56cd11542f53d3576fb6c2a438a29")
+		confirmP = mustDecodeHex(t, "926cc713504b9b4d76c9162ded04b5493e89109f6d89462cd33adc46fda27527")
+		confirmV = mustDecodeHex(t, "9747bcc4f8fe9f63defee53ac9b07876d907d55047e6ff2def2e7529089d3e68")
+		shared   = mustDecodeHex(t, "0c5f8ccd1413423a54f6c1fb26ff01534a87f893779c6e68666d772bfd91f3e7")
+	)
+
+	assertEqualBytes(t, "L", L, baseMul(w1).bytes())
+	assertEqualBytes(t, "shareP", shareP, baseMul(x).add(pointM.mul(w0)).bytes())
+
+	Y, err := decodePoint(shareV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	T := Y.sub(pointN.mul(w0))
+	assertEqualBytes(t, "Z", Z, T.mul(x).bytes())
+	assertEqualBytes(t, "V", V, T.mul(w1).bytes())
+
+	pZ, err := decodePoint(Z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pV, err := decodePoint(V)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt := transcript(context, idProver, idVerifier, shareP, shareV, pZ, pV, w0)
+	assertEqualBytes(t, "TT", TT, tt)
+	main := sha256.Sum256(tt)
+	assertEqualBytes(t, "K_main", mainKey, main[:])
+
+	k := deriveKeys(context, idProver, idVerifier, shareP, shareV, pZ, pV, w0)
+	assertEqualBytes(t, "confirmP", confirmP, k.confirmP)
+	assertEqualBytes(t, "confirmV", confirmV, k.confirmV)
+	assertEqualBytes(t, "K_shared", shared, k.shared)
+}
+
+// mustDecodeHex decodes the hexadecimal string s.
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// mustDecodeScalar decodes the hexadecimal scalar s.
+func mustDecodeScalar(t *testing.T, s string) *big.Int {
+	t.Helper()
+	return new(big.Int).SetBytes(mustDecodeHex(t, s))
+}
+
+// assertEqualBytes fails t if got differs from want.
+func assertEqualBytes(t *testing.T, name string, want, got []byte) {
+	t.Helper()
+
+	if !bytes.Equal(want, got) {
+		t.Fatalf("%s: wanted %x, got %x", name, want, got)
+	}
+}
+
+func BenchmarkHandshake(b *testing.B) {
+	salt := srp.NewSalt()
+	tp, err := ComputeVerifier(params, "alice", "password123", salt)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c, err := NewClient(params, "alice", "password123", salt)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s, err := NewServer(params, tp.Username(), tp.Salt(), tp.Verifier())
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := handshake(c, s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSRPHandshake runs an SRP handshake with the
+// 3072-bit group of RFC 5054, for comparison.
+func BenchmarkSRPHandshake(b *testing.B) {
+	p := &srp.Params{Group: srp.RFC5054Group3072, Hash: crypto.SHA256, KDF: srp.RFC5054KDF}
+	salt := srp.NewSalt()
+	tp, err := srp.ComputeVerifier(p, "alice", "password123", salt)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c, err := srp.NewClient(p, "alice", "password123", salt)
+		if err != nil {
+			b.Fatal(err)
+		}
+		s, err := srp.NewServer(p, tp.Username(), tp.Salt(), tp.Verifier())
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := s.SetA(c.A()); err != nil {
+			b.Fatal(err)
+		}
+		if err := c.SetB(s.B()); err != nil {
+			b.Fatal(err)
+		}
+		M1, err := c.ComputeM1()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if ok, _ := s.CheckM1(M1); !ok {
+			b.Fatal("M1 rejected")
+		}
+	}
+}
@@ -26,59 +26,295 @@ const minEphemeralKeySize = 32
 // for a salt created with NewSalt.
 const SaltLength = 12
 
+// RecommendedSaltLength is the length of the salts created
+// by [Params.NewSalt] for password hashing functions such as
+// Argon2 or scrypt, as recommended by RFC 9106.
+const RecommendedSaltLength = 16
+
 // NewSalt returns a new random salt
 // using rand.Reader.
+//
+// Use [Params.NewSalt] to create a salt whose length
+// suits the key derivation function of the params.
 func NewSalt() []byte {
 	return randomKey(SaltLength)
 }
 
-// computeM1 computes the value of the client proof M1.
+// NewSaltLen returns a new random salt of n bytes
+// using rand.Reader.
+//
+// NewSaltLen panics if n is not positive.
+func NewSaltLen(n int) []byte {
+	if n <= 0 {
+		panic(errors.New("salt length must be positive"))
+	}
+	return randomKey(n)
+}
+
+// NewSaltFromReader returns a new salt of n bytes
+// read from r.
+func NewSaltFromReader(r io.Reader, n int) ([]byte, error) {
+	if n <= 0 {
+		return nil, errors.New("salt length must be positive")
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("failed to read salt: %w", err)
+	}
+	return b, nil
+}
+
+// SaltLength returns the recommended length of the salts
+// used with p.
+//
+// It is [Params.SaltWidth] when set, [SaltLength] for
+// [RFC5054KDF] and [ThinbusKDF], which are provided for
+// compatibility, and [RecommendedSaltLength] for any other
+// key derivation function.
+func (p *Params) SaltLength() int {
+	if p.SaltWidth > 0 {
+		return p.SaltWidth
+	}
+	if p.KDF == nil || sameFunc(p.KDF, RFC5054KDF) || sameFunc(p.KDF, ThinbusKDF) {
+		return SaltLength
+	}
+	return RecommendedSaltLength
+}
+
+// checkSalt returns an error if salt is longer than the
+// salt width of p.
+func (p *Params) checkSalt(salt []byte) error {
+	if p.SaltWidth > 0 && len(salt) > p.SaltWidth {
+		return fmt.Errorf("salt is longer than %d bytes", p.SaltWidth)
+	}
+	return nil
+}
+
+// NewSalt returns a new random salt of the recommended
+// length for p (see [Params.SaltLength]).
+func (p *Params) NewSalt() []byte {
+	return randomKey(p.SaltLength())
+}
+
+// computeM1 computes the value of the client proof M1
+// according to the proof scheme of params.
+//
+// The binding is only included when the client and the server
+// were configured with options that bind additional values
+// to the handshake (see [Option]).
+func computeM1(params *Params, username, salt []byte, A, B, S *big.Int, K, binding []byte) (*big.Int, error) {
+	if params.Compat == CompatThinbus {
+		return computeM1Thinbus(params, A, B, S, binding), nil
+	}
+
+	switch params.Proof {
+	case ProofRFC2945:
+		return computeM1RFC2945(params, username, salt, A, B, K, binding)
+	case ProofSRP6a:
+		return computeM1SRP6a(params, A, B, S, binding)
+	default:
+		return nil, fmt.Errorf("unknown proof scheme %d", params.Proof)
+	}
+}
+
+// computeM1RFC2945 computes the value of the client proof M1
+// as defined in [RFC2945].
 //
 // Formula:
 //
-//	M1 = H(H(N) XOR H(g) | H(U) | s | A | B | K)
-func computeM1(params *Params, username, salt []byte, A, B *big.Int, K []byte) (*big.Int, error) {
+//	M1 = H(H(N) XOR H(g) | H(U) | s | A | B | K [| binding])
+//
+// A and B are padded to the length of N with [PadAll], and s
+// to [Params.SaltWidth] when set.
+//
+// [RFC2945]: https://datatracker.ietf.org/doc/html/rfc2945
+func computeM1RFC2945(params *Params, username, salt []byte, A, B *big.Int, K, binding []byte) (*big.Int, error) {
+	h := newHasher(params)
+
+	// H(N), H(g) and H(U) share a single array.
+	size := h.Size()
+	digests := make([]byte, 0, 3*size)
+	h.Write(params.Group.N.Bytes())
+	digests = h.sumInto(digests)
+	h.Write(params.Group.Generator.Bytes())
+	digests = h.sumInto(digests)
+	h.Write(username)
+	digests = h.sumInto(digests)
+
 	var (
-		hN = params.hashBytes(params.Group.N.Bytes())
-		hg = params.hashBytes(params.Group.Generator.Bytes())
-		hU = params.hashBytes(username)
+		hN = digests[:size]
+		hg = digests[size : 2*size]
+		hU = digests[2*size:]
 	)
-
-	h := params.Hash.New()
-	{
-		groupXOR := make([]byte, len(hN))
-		n := subtle.XORBytes(groupXOR, hN, hg)
-		h.Write(groupXOR[:n])
+	subtle.XORBytes(hN, hN, hg)
+
+	if params.SaltWidth > 0 {
+		if err := params.checkSalt(salt); err != nil {
+			return nil, err
+		}
+		padded, err := pad(salt, 8*params.SaltWidth)
+		if err != nil {
+			return nil, err
+		}
+		salt = padded
 	}
+
+	h.Write(hN)
 	h.Write(hU)
 	h.Write(salt)
-	h.Write(A.Bytes())
-	h.Write(B.Bytes())
+	if err := h.writeInt(A, params.Padding == PadAll); err != nil {
+		return nil, err
+	}
+	if err := h.writeInt(B, params.Padding == PadAll); err != nil {
+		return nil, err
+	}
 	h.Write(K)
-	digest := h.Sum(nil)[:h.Size()]
+	if binding != nil {
+		h.Write(binding)
+	}
+	return h.sumInt(), nil
+}
 
-	return new(big.Int).SetBytes(digest), nil
+// computeM1SRP6a computes the value of the client proof M1
+// the way many SRP-6a implementations do.
+//
+// Formula:
+//
+//	M1 = H(A | B | S [| binding])
+//
+// A, B and S are padded to the length of N with [PadAll].
+func computeM1SRP6a(params *Params, A, B, S *big.Int, binding []byte) (*big.Int, error) {
+	h := newHasher(params)
+	for _, i := range [...]*big.Int{A, B, S} {
+		if err := h.writeInt(i, params.Padding == PadAll); err != nil {
+			return nil, err
+		}
+	}
+	if binding != nil {
+		h.Write(binding)
+	}
+	return h.sumInt(), nil
 }
 
-// computeM2 computes the value of the server proof M2.
+// computeM1Thinbus computes the value of the client proof M1
+// the way Thinbus does.
 //
 // Formula:
 //
-//	M2 = H(A | M | K)
-func computeM2(params *Params, A, M1 *big.Int, K []byte) (*big.Int, error) {
-	h := params.Hash.New()
-	h.Write(A.Bytes())
-	h.Write(M1.Bytes())
-	h.Write(K)
-	digest := h.Sum(nil)[:h.Size()]
-	return new(big.Int).SetBytes(digest), nil
+//	M1 = H(hex(A) | hex(B) | hex(S) [| binding])
+func computeM1Thinbus(params *Params, A, B, S *big.Int, binding []byte) *big.Int {
+	h := newHasher(params)
+	h.writeHex(A)
+	h.writeHex(B)
+	h.writeHex(S)
+	if binding != nil {
+		h.Write(binding)
+	}
+	return h.sumInt()
+}
+
+// computeM2 computes the value of the server proof M2
+// according to the proof scheme of params.
+//
+// Formula:
+//
+//	M2 = H(A | M | K)                (ProofRFC2945)
+//	M2 = H(A | M | S)                (ProofSRP6a)
+//	M2 = H(hex(A) | hex(M) | hex(S)) (CompatThinbus)
+//
+// A and S are padded to the length of N with [PadAll].
+func computeM2(params *Params, A, M1, S *big.Int, K []byte) (*big.Int, error) {
+	h := newHasher(params)
+	if params.Compat == CompatThinbus {
+		h.writeHex(A)
+		h.writeHex(M1)
+		h.writeHex(S)
+		return h.sumInt(), nil
+	}
+
+	if err := h.writeInt(A, params.Padding == PadAll); err != nil {
+		return nil, err
+	}
+	if err := h.writeInt(M1, false); err != nil {
+		return nil, err
+	}
+	switch params.Proof {
+	case ProofRFC2945:
+		h.Write(K)
+	case ProofSRP6a:
+		if err := h.writeInt(S, params.Padding == PadAll); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown proof scheme %d", params.Proof)
+	}
+	return h.sumInt(), nil
+}
+
+// computeK computes the session key K from the premaster
+// secret S, according to the key derivation of params.
+//
+// Formula:
+//
+//	K = H(S)                 (KeyHash)
+//	K = SHA_Interleave(S)    (KeyInterleave)
+//	K = H(hex(S))            (CompatOnePassword, CompatThinbus)
+func computeK(params *Params, S *big.Int) ([]byte, error) {
+	if params.Compat == CompatOnePassword || params.Compat == CompatThinbus {
+		h := newHasher(params)
+		h.writeHex(S)
+		return h.sumInto(nil), nil
+	}
+
+	switch params.KeyDerivation {
+	case KeyHash:
+		h := newHasher(params)
+		if err := h.writeInt(S, false); err != nil {
+			return nil, err
+		}
+		return h.sumInto(nil), nil
+	case KeyInterleave:
+		return interleave(params, S.Bytes()), nil
+	default:
+		return nil, fmt.Errorf("unknown key derivation %d", params.KeyDerivation)
+	}
+}
+
+// interleave implements the SHA_Interleave function
+// defined in [RFC2945], using the hash of params.
+//
+// [RFC2945]: https://datatracker.ietf.org/doc/html/rfc2945#section-3.1
+func interleave(params *Params, T []byte) []byte {
+	// Leading zero bytes are removed, as well as
+	// the first byte if T has an odd length.
+	for len(T) > 0 && T[0] == 0 {
+		T = T[1:]
+	}
+	if len(T)%2 == 1 {
+		T = T[1:]
+	}
+
+	E := make([]byte, len(T)/2)
+	F := make([]byte, len(T)/2)
+	for i := range E {
+		E[i] = T[2*i]
+		F[i] = T[2*i+1]
+	}
+
+	G := params.hashBytes(E)
+	H := params.hashBytes(F)
+	result := make([]byte, 0, len(G)+len(H))
+	for i := range G {
+		result = append(result, G[i], H[i])
+	}
+	return result
 }
 
 // checkProof returns true if Mx (M1 or M2) is
 // equal to proof.
 func checkProof(Mx, proof []byte) bool {
-	result := subtle.ConstantTimeCompare(Mx, proof)
-	return (result == 1)
+	return ConstantTimeEqual(Mx, proof)
 }
 
 // computeK returns the encryption key
@@ -87,12 +323,19 @@ func checkProof(Mx, proof []byte) bool {
 // Formula:
 //
 //	S = (A * v^u) ^ b % N
-func computeServerS(params *Params, v, u, A, b *big.Int) (*big.Int, error) {
+//
+// If hardened is true, the secret exponents are blinded
+// (see [WithHardening]).
+func computeServerS(params *Params, v, u, A, b *big.Int, hardened bool) (*big.Int, error) {
 	base := new(big.Int)
 	base.Exp(v, u, params.Group.N)
 	base.Mul(base, A)
 
-	S := new(big.Int).Exp(base, b, params.Group.N)
+	S, err := expMod(params, base, b, hardened)
+	if err != nil {
+		return nil, err
+	}
+	assertInRange(params, "S", S)
 	return S, nil
 }
 
@@ -102,9 +345,16 @@ func computeServerS(params *Params, v, u, A, b *big.Int) (*big.Int, error) {
 // Formula:
 //
 //	S = (B - (k * g ^ x)) ^ (a + (u * x)) % N
-func computeClientS(params *Params, k, x, u, B, a *big.Int) (*big.Int, error) {
+//
+// If hardened is true, the secret exponents are blinded
+// (see [WithHardening]).
+func computeClientS(params *Params, k, x, u, B, a *big.Int, hardened bool) (*big.Int, error) {
 	// (k * g ^ x)
-	product := new(big.Int).Mul(k, new(big.Int).Exp(params.Group.Generator, x, params.Group.N))
+	gx, err := expMod(params, params.Group.Generator, x, hardened)
+	if err != nil {
+		return nil, err
+	}
+	product := new(big.Int).Mul(k, gx)
 
 	// (B - (k * g ^ x))
 	base := new(big.Int).Sub(B, product)
@@ -113,7 +363,11 @@ func computeClientS(params *Params, k, x, u, B, a *big.Int) (*big.Int, error) {
 	exp := new(big.Int).Add(a, new(big.Int).Mul(u, x))
 
 	// (B - (k * g ^ x)) ^ (a + (u * x)) % N
-	S := new(big.Int).Exp(base, exp, params.Group.N)
+	S, err := expMod(params, base, exp, hardened)
+	if err != nil {
+		return nil, err
+	}
+	assertInRange(params, "S", S)
 	return S, nil
 }
 
@@ -122,46 +376,47 @@ func computeClientS(params *Params, k, x, u, B, a *big.Int) (*big.Int, error) {
 // Formula:
 //
 //	k = H(N | PAD(g))
+//	k = H(N | g)          (PadNone, CompatOnePassword)
 func computeLittleK(params *Params) (*big.Int, error) {
-	g, err := pad(params.Group.Generator.Bytes(), params.Group.N.BitLen())
-	if err != nil {
+	padded := params.Padding != PadNone && params.Compat != CompatOnePassword
+
+	h := newHasher(params)
+	if err := h.writeInt(params.Group.N, false); err != nil {
+		return nil, err
+	}
+	if err := h.writeInt(params.Group.Generator, padded); err != nil {
 		return nil, fmt.Errorf("failed to pad g")
 	}
-
-	h := params.Hash.New()
-	h.Write(params.Group.N.Bytes())
-	h.Write(g)
-
-	digest := h.Sum(nil)[:h.Size()]
-	return new(big.Int).SetBytes(digest), nil
+	return h.sumInt(), nil
 }
 
 // computeLittleU computes the value of u.
 //
 // Formula:
 //
-//	u = SHA1(PAD(A) | PAD(B))
+//	u = H(PAD(A) | PAD(B))
+//	u = H(A | B)              (PadNone)
+//	u = H(hex(A) | hex(B))    (CompatOnePassword, CompatThinbus)
 func computeLittleU(params *Params, A, B *big.Int) (*big.Int, error) {
 	if A == nil {
 		return nil, errors.New("client public ephemeral A must be set first")
 	}
 
-	bA, err := pad(A.Bytes(), params.Group.N.BitLen())
-	if err != nil {
-		return nil, fmt.Errorf("failed to pad A: %w", err)
+	h := newHasher(params)
+	if params.Compat == CompatOnePassword || params.Compat == CompatThinbus {
+		h.writeHex(A)
+		h.writeHex(B)
+	} else {
+		if err := h.writeInt(A, params.Padding != PadNone); err != nil {
+			return nil, fmt.Errorf("failed to pad A: %w", err)
+		}
+		if err := h.writeInt(B, params.Padding != PadNone); err != nil {
+			return nil, fmt.Errorf("failed to pad B: %w", err)
+		}
 	}
 
-	bB, err := pad(B.Bytes(), params.Group.N.BitLen())
-	if err != nil {
-		return nil, fmt.Errorf("failed to pad B: %w", err)
-	}
-
-	h := params.Hash.New()
-	h.Write(bA)
-	h.Write(bB)
-
-	digest := h.Sum(nil)[:h.Size()]
-	u := new(big.Int).SetBytes(digest)
+	u := h.sumInt()
+	assertNonZero("u", u)
 	return u, nil
 }
 
@@ -172,27 +427,32 @@ func computeLittleU(params *Params, A, B *big.Int) (*big.Int, error) {
 //
 //	b = random()
 //	B = k*v + g^b % N
-func newServerKeyPair(params *Params, k, v *big.Int) (b *big.Int, B *big.Int) {
-	size := params.Group.ExponentSize
-	if params.Group.ExponentSize < minEphemeralKeySize {
-		size = minEphemeralKeySize
+//
+// If entropy is not nil, its output is mixed into b.
+func newServerKeyPair(params *Params, k, v *big.Int, entropy io.Reader) (b *big.Int, B *big.Int, err error) {
+	randKey, err := ephemeralKey(params, entropy)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	randKey := randomKey(size)
 	b = new(big.Int).SetBytes(randKey)
+	B = serverPublicKey(params, k, v, params.expGenerator(b))
+	return
+}
 
-	B = new(big.Int)
-	var (
-		term1 = new(big.Int)
-		term2 = new(big.Int)
-	)
-	term1.Mul(k, v)
-	term1.Mod(term1, params.Group.N)
-	term2.Exp(params.Group.Generator, b, params.Group.N)
-	B.Add(term1, term2)
+// serverPublicKey computes the server's public ephemeral
+// key B from gb = g^b % N.
+//
+// Formula:
+//
+//	B = (k*v + gb) % N
+func serverPublicKey(params *Params, k, v, gb *big.Int) *big.Int {
+	B := new(big.Int).Mul(k, v)
+	B.Mod(B, params.Group.N)
+	B.Add(B, gb)
 	B.Mod(B, params.Group.N)
 
-	return
+	assertInRange(params, "B", B)
+	return B
 }
 
 // newClientKeyPair creates a client's ephemeral key pair
@@ -202,16 +462,41 @@ func newServerKeyPair(params *Params, k, v *big.Int) (b *big.Int, B *big.Int) {
 //
 //	a = random()
 //	A = g^a % N
-func newClientKeyPair(params *Params) (a *big.Int, A *big.Int) {
+//
+// If entropy is not nil, its output is mixed into a.
+func newClientKeyPair(params *Params, entropy io.Reader) (a *big.Int, A *big.Int, err error) {
+	randKey, err := ephemeralKey(params, entropy)
+	if err != nil {
+		return nil, nil, err
+	}
+	a = new(big.Int).SetBytes(randKey)
+	A = params.expGenerator(a)
+	assertInRange(params, "A", A)
+	return
+}
+
+// ephemeralKey returns the random bytes of a private
+// ephemeral key.
+//
+// The bytes read from entropy, if not nil, are XOR-ed with
+// those read from rand.Reader. Provided the two sources are
+// independent, the result is at least as unpredictable as
+// the strongest of them.
+func ephemeralKey(params *Params, entropy io.Reader) ([]byte, error) {
 	size := params.Group.ExponentSize
 	if params.Group.ExponentSize < minEphemeralKeySize {
 		size = minEphemeralKeySize
 	}
 
-	randKey := randomKey(size)
-	a = new(big.Int).SetBytes(randKey)
-	A = new(big.Int).Exp(params.Group.Generator, a, params.Group.N)
-	return
+	key := randomKey(size)
+	if entropy != nil {
+		extra := make([]byte, size)
+		if _, err := io.ReadFull(entropy, extra); err != nil {
+			return nil, fmt.Errorf("failed to read additional entropy: %w", err)
+		}
+		subtle.XORBytes(key, key, extra)
+	}
+	return key, nil
 }
 
 // isValidEphemeral returns true if i is valid
@@ -239,10 +524,16 @@ func randomKey(length int) []byte {
 	return b
 }
 
+// hexBytes returns i as a lowercase hexadecimal
+// string without leading zeros.
+func hexBytes(i *big.Int) []byte {
+	return i.Append(nil, 16)
+}
+
 // pad left-pads b with zeros until it reaches the
-// desired length in bits.
+// desired length in bits, rounded up to the next byte.
 func pad(b []byte, bits int) ([]byte, error) {
-	length := bits / 8
+	length := (bits + 7) / 8
 	padding := length - len(b)
 	if padding < 0 {
 		return nil, errors.New("padding cannot be negative")
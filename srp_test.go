@@ -6,7 +6,10 @@ import (
 	_ "crypto/sha1"
 	_ "embed"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"math/big"
 	"testing"
 )
 
@@ -81,8 +84,44 @@ func assertNotNil(t *testing.T, name string, got []byte) {
 	}
 }
 
+// handshake runs a full session between c and s, and
+// returns an error if any of the steps fails, including when
+// a proof is rejected.
+func handshake(c *Client, s *Server) error {
+	if err := s.SetA(c.A()); err != nil {
+		return err
+	}
+	if err := c.SetB(s.B()); err != nil {
+		return err
+	}
+
+	M1, err := c.ComputeM1()
+	if err != nil {
+		return err
+	}
+	if ok, err := s.CheckM1(M1); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("client proof M1 rejected")
+	}
+
+	M2, err := s.ComputeM2()
+	if err != nil {
+		return err
+	}
+	if ok, err := c.CheckM2(M2); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("server proof M2 rejected")
+	}
+	return nil
+}
+
 func TestServerKeyPair(t *testing.T) {
-	b, B := newServerKeyPair(params, k, v)
+	b, B, err := newServerKeyPair(params, k, v, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if b == bigZero {
 		t.Fatal("b should not be bigZero")
 	}
@@ -93,7 +132,10 @@ func TestServerKeyPair(t *testing.T) {
 }
 
 func TestClientKeyPair(t *testing.T) {
-	a, A := newClientKeyPair(params)
+	a, A, err := newClientKeyPair(params, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if a == bigZero {
 		t.Fatal("a should not be bigZero")
 	}
@@ -130,7 +172,7 @@ func TestComputeLittleK(t *testing.T) {
 
 func TestComputeS(t *testing.T) {
 	t.Run("Server", func(t *testing.T) {
-		got, err := computeServerS(params, v, u, A, b)
+		got, err := computeServerS(params, v, u, A, b, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -139,7 +181,7 @@ func TestComputeS(t *testing.T) {
 	})
 
 	t.Run("Client", func(t *testing.T) {
-		got, err := computeClientS(params, k, x, u, B, a)
+		got, err := computeClientS(params, k, x, u, B, a, false)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -167,14 +209,309 @@ func TestComputeVerifier(t *testing.T) {
 }
 
 func TestComputeM(t *testing.T) {
-	M1, err := computeM1(params, I, salt.Bytes(), A, B, K)
+	M1, err := computeM1(params, I, salt.Bytes(), A, B, S, K, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := computeM2(params, A, M1, S, K); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestComputeMSRP6a(t *testing.T) {
+	params := &Params{
+		Group: RFC5054Group1024,
+		Hash:  crypto.SHA1,
+		KDF:   RFC5054KDF,
+		Proof: ProofSRP6a,
+	}
+
+	M1, err := computeM1(params, I, salt.Bytes(), A, B, S, K, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := crypto.SHA1.New()
+	h.Write(A.Bytes())
+	h.Write(B.Bytes())
+	h.Write(S.Bytes())
+	assertEqualBytes(t, "M1", h.Sum(nil), M1.Bytes())
+
+	M2, err := computeM2(params, A, M1, S, K)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Reset()
+	h.Write(A.Bytes())
+	h.Write(M1.Bytes())
+	h.Write(S.Bytes())
+	assertEqualBytes(t, "M2", h.Sum(nil), M2.Bytes())
+}
+
+func TestSessionProofScheme(t *testing.T) {
+	srp6a := &Params{
+		Group: RFC5054Group1024,
+		Hash:  crypto.SHA1,
+		KDF:   RFC5054KDF,
+		Proof: ProofSRP6a,
+	}
+
+	client, err := NewClient(srp6a, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(srp6a, string(I), salt.Bytes(), v.Bytes())
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
 
-	if _, err := computeM2(params, A, M1, K); err != nil {
+	// A client using the default scheme cannot
+	// authenticate against an SRP-6a server.
+	client, err = NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
 		t.Fatal(err)
 	}
+	server, err = NewServer(srp6a, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err == nil {
+		t.Fatal("expected proof schemes mismatch to fail")
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	T := []byte{0x00, 0x00, 0x01, 0x02, 0x03, 0x04, 0x05}
+
+	// Leading zeros and the first byte (odd length)
+	// are dropped: E = 02 04, F = 03 05.
+	var (
+		G = crypto.SHA1.New()
+		H = crypto.SHA1.New()
+	)
+	G.Write([]byte{0x02, 0x04})
+	H.Write([]byte{0x03, 0x05})
+	g, h := G.Sum(nil), H.Sum(nil)
+
+	wanted := make([]byte, 0, 2*len(g))
+	for i := range g {
+		wanted = append(wanted, g[i], h[i])
+	}
+
+	assertEqualBytes(t, "SHA_Interleave", wanted, interleave(params, T))
+}
+
+func TestSessionKeyInterleave(t *testing.T) {
+	params := &Params{
+		Group:         RFC5054Group1024,
+		Hash:          crypto.SHA1,
+		KDF:           RFC5054KDF,
+		KeyDerivation: KeyInterleave,
+	}
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	cK, err := client.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sK, err := server.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "K", cK, sK)
+	if len(cK) != 2*crypto.SHA1.Size() {
+		t.Fatalf("expected a %d-byte key, got %d", 2*crypto.SHA1.Size(), len(cK))
+	}
+	assertEqualBytes(t, "K", interleave(params, client.xS.Bytes()), cK)
+}
+
+func TestPadding(t *testing.T) {
+	padded := func(i []byte) []byte {
+		b, err := pad(i, RFC5054Group1024.N.BitLen())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	hash := func(values ...[]byte) []byte {
+		h := crypto.SHA1.New()
+		for _, v := range values {
+			h.Write(v)
+		}
+		return h.Sum(nil)
+	}
+
+	t.Run("None", func(t *testing.T) {
+		params := &Params{Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF, Padding: PadNone}
+
+		gotK, err := computeLittleK(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, "k", hash(params.Group.N.Bytes(), params.Group.Generator.Bytes()), gotK.Bytes())
+
+		gotU, err := computeLittleU(params, A, B)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, "u", hash(A.Bytes(), B.Bytes()), gotU.Bytes())
+	})
+
+	t.Run("All", func(t *testing.T) {
+		params := &Params{Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF, Padding: PadAll, Proof: ProofSRP6a}
+
+		// k and u are computed as in RFC 5054.
+		gotK, err := computeLittleK(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, "k", k.Bytes(), gotK.Bytes())
+
+		gotU, err := computeLittleU(params, A, B)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, "u", u.Bytes(), gotU.Bytes())
+
+		// A short value is padded in the proofs.
+		short := big.NewInt(0xabcdef)
+		M1, err := computeM1(params, I, salt.Bytes(), short, B, S, K, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, "M1", hash(padded(short.Bytes()), padded(B.Bytes()), padded(S.Bytes())), M1.Bytes())
+
+		M2, err := computeM2(params, short, M1, S, K)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualBytes(t, "M2", hash(padded(short.Bytes()), M1.Bytes(), padded(S.Bytes())), M2.Bytes())
+	})
+}
+
+func TestPaddedProofsLeadingZeros(t *testing.T) {
+	params := &Params{Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF, Padding: PadAll}
+	size := (params.Group.N.BitLen() + 7) / 8
+
+	// About 1 in 256 public keys starts with a zero byte.
+	var client *Client
+	for i := 0; i < 10000 && client == nil; i++ {
+		c, err := NewClient(params, string(I), string(P), salt.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(c.A()) < size {
+			client = c
+		}
+	}
+	if client == nil {
+		t.Fatal("failed to generate a public key with a leading zero byte")
+	}
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	// The proof differs from the one hashing the stripped encoding of A.
+	unpadded := *params
+	unpadded.Padding = PadRFC5054
+	M1, err := computeM1(&unpadded, client.username, client.salt, client.xA, client.xB, client.xS, client.xK, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if M1.Cmp(client.m1) == 0 {
+		t.Fatal("A was not padded in M1")
+	}
+}
+
+func TestHasherWriteIntAfterHex(t *testing.T) {
+	size := (params.Group.N.BitLen() + 7) / 8
+	one := big.NewInt(1)
+
+	// writeHex and sumInt reuse the scratch buffer, which must
+	// not change the length integers are padded to.
+	h := newHasher(params)
+	h.writeHex(one)
+	h.sumInt()
+	if err := h.writeInt(one, true); err != nil {
+		t.Fatal(err)
+	}
+
+	want := params.Hash.New()
+	want.Write(one.FillBytes(make([]byte, size)))
+	assertEqualBytes(t, "H(PAD(1))", want.Sum(nil), h.Sum(nil))
+}
+
+func TestSaltWidth(t *testing.T) {
+	params := &Params{Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF, SaltWidth: 16}
+	if n := len(params.NewSalt()); n != 16 {
+		t.Fatalf("expected a 16-byte salt, got %d", n)
+	}
+
+	// A salt starting with zeros, and the same salt once
+	// stripped by an implementation storing it as an integer.
+	full := append([]byte{0, 0}, bytes.Repeat([]byte{0x5a}, 14)...)
+	stripped := full[2:]
+
+	M1, err := computeM1(params, I, full, A, B, S, K, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	M1Stripped, err := computeM1(params, I, stripped, A, B, S, K, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "M1", M1.Bytes(), M1Stripped.Bytes())
+
+	long := make([]byte, 17)
+	if _, err := computeM1(params, I, long, A, B, S, K, nil); err == nil {
+		t.Fatal("expected an error for a salt longer than SaltWidth")
+	}
+	if _, err := NewClient(params, string(I), string(P), long); err == nil {
+		t.Fatal("expected the client to reject a salt longer than SaltWidth")
+	}
+	if _, err := NewClientFromSecret(params, string(I), x.Bytes(), long); err == nil {
+		t.Fatal("expected the client to reject a salt longer than SaltWidth")
+	}
+	if _, err := NewServer(params, string(I), long, v.Bytes()); err == nil {
+		t.Fatal("expected the server to reject a salt longer than SaltWidth")
+	}
+}
+
+func TestSessionPadding(t *testing.T) {
+	for _, padding := range []Padding{PadRFC5054, PadAll, PadNone} {
+		params := &Params{Group: RFC5054Group1024, Hash: crypto.SHA1, KDF: RFC5054KDF, Padding: padding}
+
+		client, err := NewClient(params, string(I), string(P), salt.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := handshake(client, server); err != nil {
+			t.Fatalf("padding %d: %v", padding, err)
+		}
+	}
 }
 
 func TestNewServer(t *testing.T) {
@@ -256,7 +593,7 @@ func TestClientSetB(t *testing.T) {
 }
 
 func TestCheckM1(t *testing.T) {
-	M1, err := computeM1(params, I, salt.Bytes(), A, B, K)
+	M1, err := computeM1(params, I, salt.Bytes(), A, B, S, K, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -351,6 +688,61 @@ func TestNewSalt(t *testing.T) {
 	}
 }
 
+func TestNewSaltLen(t *testing.T) {
+	if b := NewSaltLen(32); len(b) != 32 {
+		t.Fatalf("wanted 32 bytes, got %d", len(b))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected NewSaltLen to panic")
+		}
+	}()
+	NewSaltLen(0)
+}
+
+func TestNewSaltFromReader(t *testing.T) {
+	b, err := NewSaltFromReader(constantReader(7), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "salt", bytes.Repeat([]byte{7}, 16), b)
+
+	if _, err := NewSaltFromReader(bytes.NewReader([]byte{1, 2}), 16); err == nil {
+		t.Fatal("expected a short reader to fail")
+	}
+	if _, err := NewSaltFromReader(constantReader(7), 0); err == nil {
+		t.Fatal("expected an empty salt to be rejected")
+	}
+}
+
+func TestParamsSaltLength(t *testing.T) {
+	argon2 := func(username, password string, salt []byte) ([]byte, error) {
+		return nil, nil
+	}
+
+	tests := []struct {
+		name   string
+		params *Params
+		wanted int
+	}{
+		{"RFC5054KDF", params, SaltLength},
+		{"ThinbusKDF", ThinbusProfile, SaltLength},
+		{"AppleKDF", AppleProfile, RecommendedSaltLength},
+		{"Custom", &Params{Group: RFC5054Group2048, Hash: crypto.SHA256, KDF: argon2}, RecommendedSaltLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n := tt.params.SaltLength(); n != tt.wanted {
+				t.Fatalf("wanted %d, got %d", tt.wanted, n)
+			}
+			if b := tt.params.NewSalt(); len(b) != tt.wanted {
+				t.Fatalf("wanted a %d-byte salt, got %d bytes", tt.wanted, len(b))
+			}
+		})
+	}
+}
+
 // Send is a noop used for examples.
 func Send(any) {}
 
@@ -531,3 +923,113 @@ func ExampleParams() {
 		log.Fatal(err)
 	}
 }
+
+func BenchmarkHandshake(bench *testing.B) {
+	groups := []struct {
+		name  string
+		group *Group
+	}{
+		{"1024", RFC5054Group1024},
+		{"2048", RFC5054Group2048},
+		{"3072", RFC5054Group3072},
+		{"4096", RFC5054Group4096},
+		{"8192", RFC5054Group8192},
+	}
+
+	for _, g := range groups {
+		p := &Params{Group: g.group, Hash: crypto.SHA256, KDF: params.KDF}
+		tp, err := ComputeVerifier(p, string(I), string(P), salt.Bytes())
+		if err != nil {
+			bench.Fatal(err)
+		}
+
+		bench.Run(g.name, func(bench *testing.B) {
+			bench.ReportAllocs()
+			for i := 0; i < bench.N; i++ {
+				client, err := NewClient(p, string(I), string(P), tp.Salt())
+				if err != nil {
+					bench.Fatal(err)
+				}
+				server, err := NewServer(p, string(I), tp.Salt(), tp.Verifier())
+				if err != nil {
+					bench.Fatal(err)
+				}
+				if err := handshake(client, server); err != nil {
+					bench.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkProofs(bench *testing.B) {
+	for _, padding := range []Padding{PadRFC5054, PadAll} {
+		p := *params
+		p.Padding = padding
+
+		bench.Run(fmt.Sprintf("Padding%d", padding), func(bench *testing.B) {
+			bench.ReportAllocs()
+			for i := 0; i < bench.N; i++ {
+				if _, err := computeLittleU(&p, A, B); err != nil {
+					bench.Fatal(err)
+				}
+				K, err := computeK(&p, S)
+				if err != nil {
+					bench.Fatal(err)
+				}
+				M1, err := computeM1(&p, I, salt.Bytes(), A, B, S, K, nil)
+				if err != nil {
+					bench.Fatal(err)
+				}
+				if _, err := computeM2(&p, A, M1, S, K); err != nil {
+					bench.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestNewClientFromSecret(t *testing.T) {
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := client.ExportSecret()
+	assertEqualBytes(t, "x", x.Bytes(), secret)
+
+	// The KDF is not called again.
+	noKDF := *params
+	noKDF.KDF = func(username, password string, salt []byte) ([]byte, error) {
+		t.Fatal("unexpected call to the KDF")
+		return nil, nil
+	}
+
+	cached, err := NewClientFromSecret(&noKDF, string(I), secret, salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(&noKDF, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(cached, server); err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "exported secret", secret, cached.ExportSecret())
+
+	wrong, err := NewClientFromSecret(params, string(I), []byte("wrong secret"), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err = NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(wrong, server); err == nil {
+		t.Fatal("expected a wrong secret to be rejected")
+	}
+
+	if _, err := NewClientFromSecret(params, string(I), nil, salt.Bytes()); err == nil {
+		t.Fatal("expected an empty secret to be rejected")
+	}
+}
@@ -0,0 +1,254 @@
+package srp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrThrottled is returned when a user attempts to
+// authenticate again too soon after failed attempts.
+var ErrThrottled = errors.New("too many failed attempts")
+
+// ThrottleError is returned by a [ThrottlePolicy] to reject an
+// attempt to authenticate. It wraps [ErrThrottled].
+type ThrottleError struct {
+	Username   string        // User whose attempt was rejected
+	Failures   int           // Number of consecutive failed attempts
+	RetryAfter time.Duration // Time to wait before the next attempt
+}
+
+// Error implements the error interface.
+func (e *ThrottleError) Error() string {
+	return fmt.Sprintf("%v for %q, retry after %v", ErrThrottled, e.Username, e.RetryAfter)
+}
+
+// Unwrap returns [ErrThrottled].
+func (e *ThrottleError) Unwrap() error {
+	return ErrThrottled
+}
+
+// ThrottlePolicy decides whether users may attempt to
+// authenticate, given their previous failed attempts, to
+// protect them against online brute-force attacks.
+//
+// The servers configured with [WithThrottle] call Begin before
+// verifying a client proof, and the function it returns once
+// the proof was verified. Fake servers (see [NewFakeServer])
+// do as well, so that unknown users are throttled like
+// existing ones.
+//
+// The methods are called synchronously, while the server is
+// locked: they must not block, nor call methods of the server.
+// Implementations must be safe for concurrent use.
+type ThrottlePolicy interface {
+	// Check returns an error, typically a [ThrottleError], if
+	// username may not attempt to authenticate yet. It does
+	// not reserve an attempt.
+	Check(username string) error
+
+	// Begin reserves an attempt of username, and returns a
+	// function reporting its outcome, which must be called
+	// exactly once. It returns an error instead, typically a
+	// [ThrottleError], if username may not attempt to
+	// authenticate yet.
+	//
+	// An attempt is counted from the moment it is reserved,
+	// so that concurrent attempts of a user cannot all be
+	// allowed before the first one fails.
+	Begin(username string) (done func(success bool), err error)
+}
+
+// WithThrottle makes servers consult p before verifying client
+// proofs, and report the outcome to p. It does not need to
+// match on both sides.
+//
+// [Server.CheckM1] returns the error of p.Begin without
+// verifying the proof when an attempt is rejected, and the
+// server is left unchanged, so the proof can be checked again
+// once the policy allows it.
+func WithThrottle(p ThrottlePolicy) Option {
+	return func(o *options) {
+		o.throttle = p
+	}
+}
+
+// minBackoffPurge is the number of users a BackoffThrottle
+// holds before it purges them as failures are recorded.
+const minBackoffPurge = 1024
+
+// BackoffThrottle is a [ThrottlePolicy] keeping the number of
+// consecutive failed attempts of each user in memory, and
+// delaying their next attempt exponentially: after n failures,
+// a user must wait base * 2^(n-1), up to max, since their last
+// failed attempt. A successful attempt resets the count.
+//
+// A user has at most one attempt in flight: the attempts
+// begun while another one is in flight are rejected.
+//
+// Users are forgotten max after their last failed attempt.
+// They are purged as failures are recorded, each time the
+// number of users held doubles, or when
+// [BackoffThrottle.Purge] is called.
+type BackoffThrottle struct {
+	base time.Duration
+	max  time.Duration
+
+	mu      sync.Mutex
+	users   map[string]backoffEntry
+	purgeAt int // Number of users from which the next failure purges them
+}
+
+// backoffEntry holds the failed attempts of a user.
+type backoffEntry struct {
+	failures int
+	last     time.Time // Time of the last failed attempt
+	inflight bool      // Whether an attempt is in flight
+}
+
+// NewBackoffThrottle returns a new BackoffThrottle delaying
+// attempts by base after a first failure, and by at most max.
+func NewBackoffThrottle(base, max time.Duration) *BackoffThrottle {
+	if base <= 0 || max < base {
+		panic(errors.New("invalid backoff durations"))
+	}
+	return &BackoffThrottle{
+		base:    base,
+		max:     max,
+		users:   make(map[string]backoffEntry),
+		purgeAt: minBackoffPurge,
+	}
+}
+
+// Check implements [ThrottlePolicy].
+func (t *BackoffThrottle) Check(username string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.check(username, t.users[username])
+}
+
+// Begin implements [ThrottlePolicy].
+func (t *BackoffThrottle) Begin(username string) (func(success bool), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e := t.users[username]
+	if err := t.check(username, e); err != nil {
+		return nil, err
+	}
+	e.inflight = true
+	t.users[username] = e
+
+	var once sync.Once
+	return func(success bool) {
+		once.Do(func() { t.record(username, success) })
+	}, nil
+}
+
+// check returns a ThrottleError if the user of e may
+// not attempt to authenticate yet.
+func (t *BackoffThrottle) check(username string, e backoffEntry) error {
+	var wait time.Duration
+	switch {
+	case e.inflight:
+		// Until the attempt in flight completes, the next one
+		// waits as long as if the attempt had failed.
+		wait = t.delay(e.failures + 1)
+	case e.failures > 0:
+		wait = e.last.Add(t.delay(e.failures)).Sub(now())
+	}
+	if wait <= 0 {
+		return nil
+	}
+	return &ThrottleError{
+		Username:   username,
+		Failures:   e.failures,
+		RetryAfter: wait,
+	}
+}
+
+// record reports the outcome of the attempt in
+// flight of username.
+func (t *BackoffThrottle) record(username string, success bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if success {
+		delete(t.users, username)
+		return
+	}
+	current := now()
+	t.users[username] = backoffEntry{
+		failures: t.users[username].failures + 1,
+		last:     current,
+	}
+	if len(t.users) >= t.purgeAt {
+		t.purge(current)
+		t.purgeAt = 2 * len(t.users)
+		if t.purgeAt < minBackoffPurge {
+			t.purgeAt = minBackoffPurge
+		}
+	}
+}
+
+// Failures returns the number of consecutive failed
+// attempts of username.
+func (t *BackoffThrottle) Failures(username string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.users[username].failures
+}
+
+// Purge forgets the users whose last failed attempt is
+// older than max.
+func (t *BackoffThrottle) Purge() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.purge(now())
+}
+
+// purge forgets the users whose last failed attempt is older
+// than max at current, and who have no attempt in flight.
+func (t *BackoffThrottle) purge(current time.Time) {
+	for username, e := range t.users {
+		if !e.inflight && current.Sub(e.last) >= t.max {
+			delete(t.users, username)
+		}
+	}
+}
+
+// delay returns the time to wait after the given
+// number of failures.
+func (t *BackoffThrottle) delay(failures int) time.Duration {
+	d := t.base
+	for i := 1; i < failures && d < t.max; i++ {
+		d *= 2
+	}
+	if d > t.max {
+		d = t.max
+	}
+	return d
+}
+
+// checkThrottle returns the error of the throttle policy of o,
+// if any, for username.
+func (o *options) checkThrottle(username string) error {
+	if o.throttle == nil {
+		return nil
+	}
+	return o.throttle.Check(username)
+}
+
+// beginThrottle reserves an attempt of username with the
+// throttle policy of o, if any, and returns the function
+// reporting its outcome.
+func (o *options) beginThrottle(username string) (func(success bool), error) {
+	if o.throttle == nil {
+		return func(bool) {}, nil
+	}
+	return o.throttle.Begin(username)
+}
@@ -0,0 +1,252 @@
+package srp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// attempt begins an attempt of username with throttle,
+// and reports it with the given outcome.
+func attempt(t *testing.T, throttle ThrottlePolicy, username string, success bool) {
+	t.Helper()
+	done, err := throttle.Begin(username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done(success)
+}
+
+func TestBackoffThrottle(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	setNow(t, start)
+
+	throttle := NewBackoffThrottle(time.Second, 10*time.Second)
+	if err := throttle.Check("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		failures int
+		wait     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{6, 10 * time.Second},
+	}
+	current := start
+	for _, tt := range tests {
+		attempt(t, throttle, "alice", false)
+		if n := throttle.Failures("alice"); n != tt.failures {
+			t.Fatalf("expected %d failures, got %d", tt.failures, n)
+		}
+
+		var throttled *ThrottleError
+		if err := throttle.Check("alice"); !errors.As(err, &throttled) || !errors.Is(err, ErrThrottled) {
+			t.Fatalf("expected a ThrottleError, got %v", err)
+		}
+		if throttled.RetryAfter != tt.wait || throttled.Failures != tt.failures {
+			t.Fatalf("expected to wait %v after %d failures, got %v after %d", tt.wait, tt.failures, throttled.RetryAfter, throttled.Failures)
+		}
+		if _, err := throttle.Begin("alice"); !errors.Is(err, ErrThrottled) {
+			t.Fatalf("expected ErrThrottled, got %v", err)
+		}
+		if err := throttle.Check("bob"); err != nil {
+			t.Fatalf("expected other users to be allowed, got %v", err)
+		}
+
+		current = current.Add(tt.wait)
+		setNow(t, current)
+		if err := throttle.Check("alice"); err != nil {
+			t.Fatalf("expected an attempt to be allowed after %v, got %v", tt.wait, err)
+		}
+	}
+
+	attempt(t, throttle, "alice", true)
+	if n := throttle.Failures("alice"); n != 0 {
+		t.Fatalf("expected a success to reset the failures, got %d", n)
+	}
+
+	attempt(t, throttle, "alice", false)
+	throttle.Purge()
+	if n := throttle.Failures("alice"); n != 1 {
+		t.Fatalf("expected a recent failure to be kept, got %d", n)
+	}
+	setNow(t, current.Add(time.Minute))
+	throttle.Purge()
+	if n := throttle.Failures("alice"); n != 0 {
+		t.Fatalf("expected an old failure to be purged, got %d", n)
+	}
+}
+
+func TestBackoffThrottleInFlight(t *testing.T) {
+	throttle := NewBackoffThrottle(time.Minute, time.Hour)
+
+	done, err := throttle.Begin("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var throttled *ThrottleError
+	if _, err := throttle.Begin("alice"); !errors.As(err, &throttled) || throttled.RetryAfter != time.Minute {
+		t.Fatalf("expected a ThrottleError retrying after %v, got %v", time.Minute, err)
+	}
+	if err := throttle.Check("alice"); !errors.Is(err, ErrThrottled) {
+		t.Fatalf("expected ErrThrottled, got %v", err)
+	}
+
+	// An attempt in flight is not purged.
+	throttle.Purge()
+	done(true)
+	done(false)
+	if n := throttle.Failures("alice"); n != 0 {
+		t.Fatalf("expected an outcome to be reported once, got %d failures", n)
+	}
+	attempt(t, throttle, "alice", true)
+}
+
+func TestBackoffThrottleConcurrent(t *testing.T) {
+	throttle := NewBackoffThrottle(time.Minute, time.Hour)
+
+	var (
+		allowed atomic.Int64
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := throttle.Begin("alice")
+			if err != nil {
+				return
+			}
+			allowed.Add(1)
+			done(false)
+		}()
+	}
+	wg.Wait()
+
+	// However the attempts interleave, only the first one
+	// is allowed: the others are either in flight with it,
+	// or follow its failure.
+	if n := allowed.Load(); n != 1 {
+		t.Fatalf("expected a single attempt to be allowed, got %d", n)
+	}
+	if n := throttle.Failures("alice"); n != 1 {
+		t.Fatalf("expected 1 failure, got %d", n)
+	}
+}
+
+func TestBackoffThrottlePurge(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	setNow(t, start)
+
+	throttle := NewBackoffThrottle(time.Second, time.Minute)
+	for i := 0; i < minBackoffPurge-1; i++ {
+		attempt(t, throttle, fmt.Sprint("user", i), false)
+	}
+
+	// Failures recorded once the old ones expired
+	// purge them, without calling Purge.
+	setNow(t, start.Add(time.Minute))
+	attempt(t, throttle, "alice", false)
+	if n := len(throttle.users); n != 1 {
+		t.Fatalf("expected expired users to be purged, %d remain", n)
+	}
+	if throttle.purgeAt != minBackoffPurge {
+		t.Fatalf("expected to purge again from %d users, got %d", minBackoffPurge, throttle.purgeAt)
+	}
+}
+
+func TestServerThrottle(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	setNow(t, start)
+
+	throttle := NewBackoffThrottle(time.Minute, time.Hour)
+	attempt := func(password string) (*Client, *Server, error) {
+		client, err := NewClient(params, string(I), password, salt.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithThrottle(throttle))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client, server, handshake(client, server)
+	}
+
+	if _, _, err := attempt("wrong password"); err == nil || errors.Is(err, ErrThrottled) {
+		t.Fatalf("expected the proof to be rejected, got %v", err)
+	}
+	if n := throttle.Failures(string(I)); n != 1 {
+		t.Fatalf("expected 1 failure, got %d", n)
+	}
+
+	// The right password is rejected too until the delay elapses,
+	// without consuming the server.
+	client, server, err := attempt(string(P))
+	if !errors.Is(err, ErrThrottled) {
+		t.Fatalf("expected ErrThrottled, got %v", err)
+	}
+	if server.err != nil || server.verifiedM1 {
+		t.Fatal("expected a throttled server to be unchanged")
+	}
+
+	setNow(t, start.Add(time.Minute))
+	M1, err := client.ComputeM1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := server.CheckM1(M1); err != nil || !ok {
+		t.Fatalf("expected M1 to be verified, got %v, %v", ok, err)
+	}
+	if n := throttle.Failures(string(I)); n != 0 {
+		t.Fatalf("expected the failures to be reset, got %d", n)
+	}
+}
+
+func TestFakeServerThrottle(t *testing.T) {
+	throttle := NewBackoffThrottle(time.Minute, time.Hour)
+
+	client, err := NewClient(params, "unknown", string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewFakeServer(params, "unknown", fakeSecret, WithThrottle(throttle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err == nil {
+		t.Fatal("expected the handshake to fail")
+	}
+	if n := throttle.Failures("unknown"); n != 1 {
+		t.Fatalf("expected unknown users to be throttled, got %d failures", n)
+	}
+}
+
+func TestSessionManagerThrottle(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		throttle = NewBackoffThrottle(time.Minute, time.Hour)
+		manager  = NewSessionManager(params, NewMemoryStore(), time.Minute, WithThrottle(throttle))
+	)
+
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Create(ctx, server); err != nil {
+		t.Fatal(err)
+	}
+
+	attempt(t, throttle, string(I), false)
+	if _, err := manager.Create(ctx, server); !errors.Is(err, ErrThrottled) {
+		t.Fatalf("expected ErrThrottled, got %v", err)
+	}
+}
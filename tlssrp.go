@@ -0,0 +1,267 @@
+package srp
+
+import (
+	"crypto"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// TLSExtensionSRP is the type of the SRP extension of the
+// ClientHello.
+const TLSExtensionSRP uint16 = 12
+
+// TLS-SRP cipher suites defined in RFC 5054.
+const (
+	TLS_SRP_SHA_WITH_3DES_EDE_CBC_SHA     uint16 = 0xC01A
+	TLS_SRP_SHA_RSA_WITH_3DES_EDE_CBC_SHA uint16 = 0xC01B
+	TLS_SRP_SHA_DSS_WITH_3DES_EDE_CBC_SHA uint16 = 0xC01C
+	TLS_SRP_SHA_WITH_AES_128_CBC_SHA      uint16 = 0xC01D
+	TLS_SRP_SHA_RSA_WITH_AES_128_CBC_SHA  uint16 = 0xC01E
+	TLS_SRP_SHA_DSS_WITH_AES_128_CBC_SHA  uint16 = 0xC01F
+	TLS_SRP_SHA_WITH_AES_256_CBC_SHA      uint16 = 0xC020
+	TLS_SRP_SHA_RSA_WITH_AES_256_CBC_SHA  uint16 = 0xC021
+	TLS_SRP_SHA_DSS_WITH_AES_256_CBC_SHA  uint16 = 0xC022
+)
+
+// tlsGroups lists the groups TLS-SRP clients accept,
+// from RFC 5054, Appendix A.
+var tlsGroups = []*Group{
+	RFC5054Group1024,
+	RFC5054Group1536,
+	RFC5054Group2048,
+	RFC5054Group3072,
+	RFC5054Group4096,
+	RFC5054Group6144,
+	RFC5054Group8192,
+}
+
+// TLSParams returns the params TLS-SRP uses with group: SHA-1,
+// with the [RFC5054KDF] and the padding of RFC 5054.
+//
+// TLS-SRP, defined in [RFC5054], authenticates the TLS handshake
+// with SRP instead of certificates. The standard library's
+// crypto/tls does not support it, so this package only provides
+// the pieces a TLS implementation needs (e.g. a fork of
+// crypto/tls, or uTLS on the client side):
+//
+//  1. The client sends its username in the SRP extension of the
+//     ClientHello ([TLSExtensionSRP], [MarshalTLSExtension]).
+//  2. The server looks up the triplet of the user, creates a
+//     [Server] with [TLSParams], and sends N, g, s and B in its
+//     ServerKeyExchange ([TLSServerParams]).
+//  3. The client checks that N and g form a known group
+//     ([TLSServerParams.Group]), creates a [Client] with the
+//     salt, and sends A in its ClientKeyExchange
+//     ([MarshalTLSClientKeyExchange]).
+//  4. Both sides use S as the premaster secret
+//     ([Client.PremasterSecret], [Server.PremasterSecret]), from
+//     which the TLS stack derives the master secret.
+//
+// The proofs M1 and M2 are not used: the Finished messages of
+// TLS play their role.
+//
+// [RFC5054]: https://datatracker.ietf.org/doc/html/rfc5054
+func TLSParams(group *Group) *Params {
+	return &Params{
+		Name:  "tls-srp-" + group.ID,
+		Group: group,
+		Hash:  crypto.SHA1,
+		KDF:   RFC5054KDF,
+	}
+}
+
+// MarshalTLSExtension returns the data of the SRP extension
+// of the ClientHello, which carries the username:
+//
+//	opaque srp_I<1..2^8-1>;
+func MarshalTLSExtension(username string) ([]byte, error) {
+	if len(username) == 0 || len(username) > math.MaxUint8 {
+		return nil, fmt.Errorf("username must be 1 to %d bytes long", math.MaxUint8)
+	}
+	return append([]byte{byte(len(username))}, username...), nil
+}
+
+// ParseTLSExtension returns the username carried by the data
+// of an SRP extension.
+func ParseTLSExtension(data []byte) (string, error) {
+	username, rest, ok := readVector(data, 1)
+	if !ok || len(username) == 0 || len(rest) > 0 {
+		return "", errors.New("malformed SRP extension")
+	}
+	return string(username), nil
+}
+
+// TLSServerParams holds the SRP parameters sent by a server
+// in its ServerKeyExchange message:
+//
+//	struct {
+//	    opaque srp_N<1..2^16-1>;
+//	    opaque srp_g<1..2^16-1>;
+//	    opaque srp_s<1..2^8-1>;
+//	    opaque srp_B<1..2^16-1>;
+//	} ServerSRPParams;
+type TLSServerParams struct {
+	N    []byte
+	G    []byte
+	Salt []byte
+	B    []byte
+}
+
+// NewTLSServerParams returns the ServerKeyExchange
+// parameters of s.
+func NewTLSServerParams(s *Server) *TLSServerParams {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &TLSServerParams{
+		N:    s.params.Group.N.Bytes(),
+		G:    s.params.Group.Generator.Bytes(),
+		Salt: s.triplet.Salt(),
+		B:    s.xB.Bytes(),
+	}
+}
+
+// MarshalBinary encodes p as a ServerSRPParams structure.
+func (p *TLSServerParams) MarshalBinary() ([]byte, error) {
+	if len(p.Salt) == 0 || len(p.Salt) > math.MaxUint8 {
+		return nil, fmt.Errorf("salt must be 1 to %d bytes long", math.MaxUint8)
+	}
+
+	for _, v := range [][]byte{p.N, p.G, p.B} {
+		if len(v) == 0 || len(v) > math.MaxUint16 {
+			return nil, fmt.Errorf("values must be 1 to %d bytes long", math.MaxUint16)
+		}
+	}
+
+	var b []byte
+	b = appendVector(b, p.N, 2)
+	b = appendVector(b, p.G, 2)
+	b = appendVector(b, p.Salt, 1)
+	b = appendVector(b, p.B, 2)
+	return b, nil
+}
+
+// UnmarshalBinary decodes a ServerSRPParams structure.
+func (p *TLSServerParams) UnmarshalBinary(data []byte) error {
+	var (
+		values = make([][]byte, 4)
+		ok     = true
+	)
+	for i, lengthSize := range []int{2, 2, 1, 2} {
+		if values[i], data, ok = readVector(data, lengthSize); !ok || len(values[i]) == 0 {
+			return errors.New("malformed ServerSRPParams")
+		}
+	}
+	if len(data) > 0 {
+		return errors.New("malformed ServerSRPParams")
+	}
+
+	p.N, p.G, p.Salt, p.B = values[0], values[1], values[2], values[3]
+	return nil
+}
+
+// Group returns the group of RFC 5054, Appendix A formed by
+// N and g, or an error if they do not form one.
+//
+// RFC 5054 requires clients to reject other groups, as they
+// cannot check cheaply that they are safe.
+func (p *TLSServerParams) Group() (*Group, error) {
+	candidate := &Group{
+		N:         new(big.Int).SetBytes(p.N),
+		Generator: new(big.Int).SetBytes(p.G),
+	}
+	for _, g := range tlsGroups {
+		if sameGroup(g, candidate) {
+			return g, nil
+		}
+	}
+	return nil, errors.New("unknown TLS-SRP group")
+}
+
+// MarshalTLSClientKeyExchange returns the content of the
+// ClientKeyExchange message carrying the public key A of a
+// client:
+//
+//	opaque srp_A<1..2^16-1>;
+func MarshalTLSClientKeyExchange(A PublicKey) ([]byte, error) {
+	if len(A) == 0 || len(A) > math.MaxUint16 {
+		return nil, fmt.Errorf("A must be 1 to %d bytes long", math.MaxUint16)
+	}
+	return appendVector(nil, A, 2), nil
+}
+
+// ParseTLSClientKeyExchange returns the public key A carried
+// by a ClientKeyExchange message.
+func ParseTLSClientKeyExchange(data []byte) (PublicKey, error) {
+	A, rest, ok := readVector(data, 2)
+	if !ok || len(A) == 0 || len(rest) > 0 {
+		return nil, errors.New("malformed ClientKeyExchange")
+	}
+	return A, nil
+}
+
+// PremasterSecret returns the premaster secret S, which TLS-SRP
+// uses as the TLS premaster secret, once B has been set.
+func (c *Client) PremasterSecret() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.xS == nil {
+		return nil, ErrClientNotReady
+	}
+	return c.xS.Bytes(), nil
+}
+
+// PremasterSecret returns the premaster secret S, which TLS-SRP
+// uses as the TLS premaster secret, once A has been set.
+//
+// The premaster secret of a fake server (see [NewFakeServer]) is
+// returned as well, so that the TLS handshake fails when the
+// Finished messages are checked, as it does with a wrong password.
+func (s *Server) PremasterSecret() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.xS == nil {
+		return nil, ErrServerNoReady
+	}
+	return s.xS.Bytes(), nil
+}
+
+// appendVector appends v to b, prefixed with its length
+// encoded on lengthSize bytes.
+func appendVector(b, v []byte, lengthSize int) []byte {
+	if lengthSize == 1 {
+		b = append(b, byte(len(v)))
+	} else {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(v)))
+	}
+	return append(b, v...)
+}
+
+// readVector reads a vector prefixed with its length encoded
+// on lengthSize bytes from b, and returns it with the rest of
+// b, or false if b is too short.
+func readVector(b []byte, lengthSize int) (v, rest []byte, ok bool) {
+	if len(b) < lengthSize {
+		return nil, nil, false
+	}
+
+	var length int
+	if lengthSize == 1 {
+		length = int(b[0])
+	} else {
+		length = int(binary.BigEndian.Uint16(b))
+	}
+	b = b[lengthSize:]
+	if len(b) < length {
+		return nil, nil, false
+	}
+	return b[:length], b[length:], true
+}
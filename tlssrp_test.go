@@ -0,0 +1,168 @@
+package srp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTLSExtension(t *testing.T) {
+	data, err := MarshalTLSExtension("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "extension", []byte{0x05, 'a', 'l', 'i', 'c', 'e'}, data)
+
+	username, err := ParseTLSExtension(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if username != "alice" {
+		t.Fatalf("wanted alice, got %s", username)
+	}
+
+	if _, err := MarshalTLSExtension(""); err == nil {
+		t.Fatal("expected an empty username to be rejected")
+	}
+	for _, b := range [][]byte{nil, {0}, {5, 'a'}, {1, 'a', 'b'}} {
+		if _, err := ParseTLSExtension(b); err == nil {
+			t.Fatalf("expected %x to be rejected", b)
+		}
+	}
+}
+
+func TestTLSSRP(t *testing.T) {
+	tlsParams := TLSParams(RFC5054Group2048)
+	tp, err := ComputeVerifier(tlsParams, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ClientHello
+	extension, err := MarshalTLSExtension(string(I))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ServerKeyExchange
+	username, err := ParseTLSExtension(extension)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(tlsParams, username, tp.Salt(), tp.Verifier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ske, err := NewTLSServerParams(server).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ClientKeyExchange
+	var sp TLSServerParams
+	if err := sp.UnmarshalBinary(ske); err != nil {
+		t.Fatal(err)
+	}
+	group, err := sp.Group()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group != RFC5054Group2048 {
+		t.Fatalf("wanted group %s, got %s", RFC5054Group2048.ID, group.ID)
+	}
+	client, err := NewClient(TLSParams(group), string(I), string(P), sp.Salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetB(sp.B); err != nil {
+		t.Fatal(err)
+	}
+	cke, err := MarshalTLSClientKeyExchange(client.A())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	A, err := ParseTLSClientKeyExchange(cke)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetA(A); err != nil {
+		t.Fatal(err)
+	}
+
+	clientSecret, err := client.PremasterSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverSecret, err := server.PremasterSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "premaster secret", clientSecret, serverSecret)
+}
+
+func TestTLSServerParams(t *testing.T) {
+	p := &TLSServerParams{
+		N:    []byte{0x01, 0x02},
+		G:    []byte{0x02},
+		Salt: []byte{0x03},
+		B:    []byte{0x04, 0x05},
+	}
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := []byte{
+		0x00, 0x02, 0x01, 0x02,
+		0x00, 0x01, 0x02,
+		0x01, 0x03,
+		0x00, 0x02, 0x04, 0x05,
+	}
+	assertEqualBytes(t, "ServerSRPParams", wanted, b)
+
+	for i := 0; i < len(b); i++ {
+		if err := new(TLSServerParams).UnmarshalBinary(b[:i]); err == nil {
+			t.Fatalf("expected %x to be rejected", b[:i])
+		}
+	}
+	if err := new(TLSServerParams).UnmarshalBinary(append(b, 0)); err == nil {
+		t.Fatal("expected trailing data to be rejected")
+	}
+
+	if _, err := p.Group(); err == nil {
+		t.Fatal("expected an unknown group to be rejected")
+	}
+}
+
+func TestTLSClientKeyExchange(t *testing.T) {
+	b, err := MarshalTLSClientKeyExchange(A.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseTLSClientKeyExchange(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed, A.Bytes()) {
+		t.Fatal("A does not match")
+	}
+	if _, err := ParseTLSClientKeyExchange(b[:len(b)-1]); err == nil {
+		t.Fatal("expected a truncated message to be rejected")
+	}
+}
+
+func TestPremasterSecretNotReady(t *testing.T) {
+	client, err := NewClient(params, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.PremasterSecret(); err != ErrClientNotReady {
+		t.Fatalf("expected ErrClientNotReady, got %v", err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.PremasterSecret(); err != ErrServerNoReady {
+		t.Fatalf("expected ErrServerNoReady, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package srp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// Transcript holds the values of a handshake recorded by a
+// [TranscriptRecorder]. Except for the username, they are
+// encoded in lowercase hexadecimal, without leading zeros for
+// integers.
+//
+// Transcripts contain secret values (a, b, x, S, K), and must
+// only be produced with test credentials.
+type Transcript struct {
+	Params   string `json:"params,omitempty"`
+	Group    string `json:"group,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+	Username string `json:"I,omitempty"`
+
+	Salt     string `json:"s,omitempty"`
+	X        string `json:"x,omitempty"`
+	Verifier string `json:"v,omitempty"`
+	LittleK  string `json:"k,omitempty"`
+	LittleA  string `json:"a,omitempty"`
+	BigA     string `json:"A,omitempty"`
+	LittleB  string `json:"b,omitempty"`
+	BigB     string `json:"B,omitempty"`
+	U        string `json:"u,omitempty"`
+	S        string `json:"S,omitempty"`
+	K        string `json:"K,omitempty"`
+	M1       string `json:"M1,omitempty"`
+	M2       string `json:"M2,omitempty"`
+
+	// Mismatches lists the values recorded twice with
+	// different contents, when a recorder is shared by a
+	// client and a server.
+	Mismatches []string `json:"mismatches,omitempty"`
+}
+
+// TranscriptRecorder captures the intermediate values computed
+// by the clients and servers configured with [WithTranscript],
+// so that they can be compared with those of another
+// implementation of the protocol.
+//
+// A recorder may be shared by the client and the server of the
+// same handshake, in which case the values computed by both
+// sides are compared, and those that differ are listed in
+// [Transcript.Mismatches].
+//
+// A TranscriptRecorder is safe for concurrent use by multiple
+// goroutines.
+type TranscriptRecorder struct {
+	mu sync.Mutex
+	t  Transcript
+}
+
+// Transcript returns the values recorded by r.
+func (r *TranscriptRecorder) Transcript() Transcript {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.t
+	t.Mismatches = append([]string(nil), r.t.Mismatches...)
+	return t
+}
+
+// WriteJSON writes the values recorded by r to w as an
+// indented JSON object.
+func (r *TranscriptRecorder) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Transcript())
+}
+
+// recordParams records the description of params.
+func (r *TranscriptRecorder) recordParams(params *Params) {
+	r.record("params", &r.t.Params, params.Name)
+	r.record("group", &r.t.Group, params.Group.ID)
+	r.record("hash", &r.t.Hash, params.Hash.String())
+}
+
+// record sets *field to value, or adds name to the mismatches
+// of r if a different value was already recorded.
+func (r *TranscriptRecorder) record(name string, field *string, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if *field != "" && *field != value {
+		r.t.Mismatches = append(r.t.Mismatches, name)
+		return
+	}
+	*field = value
+}
+
+// recordInt records i in hexadecimal.
+func (r *TranscriptRecorder) recordInt(name string, field *string, i *big.Int) {
+	r.record(name, field, i.Text(16))
+}
+
+// recordBytes records b in hexadecimal.
+func (r *TranscriptRecorder) recordBytes(name string, field *string, b []byte) {
+	r.record(name, field, hex.EncodeToString(b))
+}
+
+// WithTranscript records the values computed by a client or a
+// server in r. It does not need to match on both sides.
+//
+// It is meant for debugging and generating test vectors only:
+// the recorder captures secret values.
+func WithTranscript(r *TranscriptRecorder) Option {
+	return func(o *options) {
+		o.transcript = r
+	}
+}
+
+// recordTranscript calls f with the transcript recorder of o,
+// if any.
+func (o *options) recordTranscript(f func(r *TranscriptRecorder)) {
+	if o.transcript != nil {
+		f(o.transcript)
+	}
+}
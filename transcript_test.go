@@ -0,0 +1,94 @@
+package srp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestTranscriptRecorder(t *testing.T) {
+	recorder := new(TranscriptRecorder)
+
+	client, err := NewClient(params, string(I), string(P), salt.Bytes(), WithTranscript(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithTranscript(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := recorder.Transcript()
+	if len(tr.Mismatches) > 0 {
+		t.Fatalf("unexpected mismatches: %v", tr.Mismatches)
+	}
+
+	key, err := client.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := map[string][2]string{
+		"I": {string(I), tr.Username},
+		"s": {hex.EncodeToString(salt.Bytes()), tr.Salt},
+		"x": {x.Text(16), tr.X},
+		"v": {v.Text(16), tr.Verifier},
+		"k": {k.Text(16), tr.LittleK},
+		"A": {new(big.Int).SetBytes(client.A()).Text(16), tr.BigA},
+		"B": {new(big.Int).SetBytes(server.B()).Text(16), tr.BigB},
+		"K": {hex.EncodeToString(key), tr.K},
+	}
+	for name, values := range wanted {
+		if values[0] != values[1] {
+			t.Fatalf("%s: wanted %s, got %s", name, values[0], values[1])
+		}
+	}
+	for name, value := range map[string]string{"a": tr.LittleA, "b": tr.LittleB, "u": tr.U, "S": tr.S, "M1": tr.M1, "M2": tr.M2} {
+		if value == "" {
+			t.Fatalf("%s was not recorded", name)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Transcript
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.M1 != tr.M1 || decoded.Hash != "SHA-1" || decoded.Group != params.Group.ID {
+		t.Fatalf("unexpected JSON transcript: %s", buf.Bytes())
+	}
+}
+
+func TestTranscriptRecorderMismatches(t *testing.T) {
+	recorder := new(TranscriptRecorder)
+
+	client, err := NewClient(params, string(I), "password124", salt.Bytes(), WithTranscript(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(params, string(I), salt.Bytes(), v.Bytes(), WithTranscript(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err == nil {
+		t.Fatal("expected the handshake to fail")
+	}
+
+	mismatches := recorder.Transcript().Mismatches
+	for _, name := range []string{"S", "K", "M1", "M2"} {
+		found := false
+		for _, m := range mismatches {
+			found = found || m == name
+		}
+		if !found {
+			t.Fatalf("expected %s to mismatch, got %v", name, mismatches)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package srp
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"strings"
 )
 
 // Triplet holds the parameters the server
@@ -47,9 +50,29 @@ func (t Triplet) Verifier() []byte {
 	return t[usernameLen+saltLen+2:]
 }
 
+// validate returns an error if t is mis-formatted.
+func (t Triplet) validate() error {
+	if len(t) < 1 {
+		return errors.New("triplet is empty")
+	}
+	usernameLen := int(t[0])
+	if len(t) < usernameLen+2 {
+		return errors.New("triplet is too short to contain a username")
+	}
+	saltLen := int(t[usernameLen+1])
+	if len(t) < usernameLen+saltLen+2 {
+		return errors.New("triplet is too short to contain a salt")
+	}
+	if len(t) == usernameLen+saltLen+2 {
+		return errors.New("triplet does not contain a verifier")
+	}
+	return nil
+}
+
 // MarshalJSON returns a JSON representation
 // of t that includes the username and the salt,
-// but not the verifier.
+// but not the verifier. Use [Triplet.Base64] to
+// encode the whole triplet.
 //
 //  {
 //     "username": "alice",
@@ -63,6 +86,69 @@ func (t Triplet) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+// tripletTextPrefix is the prefix of the text encoding of
+// triplets, which identifies its version.
+const tripletTextPrefix = "srp-v1:"
+
+// Base64 returns t encoded as a string, made of a version
+// prefix followed by t in unpadded URL-safe base64 (see
+// [RFC4648], Section 5):
+//
+//	srp-v1:BWFsaWNl...
+//
+// Unlike [Triplet.MarshalJSON], it includes the verifier, and
+// can be decoded with [ParseTripletBase64] to back up triplets,
+// or to store them in configuration files or environment
+// variables.
+//
+// [RFC4648]: https://datatracker.ietf.org/doc/html/rfc4648#section-5
+func (t Triplet) Base64() string {
+	return tripletTextPrefix + base64.RawURLEncoding.EncodeToString(t)
+}
+
+// ParseTripletBase64 returns the triplet encoded in s by
+// [Triplet.Base64], or an error if s is mis-formatted.
+func ParseTripletBase64(s string) (Triplet, error) {
+	encoded, ok := strings.CutPrefix(s, tripletTextPrefix)
+	if !ok {
+		return nil, fmt.Errorf("triplet must start with %q", tripletTextPrefix)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("triplet is not valid base64: %w", err)
+	}
+
+	t := Triplet(b)
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// MarshalText returns t encoded as with [Triplet.Base64], or
+// an error if t is mis-formatted.
+//
+// It lets encoders of text formats (e.g. YAML) store the whole
+// triplet, including the verifier. Encoding t in JSON still
+// calls [Triplet.MarshalJSON].
+func (t Triplet) MarshalText() ([]byte, error) {
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return []byte(t.Base64()), nil
+}
+
+// UnmarshalText decodes a triplet encoded with
+// [Triplet.MarshalText].
+func (t *Triplet) UnmarshalText(text []byte) error {
+	parsed, err := ParseTripletBase64(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
 // NewTriplet returns a new Triplet instance from the given
 // username, verifier and salt.
 //
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -26,3 +27,60 @@ func TestTripletMarshalJSON(t *testing.T) {
 		t.Fatalf("Wanted: %s. Got: %s", wanted, string(b))
 	}
 }
+
+func TestTripletBase64(t *testing.T) {
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	s := tp.Base64()
+	if !strings.HasPrefix(s, "srp-v1:") {
+		t.Fatalf("expected a version prefix, got %q", s)
+	}
+	if strings.ContainsAny(s[len("srp-v1:"):], "+/=") {
+		t.Fatalf("expected URL-safe base64 without padding, got %q", s)
+	}
+
+	parsed, err := ParseTripletBase64(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "triplet", tp, parsed)
+
+	invalid := []string{
+		"",
+		base64.RawURLEncoding.EncodeToString(tp),
+		"srp-v2:" + base64.RawURLEncoding.EncodeToString(tp),
+		"srp-v1:" + base64.StdEncoding.EncodeToString(tp),
+		"srp-v1:" + base64.RawURLEncoding.EncodeToString(NewTriplet(string(I), salt.Bytes(), nil)),
+	}
+	for _, s := range invalid {
+		if _, err := ParseTripletBase64(s); err == nil {
+			t.Fatalf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestTripletMarshalText(t *testing.T) {
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	text, err := tp.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != tp.Base64() {
+		t.Fatalf("expected %q, got %q", tp.Base64(), text)
+	}
+
+	var decoded Triplet
+	if err := decoded.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "triplet", tp, decoded)
+
+	if _, err := Triplet(nil).MarshalText(); err == nil {
+		t.Fatal("expected an empty triplet to be rejected")
+	}
+	if err := decoded.UnmarshalText([]byte("srp-v1:!")); err == nil {
+		t.Fatal("expected invalid text to be rejected")
+	}
+	assertEqualBytes(t, "triplet", tp, decoded)
+}
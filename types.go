@@ -0,0 +1,99 @@
+package srp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// redacted replaces secret values when they are printed.
+const redacted = "[REDACTED]"
+
+// PublicKey represents a public ephemeral key (A or B).
+type PublicKey []byte
+
+// NewPublicKey returns b as a [PublicKey], or an error if b
+// is not a valid public ephemeral key for params.
+func NewPublicKey(params *Params, b []byte) (PublicKey, error) {
+	if len(b) == 0 {
+		return nil, errors.New("public key cannot be empty")
+	}
+	if _, err := decodePublicKey(params, b); err != nil {
+		return nil, err
+	}
+	return PublicKey(b), nil
+}
+
+// String returns the hexadecimal representation of k.
+func (k PublicKey) String() string {
+	return hex.EncodeToString(k)
+}
+
+// Proof represents a client or a server proof (M1 or M2).
+//
+// Proofs are not secret, but an eavesdropper could use them
+// to mount a dictionary attack on the user's password. Their
+// value is therefore redacted when they are printed with the
+// fmt package.
+type Proof []byte
+
+// NewProof returns b as a [Proof], or an error if b cannot
+// be a proof computed with params.
+func NewProof(params *Params, b []byte) (Proof, error) {
+	if len(b) == 0 {
+		return nil, errors.New("proof cannot be empty")
+	}
+	if len(b) > params.Hash.Size() {
+		return nil, errors.New("proof is longer than the output of the hash")
+	}
+	return Proof(b), nil
+}
+
+// String returns a redacted representation of p.
+func (p Proof) String() string {
+	return redacted
+}
+
+// GoString returns a redacted representation of p.
+func (p Proof) GoString() string {
+	return redacted
+}
+
+// SessionKey represents the key shared by a client and a
+// server at the end of a handshake, in the format selected
+// by [Params.SessionKeyFormat].
+//
+// Its value is redacted when it is printed with the fmt
+// package, to prevent it from being logged by accident.
+// Use the key as a byte array to access its value.
+type SessionKey []byte
+
+// String returns a redacted representation of k.
+func (k SessionKey) String() string {
+	return redacted
+}
+
+// GoString returns a redacted representation of k.
+func (k SessionKey) GoString() string {
+	return redacted
+}
+
+// sessionKeyInfo is the HKDF info used to derive
+// keys in the [SessionKey32] format.
+const sessionKeyInfo = "srp session key"
+
+// formatSessionKey returns K in the session key
+// format of params.
+func formatSessionKey(params *Params, K []byte) (SessionKey, error) {
+	switch params.SessionKeyFormat {
+	case SessionKeyRaw:
+		return K, nil
+	case SessionKeyBase64:
+		return []byte(base64.StdEncoding.EncodeToString(K)), nil
+	case SessionKey32:
+		return hkdf(params.Hash, K, nil, []byte(sessionKeyInfo), 32)
+	default:
+		return nil, fmt.Errorf("unknown session key format %d", params.SessionKeyFormat)
+	}
+}
@@ -0,0 +1,119 @@
+package srp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewPublicKey(t *testing.T) {
+	if _, err := NewPublicKey(params, A.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := [][]byte{
+		nil,
+		{0x00},
+		params.Group.N.Bytes(),
+	}
+	for _, b := range invalid {
+		if _, err := NewPublicKey(params, b); err == nil {
+			t.Fatalf("expected %x to be rejected", b)
+		}
+	}
+}
+
+func TestNewProof(t *testing.T) {
+	if _, err := NewProof(params, make([]byte, params.Hash.Size())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewProof(params, nil); err == nil {
+		t.Fatal("expected an empty proof to be rejected")
+	}
+	if _, err := NewProof(params, make([]byte, params.Hash.Size()+1)); err == nil {
+		t.Fatal("expected a long proof to be rejected")
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	secret := []byte("secret")
+	values := []any{Proof(secret), SessionKey(secret)}
+	for _, v := range values {
+		for _, verb := range []string{"%v", "%s", "%#v", "%x", "%+v"} {
+			s := fmt.Sprintf(verb, v)
+			if strings.Contains(s, "secret") || strings.Contains(s, fmt.Sprintf("%x", secret)) {
+				t.Fatalf("%T printed with %s is not redacted: %s", v, verb, s)
+			}
+		}
+	}
+
+	if s := PublicKey(A.Bytes()).String(); s != fmt.Sprintf("%x", A.Bytes()) {
+		t.Fatalf("expected public keys to be printed in hexadecimal, got %s", s)
+	}
+}
+
+func TestFormatSessionKey(t *testing.T) {
+	K := []byte{
+		0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09,
+		0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13,
+	}
+
+	tests := []struct {
+		format SessionKeyFormat
+		wanted string
+	}{
+		{SessionKeyRaw, "000102030405060708090a0b0c0d0e0f10111213"},
+		{SessionKeyBase64, hex.EncodeToString([]byte("AAECAwQFBgcICQoLDA0ODxAREhM="))},
+		{SessionKey32, "133743f646595f94c8b992b951bfdc9e9d44fdbb70628f2533743287b65af447"},
+	}
+
+	for _, tt := range tests {
+		p := *params
+		p.SessionKeyFormat = tt.format
+
+		key, err := formatSessionKey(&p, K)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key); got != tt.wanted {
+			t.Fatalf("format %d: wanted %s, got %s", tt.format, tt.wanted, got)
+		}
+	}
+
+	p := *params
+	p.SessionKeyFormat = -1
+	if _, err := formatSessionKey(&p, K); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+}
+
+func TestSessionKeyFormat(t *testing.T) {
+	p := *params
+	p.SessionKeyFormat = SessionKey32
+
+	client, err := NewClient(&p, string(I), string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(&p, string(I), salt.Bytes(), v.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	clientKey, err := client.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverKey, err := server.SessionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clientKey) != 32 {
+		t.Fatalf("expected a 32-byte session key, got %d bytes", len(clientKey))
+	}
+	assertEqualBytes(t, "session key", clientKey, serverKey)
+}
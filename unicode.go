@@ -1,9 +1,11 @@
 package srp
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
 
+	"golang.org/x/text/secure/precis"
 	"golang.org/x/text/unicode/norm"
 )
 
@@ -15,3 +17,67 @@ func NFKD(str string) string {
 	str = strings.TrimFunc(str, unicode.IsSpace)
 	return str
 }
+
+// Normalization identifies how usernames and passwords are
+// prepared before being passed to the key derivation function.
+type Normalization int
+
+// Available normalization policies.
+const (
+	// NormalizeNFKD applies [NFKD] to usernames and
+	// passwords.
+	NormalizeNFKD Normalization = iota
+
+	// NormalizePRECIS prepares usernames with the
+	// UsernameCasePreserved profile, and passwords with the
+	// OpaqueString profile defined in RFC 8265, which
+	// supersedes SASLprep (RFC 4013). Credentials containing
+	// characters these profiles disallow (e.g. spaces in
+	// usernames, control characters) are rejected.
+	//
+	// The prepared username is also used in the client proof,
+	// so that clients and servers agree on it.
+	NormalizePRECIS
+)
+
+// normalizeUsername prepares username according to the
+// normalization policy of p.
+func (p *Params) normalizeUsername(username string) (string, error) {
+	if p.Normalization != NormalizePRECIS {
+		return NFKD(username), nil
+	}
+
+	s, err := precis.UsernameCasePreserved.String(username)
+	if err != nil {
+		return "", fmt.Errorf("invalid username: %w", err)
+	}
+	return s, nil
+}
+
+// normalizePassword prepares password according to the
+// normalization policy of p.
+func (p *Params) normalizePassword(password string) (string, error) {
+	if p.Normalization != NormalizePRECIS {
+		return NFKD(password), nil
+	}
+
+	s, err := precis.OpaqueString.String(password)
+	if err != nil {
+		return "", fmt.Errorf("invalid password: %w", err)
+	}
+	return s, nil
+}
+
+// normalizeCredentials prepares username and password
+// according to the normalization policy of p.
+func (p *Params) normalizeCredentials(username, password string) (string, string, error) {
+	username, err := p.normalizeUsername(username)
+	if err != nil {
+		return "", "", err
+	}
+	password, err = p.normalizePassword(password)
+	if err != nil {
+		return "", "", err
+	}
+	return username, password, nil
+}
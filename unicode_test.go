@@ -0,0 +1,92 @@
+package srp
+
+import (
+	"crypto"
+	"testing"
+)
+
+// precisParams are params using the PRECIS normalization.
+var precisParams = &Params{
+	Name:          "precis",
+	Group:         RFC5054Group2048,
+	Hash:          crypto.SHA256,
+	KDF:           RFC5054KDF,
+	Normalization: NormalizePRECIS,
+}
+
+func TestNormalizePRECIS(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wanted   [2]string // Empty if the credentials are rejected
+	}{
+		{"ASCII", "alice", "password123", [2]string{"alice", "password123"}},
+		{"CasePreserved", "Alice", "Password123", [2]string{"Alice", "Password123"}},
+		{"FullwidthUsername", "\uff41\uff4c\uff49\uff43\uff45", "password123", [2]string{"alice", "password123"}},
+		{"NonASCIISpace", "alice", "correct\u00a0horse", [2]string{"alice", "correct horse"}},
+		{"Decomposed", "ame\u0301lie", "pa\u0301ss", [2]string{"am\u00e9lie", "p\u00e1ss"}},
+		{"SpaceInUsername", "alice smith", "password123", [2]string{}},
+		{"ControlInPassword", "alice", "pass\u0007word", [2]string{}},
+		{"EmptyPassword", "alice", "", [2]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, password, err := precisParams.normalizeCredentials(tt.username, tt.password)
+			if tt.wanted[0] == "" {
+				if err == nil {
+					t.Fatal("expected credentials to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if username != tt.wanted[0] || password != tt.wanted[1] {
+				t.Fatalf("wanted %q, got %q", tt.wanted, [2]string{username, password})
+			}
+		})
+	}
+}
+
+func TestNormalizePRECISHandshake(t *testing.T) {
+	tp, err := ComputeVerifier(precisParams, "\uff41\uff4c\uff49\uff43\uff45", "correct\u00a0horse", salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The client uses the prepared forms of the credentials.
+	client, err := NewClient(precisParams, "alice", "correct horse", salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServerFromTriplet(precisParams, tp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(client, server); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := VerifyLocal(precisParams, tp, "alice", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected the prepared credentials to match")
+	}
+
+	if _, err := NewClient(precisParams, "alice smith", "correct horse", salt.Bytes()); err == nil {
+		t.Fatal("expected NewClient to reject a username with a space")
+	}
+	if _, err := ComputeVerifier(precisParams, "alice", "pass\u0007word", salt.Bytes()); err == nil {
+		t.Fatal("expected ComputeVerifier to reject a control character")
+	}
+}
+
+func TestNormalizeNFKDUnchanged(t *testing.T) {
+	// The default policy accepts what PRECIS rejects.
+	if _, err := ComputeVerifier(params, "alice smith", "pass\u0007word", salt.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
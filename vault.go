@@ -0,0 +1,47 @@
+package srp
+
+import (
+	"errors"
+)
+
+// vaultDomain separates the inputs of the KDF used to
+// derive vault keys from those used to derive x.
+const vaultDomain = "srp vault key"
+
+// DeriveVaultKey derives a key from the user's credentials
+// that applications can use to encrypt local data, while
+// the same password is used to authenticate with SRP.
+//
+// The key is independent from x (and therefore from the
+// verifier): the params' KDF is run with a salt derived from
+// salt and a fixed domain-separation string, and its output
+// is expanded with HKDF using label as context information.
+// Different labels produce independent keys.
+//
+//	s' = H("srp vault key" | s)
+//	key = HKDF(KDF(U, p, s'), label)
+//
+// The key has the same length as the output of the params'
+// hash. DeriveVaultKey runs the params' key derivation
+// function.
+func DeriveVaultKey(params *Params, username, password string, salt []byte, label string) ([]byte, error) {
+	if len(salt) == 0 {
+		return nil, errors.New("salt cannot be empty")
+	}
+
+	h := params.Hash.New()
+	h.Write([]byte(vaultDomain))
+	h.Write(salt)
+	vaultSalt := h.Sum(nil)[:h.Size()]
+
+	username, password, err := params.normalizeCredentials(username, password)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := params.KDF(username, password, vaultSalt)
+	if err != nil {
+		return nil, err
+	}
+
+	return hkdf(params.Hash, secret, nil, []byte(label), params.Hash.Size())
+}
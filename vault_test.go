@@ -0,0 +1,48 @@
+package srp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveVaultKey(t *testing.T) {
+	key, err := DeriveVaultKey(params, string(I), string(P), salt.Bytes(), "vault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != params.Hash.Size() {
+		t.Fatalf("expected a %d-byte key, got %d", params.Hash.Size(), len(key))
+	}
+
+	again, err := DeriveVaultKey(params, string(I), string(P), salt.Bytes(), "vault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBytes(t, "key", key, again)
+
+	if bytes.Equal(key, x.Bytes()) {
+		t.Fatal("vault key must differ from x")
+	}
+
+	other, err := DeriveVaultKey(params, string(I), string(P), salt.Bytes(), "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(key, other) {
+		t.Fatal("keys derived with different labels must differ")
+	}
+
+	wrong, err := DeriveVaultKey(params, string(I), "password124", salt.Bytes(), "vault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(key, wrong) {
+		t.Fatal("keys derived from different passwords must differ")
+	}
+}
+
+func TestDeriveVaultKeyEmptySalt(t *testing.T) {
+	if _, err := DeriveVaultKey(params, string(I), string(P), nil, "vault"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,137 @@
+package srp
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Credential holds the username and the password of a user
+// whose verifier is computed by [ComputeVerifiers].
+type Credential struct {
+	Username string
+	Password string
+
+	// Salt is the salt of the user. A new salt is created
+	// with [Params.NewSalt] if it is nil.
+	Salt []byte
+}
+
+// CredentialError is returned by [ComputeVerifiers] when the
+// triplet of an entry cannot be computed.
+type CredentialError struct {
+	Index int   // Index of the entry in the credentials
+	Err   error // Error of the entry
+}
+
+// Error implements the error interface.
+func (e *CredentialError) Error() string {
+	return fmt.Sprintf("entry %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error of the entry.
+func (e *CredentialError) Unwrap() error {
+	return e.Err
+}
+
+// ComputeVerifiers computes the triplets of entries with
+// workers goroutines, e.g. to migrate an existing user base
+// to SRP. Triplets are returned in the order of entries.
+//
+// If workers is not positive, runtime.GOMAXPROCS(0) workers
+// are used. Memory-hard key derivation functions such as
+// Argon2 may require fewer workers than CPUs to fit in memory.
+//
+// If progress is not nil, it is called after each triplet is
+// computed with the number of triplets computed so far and
+// the number of entries. Calls to progress are serialized, and
+// block the workers: it should return quickly.
+//
+// The computation stops at the first error, which is returned
+// as a [*CredentialError] holding the index of the entry that
+// caused it.
+func ComputeVerifiers(params *Params, entries []Credential, workers int, progress func(done, total int)) ([]Triplet, error) {
+	return ComputeVerifiersContext(context.Background(), params, entries, workers, progress)
+}
+
+// ComputeVerifiersContext is like [ComputeVerifiers], but
+// stops and returns the error of ctx if ctx is done before
+// all the triplets are computed.
+//
+// The key derivation functions running when ctx is done are
+// not interrupted, but no new one is started.
+func ComputeVerifiersContext(ctx context.Context, params *Params, entries []Credential, workers int, progress func(done, total int)) ([]Triplet, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(entries) {
+		workers = len(entries)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		triplets = make([]Triplet, len(entries))
+		next     atomic.Int64 // Index of the next entry
+
+		mu       sync.Mutex // Guards the variables below
+		done     int        // Number of triplets computed
+		firstErr error      // First error encountered
+	)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= len(entries) {
+					return
+				}
+
+				tp, err := computeCredential(params, entries[i])
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = &CredentialError{Index: i, Err: err}
+						cancel()
+					}
+				} else {
+					triplets[i] = tp
+					done++
+					if progress != nil {
+						progress(done, len(entries))
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return triplets, nil
+}
+
+// computeCredential returns the triplet of c.
+func computeCredential(params *Params, c Credential) (Triplet, error) {
+	salt := c.Salt
+	if salt == nil {
+		salt = params.NewSalt()
+	}
+	return ComputeVerifier(params, c.Username, c.Password, salt)
+}
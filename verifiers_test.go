@@ -0,0 +1,93 @@
+package srp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestComputeVerifiers(t *testing.T) {
+	entries := make([]Credential, 20)
+	for i := range entries {
+		entries[i] = Credential{
+			Username: fmt.Sprintf("user%d", i),
+			Password: fmt.Sprintf("password%d", i),
+		}
+	}
+	entries[0].Salt = salt.Bytes()
+
+	var calls []int
+	triplets, err := ComputeVerifiers(params, entries, 4, func(done, total int) {
+		if total != len(entries) {
+			t.Errorf("wanted a total of %d, got %d", len(entries), total)
+		}
+		calls = append(calls, done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != len(entries) {
+		t.Fatalf("wanted %d progress calls, got %d", len(entries), len(calls))
+	}
+	for i, done := range calls {
+		if done != i+1 {
+			t.Fatalf("call %d: wanted %d, got %d", i, i+1, done)
+		}
+	}
+
+	assertEqualBytes(t, "salt", salt.Bytes(), triplets[0].Salt())
+	for i, tp := range triplets {
+		ok, err := VerifyLocal(params, tp, entries[i].Username, entries[i].Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("triplet %d does not match its credentials", i)
+		}
+	}
+}
+
+func TestComputeVerifiersError(t *testing.T) {
+	failing := &Params{
+		Group: params.Group,
+		Hash:  params.Hash,
+		KDF: func(username, password string, salt []byte) ([]byte, error) {
+			if username == "mallory" {
+				return nil, errors.New("KDF failed")
+			}
+			return RFC5054KDF(username, password, salt)
+		},
+	}
+
+	entries := []Credential{{Username: "alice"}, {Username: "mallory"}, {Username: "bob"}}
+	_, err := ComputeVerifiers(failing, entries, 1, nil)
+	var credErr *CredentialError
+	if !errors.As(err, &credErr) || credErr.Index != 1 {
+		t.Fatalf("expected entry 1 to fail, got %v", err)
+	}
+	if credErr.Err == nil || credErr.Err.Error() != "KDF failed" {
+		t.Fatalf("expected the error of the KDF, got %v", credErr.Err)
+	}
+}
+
+func TestComputeVerifiersContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	entries := make([]Credential, 100)
+	_, err := ComputeVerifiersContext(ctx, params, entries, 2, func(done, total int) {
+		if done == 10 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("wanted context.Canceled, got %v", err)
+	}
+
+	triplets, err := ComputeVerifiers(params, nil, 0, nil)
+	if err != nil || len(triplets) != 0 {
+		t.Fatalf("wanted no triplets, got %v, %v", triplets, err)
+	}
+}
@@ -0,0 +1,71 @@
+package srp
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+)
+
+// VerifyLocal returns true if the verifier stored in triplet
+// was computed from the given username and password.
+//
+// The verifier is recomputed with the salt stored in triplet,
+// and compared in constant time. This allows a client to
+// validate credentials against a cached triplet without
+// contacting the server (e.g. to unlock a local vault
+// offline).
+//
+// An error is returned if triplet is mis-formatted, or if its
+// username cannot be normalized with params.
+//
+// VerifyLocal runs the params' key derivation function.
+func VerifyLocal(params *Params, triplet Triplet, username, password string) (bool, error) {
+	if err := triplet.validate(); err != nil {
+		return false, err
+	}
+
+	v, err := computeVerifier(params, username, password, triplet.Salt())
+	if err != nil {
+		return false, err
+	}
+
+	stored := new(big.Int).SetBytes(triplet.Verifier())
+	if stored.Cmp(params.Group.N) >= 0 {
+		return false, nil
+	}
+
+	var (
+		length   = (params.Group.N.BitLen() + 7) / 8
+		wanted   = stored.FillBytes(make([]byte, length))
+		computed = v.FillBytes(make([]byte, length))
+	)
+
+	given, err := params.normalizeUsername(username)
+	if err != nil {
+		return false, err
+	}
+	expected, err := params.normalizeUsername(triplet.Username())
+	if err != nil {
+		return false, fmt.Errorf("stored username: %w", err)
+	}
+
+	verifierOK := subtle.ConstantTimeCompare(wanted, computed) == 1
+	usernameOK := subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+	return verifierOK && usernameOK, nil
+}
+
+// VerifyPassword returns true if the verifier stored in triplet
+// was computed from password, and the username stored in
+// triplet.
+//
+// It is a shorthand for [VerifyLocal] when the username is not
+// provided separately, e.g. to validate imported triplets or
+// in test tooling.
+//
+// VerifyPassword runs the params' key derivation function.
+func VerifyPassword(params *Params, triplet Triplet, password string) (bool, error) {
+	if err := triplet.validate(); err != nil {
+		return false, err
+	}
+	return VerifyLocal(params, triplet, triplet.Username(), password)
+}
@@ -0,0 +1,82 @@
+package srp
+
+import "testing"
+
+func TestVerifyLocal(t *testing.T) {
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wanted   bool
+	}{
+		{"Valid", string(I), string(P), true},
+		{"WrongPassword", string(I), "password124", false},
+		{"WrongUsername", "bob", string(P), false},
+		{"Normalized", " " + string(I), string(P) + "\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := VerifyLocal(params, tp, tt.username, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wanted {
+				t.Fatalf("wanted %v, got %v", tt.wanted, ok)
+			}
+		})
+	}
+}
+
+func TestVerifyLocalInvalidTriplet(t *testing.T) {
+	for _, tp := range []Triplet{nil, {5, 'a'}, {1, 'a', 4, 1}, NewTriplet("alice", salt.Bytes(), nil)} {
+		if _, err := VerifyLocal(params, tp, string(I), string(P)); err == nil {
+			t.Fatalf("expected triplet %v to be rejected", tp)
+		}
+	}
+}
+
+func TestVerifyLocalInvalidStoredUsername(t *testing.T) {
+	tp, err := ComputeVerifier(precisParams, "alice", string(P), salt.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Spaces are not allowed in PRECIS usernames.
+	invalid := NewTriplet("alice smith", tp.Salt(), tp.Verifier())
+	if _, err := VerifyLocal(precisParams, invalid, "alice", string(P)); err == nil {
+		t.Fatal("expected an invalid stored username to be reported")
+	}
+}
+
+func TestVerifyLocalLongVerifier(t *testing.T) {
+	long := append([]byte{1}, make([]byte, len(params.Group.N.Bytes()))...)
+	tp := NewTriplet(string(I), salt.Bytes(), long)
+
+	ok, err := VerifyLocal(params, tp, string(I), string(P))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("expected a verifier longer than N to be rejected")
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	for password, wanted := range map[string]bool{string(P): true, "password124": false} {
+		ok, err := VerifyPassword(params, tp, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != wanted {
+			t.Fatalf("%q: wanted %v, got %v", password, wanted, ok)
+		}
+	}
+
+	if _, err := VerifyPassword(params, nil, string(P)); err == nil {
+		t.Fatal("expected an empty triplet to be rejected")
+	}
+}
@@ -0,0 +1,191 @@
+package srp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// webSocketBinaryMessage is the type of binary WebSocket
+// messages, as defined in RFC 6455.
+const webSocketBinaryMessage = 2
+
+// HKDF info used to derive the keys of each direction
+// of a WebSocketSession.
+const (
+	webSocketClientInfo = "srp websocket client"
+	webSocketServerInfo = "srp websocket server"
+)
+
+// WebSocket is the subset of a WebSocket connection used
+// by [WebSocketClientHandshake] and [WebSocketServerHandshake].
+//
+// The *websocket.Conn of github.com/gorilla/websocket
+// implements it. Connections of nhooyr.io/websocket can be
+// adapted in a few lines with Read and Write, or used as an
+// [io.ReadWriter] with [ClientHandshake] and [ServerHandshake]
+// through websocket.NetConn.
+type WebSocket interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+}
+
+// webSocketReadWriter exposes a WebSocket as an io.ReadWriter,
+// sending each write in a binary message.
+type webSocketReadWriter struct {
+	ws  WebSocket
+	buf []byte // Unread part of the last message
+}
+
+func (rw *webSocketReadWriter) Read(p []byte) (int, error) {
+	for len(rw.buf) == 0 {
+		t, data, err := rw.ws.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+		if t != webSocketBinaryMessage {
+			return 0, fmt.Errorf("unexpected WebSocket message type %d", t)
+		}
+		rw.buf = data
+	}
+
+	n := copy(p, rw.buf)
+	rw.buf = rw.buf[n:]
+	return n, nil
+}
+
+func (rw *webSocketReadWriter) Write(p []byte) (int, error) {
+	if err := rw.ws.WriteMessage(webSocketBinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WebSocketClientHandshake runs [ClientHandshake] over ws, each
+// message being sent in a binary WebSocket message, and returns
+// a session to exchange encrypted messages with the server.
+func WebSocketClientHandshake(ws WebSocket, params *Params, username, password string, opts ...Option) (*WebSocketSession, error) {
+	key, err := ClientHandshake(&webSocketReadWriter{ws: ws}, params, username, password, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newWebSocketSession(ws, params, key, webSocketClientInfo, webSocketServerInfo)
+}
+
+// WebSocketServerHandshake runs [ServerHandshake] over ws, each
+// message being sent in a binary WebSocket message, and returns
+// a session to exchange encrypted messages with the client.
+func WebSocketServerHandshake(ws WebSocket, params *Params, triplet Triplet, opts ...Option) (*WebSocketSession, error) {
+	key, err := ServerHandshake(&webSocketReadWriter{ws: ws}, params, triplet, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newWebSocketSession(ws, params, key, webSocketServerInfo, webSocketClientInfo)
+}
+
+// WebSocketSession exchanges messages encrypted with keys
+// derived from the session key over a WebSocket.
+//
+// Messages are encrypted with AES-256-GCM, using a different key
+// in each direction and a sequence number as nonce, so messages
+// that are replayed, reordered or dropped are detected.
+//
+// Send and Receive are safe for concurrent use: the calls in
+// each direction are serialized, so the WebSocket sees at most
+// one writer and one reader at a time.
+type WebSocketSession struct {
+	ws  WebSocket
+	key SessionKey
+
+	sendMu  sync.Mutex
+	send    cipher.AEAD
+	sendSeq uint64
+
+	recvMu  sync.Mutex
+	recv    cipher.AEAD
+	recvSeq uint64
+}
+
+// newWebSocketSession returns a session over ws, deriving the
+// keys of each direction from key with sendInfo and recvInfo.
+func newWebSocketSession(ws WebSocket, params *Params, key SessionKey, sendInfo, recvInfo string) (*WebSocketSession, error) {
+	send, err := newWebSocketAEAD(params, key, sendInfo)
+	if err != nil {
+		return nil, err
+	}
+	recv, err := newWebSocketAEAD(params, key, recvInfo)
+	if err != nil {
+		return nil, err
+	}
+	return &WebSocketSession{
+		ws:   ws,
+		key:  key,
+		send: send,
+		recv: recv,
+	}, nil
+}
+
+// newWebSocketAEAD returns the AEAD of a direction of
+// a WebSocketSession.
+func newWebSocketAEAD(params *Params, key SessionKey, info string) (cipher.AEAD, error) {
+	k, err := hkdf(params.Hash, key, nil, []byte(info), 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SessionKey returns the session key established by
+// the handshake.
+func (s *WebSocketSession) SessionKey() SessionKey {
+	return s.key
+}
+
+// Send encrypts msg, and sends it in a binary message.
+func (s *WebSocketSession) Send(msg []byte) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	nonce := webSocketNonce(s.send, s.sendSeq)
+	s.sendSeq++
+	return s.ws.WriteMessage(webSocketBinaryMessage, s.send.Seal(nil, nonce, msg, nil))
+}
+
+// Receive reads the next message, and returns it decrypted.
+//
+// An error is returned if the message cannot be authenticated,
+// in which case the session should be closed.
+func (s *WebSocketSession) Receive() ([]byte, error) {
+	s.recvMu.Lock()
+	defer s.recvMu.Unlock()
+
+	t, data, err := s.ws.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	if t != webSocketBinaryMessage {
+		return nil, fmt.Errorf("unexpected WebSocket message type %d", t)
+	}
+
+	msg, err := s.recv.Open(nil, webSocketNonce(s.recv, s.recvSeq), data, nil)
+	if err != nil {
+		return nil, errors.New("failed to authenticate message")
+	}
+	s.recvSeq++
+	return msg, nil
+}
+
+// webSocketNonce returns the nonce of the message
+// with sequence number seq.
+func webSocketNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
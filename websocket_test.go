@@ -0,0 +1,148 @@
+package srp
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// wsMessage is a message of a fakeWebSocket.
+type wsMessage struct {
+	t    int
+	data []byte
+}
+
+// fakeWebSocket is one end of an in-memory WebSocket.
+type fakeWebSocket struct {
+	in, out chan wsMessage
+}
+
+// newFakeWebSockets returns the two ends of an
+// in-memory WebSocket.
+func newFakeWebSockets() (*fakeWebSocket, *fakeWebSocket) {
+	a, b := make(chan wsMessage, 16), make(chan wsMessage, 16)
+	return &fakeWebSocket{in: a, out: b}, &fakeWebSocket{in: b, out: a}
+}
+
+func (ws *fakeWebSocket) ReadMessage() (int, []byte, error) {
+	m, ok := <-ws.in
+	if !ok {
+		return 0, nil, io.EOF
+	}
+	return m.t, m.data, nil
+}
+
+func (ws *fakeWebSocket) WriteMessage(t int, data []byte) error {
+	ws.out <- wsMessage{t, append([]byte(nil), data...)}
+	return nil
+}
+
+func (ws *fakeWebSocket) Close() {
+	close(ws.out)
+}
+
+// webSocketHandshakes runs a client and a server handshake
+// over an in-memory WebSocket.
+func webSocketHandshakes(t *testing.T, password string) (*WebSocketSession, *WebSocketSession, error, error) {
+	t.Helper()
+
+	c, s := newFakeWebSockets()
+	tp := NewTriplet(string(I), salt.Bytes(), v.Bytes())
+
+	var (
+		server    *WebSocketSession
+		serverErr error
+		done      = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		server, serverErr = WebSocketServerHandshake(s, params, tp)
+		if serverErr != nil {
+			s.Close()
+		}
+	}()
+
+	client, clientErr := WebSocketClientHandshake(c, params, string(I), password)
+	<-done
+	return client, server, clientErr, serverErr
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	client, server, clientErr, serverErr := webSocketHandshakes(t, string(P))
+	if clientErr != nil {
+		t.Fatal(clientErr)
+	}
+	if serverErr != nil {
+		t.Fatal(serverErr)
+	}
+	assertEqualBytes(t, "session key", client.SessionKey(), server.SessionKey())
+
+	for _, msg := range []string{"hello", "", "world"} {
+		if err := client.Send([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := server.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != msg {
+			t.Fatalf("wanted %q, got %q", msg, got)
+		}
+	}
+
+	if err := server.Send([]byte("reply")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "reply" {
+		t.Fatalf("wanted reply, got %q", got)
+	}
+}
+
+func TestWebSocketHandshakeWrongPassword(t *testing.T) {
+	_, _, clientErr, serverErr := webSocketHandshakes(t, "password124")
+	if serverErr == nil || clientErr == nil {
+		t.Fatalf("expected both sides to fail, got %v and %v", clientErr, serverErr)
+	}
+}
+
+func TestWebSocketSessionTampering(t *testing.T) {
+	client, server, clientErr, serverErr := webSocketHandshakes(t, string(P))
+	if err := errors.Join(clientErr, serverErr); err != nil {
+		t.Fatal(err)
+	}
+	ws := server.ws.(*fakeWebSocket)
+
+	// A replayed message is rejected.
+	if err := client.Send([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	m := <-ws.in
+	ws.in <- m
+	if _, err := server.Receive(); err != nil {
+		t.Fatal(err)
+	}
+	ws.in <- m
+	if _, err := server.Receive(); err == nil {
+		t.Fatal("expected a replayed message to be rejected")
+	}
+
+	// A message reflected to its sender is rejected.
+	if err := client.Send([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	m = <-ws.in
+	client.ws.(*fakeWebSocket).in <- m
+	if _, err := client.Receive(); err == nil {
+		t.Fatal("expected a reflected message to be rejected")
+	}
+
+	// Text messages are rejected.
+	ws.in <- wsMessage{1, []byte("text")}
+	if _, err := server.Receive(); err == nil {
+		t.Fatal("expected a text message to be rejected")
+	}
+}